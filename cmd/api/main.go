@@ -6,6 +6,7 @@ import (
 	"github.com/HasanNugroho/golang-starter/cmd/docs"
 	"github.com/HasanNugroho/golang-starter/internal"
 	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"github.com/HasanNugroho/golang-starter/internal/middleware"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
@@ -32,10 +33,21 @@ func main() {
 	}
 
 	router := echo.New()
+
+	// Satu sumber IP client untuk log, rate limit dan session (c.RealIP())
+	ipResolver, err := helper.NewIPResolver(config.Security.TrustedProxies, config.Security.TrustedPlatform)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid trusted proxy configuration")
+	}
+	router.IPExtractor = ipResolver.ExtractIP
+
 	internal.Init(config, router)
 
 	// Middleware
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.SecurityHeaders(config.Security))
+	router.Use(middleware.ExpectedHost(config.Security.ExpectedHost))
+	router.Use(middleware.CORS(config.Server.AllowedOrigins, config.Security.CheckOrigin))
 
 	// Swagger Setup
 	loadSwagger(router, config)
@@ -4,17 +4,24 @@ import (
 	"github.com/HasanNugroho/golang-starter/internal/configs"
 	accounthandler "github.com/HasanNugroho/golang-starter/internal/handler/account"
 	authhandler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/HasanNugroho/golang-starter/internal/notification"
+	"github.com/HasanNugroho/golang-starter/internal/oidc"
+	"github.com/HasanNugroho/golang-starter/internal/password"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
 	accountrepository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	authrepository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
 	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
 	authservice "github.com/HasanNugroho/golang-starter/internal/service/auth"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/sarulabs/di/v2"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
 // BuildContainer menginisialisasi dependency injection container untuk fitur Role dan User
-func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolog.Logger) (di.Container, error) {
+func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, redisClient *redis.Client, logger *zerolog.Logger) (di.Container, error) {
 	builder, err := di.NewBuilder()
 	if err != nil {
 		return di.Container{}, err
@@ -36,6 +43,65 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		},
 	})
 
+	// Register Redis instance
+	builder.Add(di.Def{
+		Name: "redis",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return redisClient, nil
+		},
+	})
+
+	// Register notifier (dipakai untuk mengirim email/pesan ke user)
+	builder.Add(di.Def{
+		Name: "notifier",
+		Build: func(ctn di.Container) (interface{}, error) {
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return notification.NewNotifier(cfg.Mail, log)
+		},
+	})
+
+	// Register katalog permission (dibaca sekali saat startup)
+	builder.Add(di.Def{
+		Name: "permissionCatalog",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return permission.LoadCatalog("./internal/constant/data.yaml")
+		},
+	})
+
+	// Register password policy (daftar password bocor dibaca sekali saat startup)
+	builder.Add(di.Def{
+		Name: "passwordPolicy",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return password.NewPolicy(cfg.PasswordPolicy)
+		},
+	})
+
+	// PermissionInvalidationRepository
+	builder.Add(di.Def{
+		Name: "permissionInvalidationRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountrepository.NewPermissionInvalidationRepository(redisClient, log), nil
+		},
+	})
+
+	// PermissionService (cache permission efektif per user)
+	builder.Add(di.Def{
+		Name: "permissionService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			roleRepo := ctn.Get("roleRepository").(*accountrepository.RoleRepository)
+			invalidationRepo := ctn.Get("permissionInvalidationRepository").(*accountrepository.PermissionInvalidationRepository)
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountservice.NewPermissionService(roleRepo, invalidationRepo, catalog, log, cfg), nil
+		},
+		Close: func(obj interface{}) error {
+			obj.(*accountservice.PermissionService).Close()
+			return nil
+		},
+	})
+
 	// --- ROLE FEATURE ---
 
 	// RoleRepository
@@ -54,11 +120,9 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		Build: func(ctn di.Container) (interface{}, error) {
 			repo := ctn.Get("roleRepository").(*accountrepository.RoleRepository)
 			log := ctn.Get("logger").(*zerolog.Logger)
-			roleService, err := accountservice.NewRoleService(repo, log)
-			if err != nil {
-				return nil, err
-			}
-			return roleService, nil
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+			return accountservice.NewRoleService(repo, log, catalog, permissionSvc), nil
 		},
 	})
 
@@ -71,6 +135,15 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		},
 	})
 
+	// PermissionHandler
+	builder.Add(di.Def{
+		Name: "permissionHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			return accounthandler.NewPermissionHandler(catalog), nil
+		},
+	})
+
 	// --- USER FEATURE ---
 
 	// UserRepository
@@ -83,14 +156,27 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		},
 	})
 
+	// EmailVerificationService
+	builder.Add(di.Def{
+		Name: "emailVerificationService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repo := ctn.Get("userRepository").(accountrepository.IUserRepository)
+			notifier := ctn.Get("notifier").(notification.INotifier)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountservice.NewEmailVerificationService(repo, notifier, log, cfg), nil
+		},
+	})
+
 	// UserService
 	builder.Add(di.Def{
 		Name: "userService",
 		Build: func(ctn di.Container) (interface{}, error) {
 			repo := ctn.Get("userRepository").(accountrepository.IUserRepository)
 			rolerepo := ctn.Get("roleRepository").(*accountrepository.RoleRepository)
+			verification := ctn.Get("emailVerificationService").(accountservice.IEmailVerificationService)
+			policy := ctn.Get("passwordPolicy").(*password.Policy)
 			log := ctn.Get("logger").(*zerolog.Logger)
-			userService := accountservice.NewUserService(repo, rolerepo, log)
+			userService := accountservice.NewUserService(repo, rolerepo, verification, policy, log)
 			return userService, nil
 		},
 	})
@@ -104,13 +190,204 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		},
 	})
 
+	// --- API KEY FEATURE ---
+
+	// APIKeyRepository
+	builder.Add(di.Def{
+		Name: "apiKeyRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoDB := ctn.Get("mongoDB").(*mongo.Database)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountrepository.NewAPIKeyRepository(mongoDB, log), nil
+		},
+	})
+
+	// APIKeyService
+	builder.Add(di.Def{
+		Name: "apiKeyService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repo := ctn.Get("apiKeyRepository").(accountrepository.IAPIKeyRepository)
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountservice.NewAPIKeyService(repo, permissionSvc, catalog, log), nil
+		},
+	})
+
+	// APIKeyHandler
+	builder.Add(di.Def{
+		Name: "apiKeyHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			apiKeySvc := ctn.Get("apiKeyService").(accountservice.IAPIKeyService)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			return accounthandler.NewAPIKeyHandler(apiKeySvc, userSvc), nil
+		},
+	})
+
+	// --- INVITATION FEATURE ---
+
+	// InvitationRepository
+	builder.Add(di.Def{
+		Name: "invitationRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoDB := ctn.Get("mongoDB").(*mongo.Database)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountrepository.NewInvitationRepository(mongoDB, log), nil
+		},
+	})
+
+	// InvitationService
+	builder.Add(di.Def{
+		Name: "invitationService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repo := ctn.Get("invitationRepository").(accountrepository.IInvitationRepository)
+			rolerepo := ctn.Get("roleRepository").(*accountrepository.RoleRepository)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			notifier := ctn.Get("notifier").(notification.INotifier)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountservice.NewInvitationService(repo, rolerepo, userSvc, permissionSvc, catalog, notifier, log, cfg), nil
+		},
+	})
+
+	// InvitationHandler
+	builder.Add(di.Def{
+		Name: "invitationHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			invitationSvc := ctn.Get("invitationService").(accountservice.IInvitationService)
+			return accounthandler.NewInvitationHandler(invitationSvc), nil
+		},
+	})
+
 	// --- AUTH FEATURE ---
+
+	// SessionRepository
+	builder.Add(di.Def{
+		Name: "sessionRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewSessionRepository(redisClient, log), nil
+		},
+	})
+
+	// SessionService
+	builder.Add(di.Def{
+		Name: "sessionService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repo := ctn.Get("sessionRepository").(authrepository.ISessionRepository)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewSessionService(repo, log, cfg), nil
+		},
+	})
+
+	// JWT signing keys
+	builder.Add(di.Def{
+		Name: "jwtKeys",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return helper.LoadKeySet(cfg.Security)
+		},
+	})
+
+	// TokenRepository
+	builder.Add(di.Def{
+		Name: "tokenRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewTokenRepository(redisClient, log), nil
+		},
+	})
+
+	// TokenService
+	builder.Add(di.Def{
+		Name: "tokenService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			keys := ctn.Get("jwtKeys").(*helper.KeySet)
+			repo := ctn.Get("tokenRepository").(authrepository.ITokenRepository)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewTokenService(keys, repo, log, cfg), nil
+		},
+	})
+
+	// MFAService
+	builder.Add(di.Def{
+		Name: "mfaService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			loginGuard := ctn.Get("loginGuardService").(authservice.ILoginGuardService)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewMFAService(userSvc, loginGuard, log, cfg), nil
+		},
+	})
+
+	// PasswordResetRepository
+	builder.Add(di.Def{
+		Name: "passwordResetRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewPasswordResetRepository(redisClient, log), nil
+		},
+	})
+
+	// PasswordService
+	builder.Add(di.Def{
+		Name: "passwordService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			sessionSvc := ctn.Get("sessionService").(authservice.ISessionService)
+			repo := ctn.Get("passwordResetRepository").(authrepository.IPasswordResetRepository)
+			notifier := ctn.Get("notifier").(notification.INotifier)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewPasswordService(userSvc, sessionSvc, repo, notifier, log, cfg), nil
+		},
+	})
+
+	// RegistrationService
+	builder.Add(di.Def{
+		Name: "registrationService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			roleSvc := ctn.Get("roleService").(*accountservice.RoleService)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewRegistrationService(userSvc, roleSvc, log, cfg), nil
+		},
+	})
+
+	// LoginAttemptRepository
+	builder.Add(di.Def{
+		Name: "loginAttemptRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewLoginAttemptRepository(redisClient, log), nil
+		},
+	})
+
+	// LoginGuardService
+	builder.Add(di.Def{
+		Name: "loginGuardService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			repo := ctn.Get("loginAttemptRepository").(authrepository.ILoginAttemptRepository)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewLoginGuardService(repo, userSvc, log, cfg), nil
+		},
+	})
+
+	// AuthService
 	builder.Add(di.Def{
 		Name: "authService",
 		Build: func(ctn di.Container) (interface{}, error) {
 			log := ctn.Get("logger").(*zerolog.Logger)
 			userSvc := ctn.Get("userService").(accountservice.IUserService)
-			authService := authservice.NewAuthService(userSvc, log, cfg)
+			sessionSvc := ctn.Get("sessionService").(authservice.ISessionService)
+			tokenSvc := ctn.Get("tokenService").(authservice.ITokenService)
+			loginGuard := ctn.Get("loginGuardService").(authservice.ILoginGuardService)
+			policy := ctn.Get("passwordPolicy").(*password.Policy)
+			authService := authservice.NewAuthService(userSvc, sessionSvc, tokenSvc, loginGuard, policy, log, cfg)
 			return authService, nil
 		},
 	})
@@ -120,7 +397,168 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		Name: "authHandler",
 		Build: func(ctn di.Container) (interface{}, error) {
 			authSvc := ctn.Get("authService").(authservice.IAuthService)
-			return authhandler.NewAuthHandler(authSvc), nil
+			sessionSvc := ctn.Get("sessionService").(authservice.ISessionService)
+			tokenSvc := ctn.Get("tokenService").(authservice.ITokenService)
+			mfaSvc := ctn.Get("mfaService").(authservice.IMFAService)
+			passwordSvc := ctn.Get("passwordService").(authservice.IPasswordService)
+			verificationSvc := ctn.Get("emailVerificationService").(accountservice.IEmailVerificationService)
+			registrationSvc := ctn.Get("registrationService").(authservice.IRegistrationService)
+			return authhandler.NewAuthHandler(authSvc, sessionSvc, tokenSvc, mfaSvc, passwordSvc, verificationSvc, registrationSvc), nil
+		},
+	})
+
+	// ImpersonationService
+	builder.Add(di.Def{
+		Name: "impersonationService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+			tokenSvc := ctn.Get("tokenService").(authservice.ITokenService)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewImpersonationService(userSvc, permissionSvc, tokenSvc, log), nil
+		},
+	})
+
+	// ImpersonationHandler
+	builder.Add(di.Def{
+		Name: "impersonationHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			impersonationSvc := ctn.Get("impersonationService").(authservice.IImpersonationService)
+			return authhandler.NewImpersonationHandler(impersonationSvc), nil
+		},
+	})
+
+	// --- OIDC FEATURE ---
+
+	// OIDC provider registry
+	builder.Add(di.Def{
+		Name: "oidcProviders",
+		Build: func(ctn di.Container) (interface{}, error) {
+			return oidc.NewRegistry(cfg.OIDC), nil
+		},
+	})
+
+	// OIDCStateRepository
+	builder.Add(di.Def{
+		Name: "oidcStateRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewOIDCStateRepository(redisClient, log), nil
+		},
+	})
+
+	// IdentityRepository
+	builder.Add(di.Def{
+		Name: "identityRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoDB := ctn.Get("mongoDB").(*mongo.Database)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return accountrepository.NewIdentityRepository(mongoDB, log), nil
+		},
+	})
+
+	// OIDCService
+	builder.Add(di.Def{
+		Name: "oidcService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			providers := ctn.Get("oidcProviders").(*oidc.Registry)
+			stateRepo := ctn.Get("oidcStateRepository").(authrepository.IOIDCStateRepository)
+			identityRepo := ctn.Get("identityRepository").(accountrepository.IIdentityRepository)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			registrationSvc := ctn.Get("registrationService").(authservice.IRegistrationService)
+			authSvc := ctn.Get("authService").(authservice.IAuthService)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewOIDCService(providers, stateRepo, identityRepo, userSvc, registrationSvc, authSvc, log, cfg), nil
+		},
+	})
+
+	// OIDCHandler
+	builder.Add(di.Def{
+		Name: "oidcHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			oidcSvc := ctn.Get("oidcService").(authservice.IOIDCService)
+			return authhandler.NewOIDCHandler(oidcSvc), nil
+		},
+	})
+
+	// --- OAUTH2 AUTHORIZATION SERVER FEATURE ---
+
+	// OAuthClientRepository
+	builder.Add(di.Def{
+		Name: "oauthClientRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoDB := ctn.Get("mongoDB").(*mongo.Database)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewOAuthClientRepository(mongoDB, log), nil
+		},
+	})
+
+	// OAuthConsentRepository
+	builder.Add(di.Def{
+		Name: "oauthConsentRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoDB := ctn.Get("mongoDB").(*mongo.Database)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewOAuthConsentRepository(mongoDB, log), nil
+		},
+	})
+
+	// OAuthTokenRepository
+	builder.Add(di.Def{
+		Name: "oauthTokenRepository",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authrepository.NewOAuthTokenRepository(redisClient, log), nil
+		},
+	})
+
+	// OAuthClientService
+	builder.Add(di.Def{
+		Name: "oauthClientService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			clientRepo := ctn.Get("oauthClientRepository").(authrepository.IOAuthClientRepository)
+			tokenRepo := ctn.Get("oauthTokenRepository").(authrepository.IOAuthTokenRepository)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewOAuthClientService(clientRepo, tokenRepo, userSvc, permissionSvc, catalog, log), nil
+		},
+	})
+
+	// OAuthService
+	builder.Add(di.Def{
+		Name: "oauthService",
+		Build: func(ctn di.Container) (interface{}, error) {
+			clientSvc := ctn.Get("oauthClientService").(authservice.IOAuthClientService)
+			consentRepo := ctn.Get("oauthConsentRepository").(authrepository.IOAuthConsentRepository)
+			tokenRepo := ctn.Get("oauthTokenRepository").(authrepository.IOAuthTokenRepository)
+			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			catalog := ctn.Get("permissionCatalog").(*permission.Catalog)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return authservice.NewOAuthService(clientSvc, consentRepo, tokenRepo, userSvc, catalog, log, cfg), nil
+		},
+	})
+
+	// OAuthHandler
+	builder.Add(di.Def{
+		Name: "oauthHandler",
+		Build: func(ctn di.Container) (interface{}, error) {
+			oauthSvc := ctn.Get("oauthService").(authservice.IOAuthService)
+			clientSvc := ctn.Get("oauthClientService").(authservice.IOAuthClientService)
+			return authhandler.NewOAuthHandler(oauthSvc, clientSvc), nil
+		},
+	})
+
+	// RateLimiter
+	builder.Add(di.Def{
+		Name: "rateLimiter",
+		Build: func(ctn di.Container) (interface{}, error) {
+			redisClient := ctn.Get("redis").(*redis.Client)
+			log := ctn.Get("logger").(*zerolog.Logger)
+			return middleware.NewRateLimiter(redisClient, log, cfg)
 		},
 	})
 
@@ -128,9 +566,19 @@ func BuildContainer(cfg *configs.Config, mongoDB *mongo.Database, logger *zerolo
 		Name: "authMiddleware",
 		Build: func(ctn di.Container) (interface{}, error) {
 			userSvc := ctn.Get("userService").(accountservice.IUserService)
+			sessionSvc := ctn.Get("sessionService").(authservice.ISessionService)
+			tokenSvc := ctn.Get("tokenService").(authservice.ITokenService)
 			log := ctn.Get("logger").(*zerolog.Logger)
 
-			return middleware.NewAuthMiddleware(log, userSvc), nil
+			permissionSvc := ctn.Get("permissionService").(accountservice.IPermissionService)
+
+			apiKeySvc := ctn.Get("apiKeyService").(accountservice.IAPIKeyService)
+
+			oauthSvc := ctn.Get("oauthService").(authservice.IOAuthService)
+
+			rateLimiter := ctn.Get("rateLimiter").(*middleware.RateLimiter)
+
+			return middleware.NewAuthMiddleware(log, userSvc, sessionSvc, tokenSvc, permissionSvc, apiKeySvc, oauthSvc, rateLimiter), nil
 		},
 	})
 
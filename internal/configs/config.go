@@ -1,6 +1,7 @@
 package configs
 
 import (
+	"errors"
 	"log"
 	"strings"
 
@@ -25,6 +26,156 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config.Server.AllowedOrigins = strings.Split(viper.GetString("ALLOWED_ORIGINS"), ",")
+	config.Security.RegistrationAllowedDomains = splitList(viper.GetString("REGISTRATION_ALLOWED_DOMAINS"))
+	config.Security.RateLimitOverrides = splitList(viper.GetString("RATE_LIMIT_OVERRIDES"))
+	config.Security.TrustedProxies = splitList(viper.GetString("TRUSTED_PROXIES"))
+
+	// Issuer dan audience token default ke nama aplikasi
+	if config.Security.JWTIssuer == "" {
+		config.Security.JWTIssuer = config.AppName
+	}
+	if config.Security.JWTAudience == "" {
+		config.Security.JWTAudience = config.AppName
+	}
+
+	if config.Security.PasswordResetExpired <= 0 {
+		config.Security.PasswordResetExpired = 30
+	}
+
+	// Link verifikasi ditandatangani HMAC. JWT_SECRET_KEY hanya dipakai sebagai fallback untuk HS256,
+	// pada signing asimetris secret tersebut tidak wajib diisi sehingga key HMAC bisa kosong.
+	if config.Security.EmailVerificationSecret == "" {
+		method := strings.ToUpper(config.Security.JWTSigningMethod)
+		if (method != "" && method != "HS256") || config.Security.JWTSecretKey == "" {
+			return nil, errors.New("EMAIL_VERIFICATION_SECRET is required when JWT signing is not HS256 with JWT_SECRET_KEY")
+		}
+		config.Security.EmailVerificationSecret = config.Security.JWTSecretKey
+	}
+
+	if config.Security.EmailVerificationExpired <= 0 {
+		config.Security.EmailVerificationExpired = 24
+	}
+
+	if config.Security.InvitationExpired <= 0 {
+		config.Security.InvitationExpired = 72
+	}
+
+	// Limit per user/API key default sama dengan limit per IP
+	if !viper.IsSet("RATE_LIMIT_AUTHENTICATED") {
+		config.Security.RateLimitAuthenticated = config.Security.RateLimit
+	}
+
+	if config.Security.LoginMaxAttempts <= 0 {
+		config.Security.LoginMaxAttempts = 5
+	}
+	if config.Security.LoginMaxAttemptsPerIP <= 0 {
+		config.Security.LoginMaxAttemptsPerIP = 50
+	}
+	if config.Security.LoginAttemptWindow <= 0 {
+		config.Security.LoginAttemptWindow = 15
+	}
+	if config.Security.LoginLockoutDuration <= 0 {
+		config.Security.LoginLockoutDuration = 15
+	}
+	if !viper.IsSet("LOGIN_DELAY_BASE") {
+		config.Security.LoginDelayBase = 250
+	}
+	if config.Security.LoginDelayMax <= 0 {
+		config.Security.LoginDelayMax = 5000
+	}
+
+	if config.Security.PermissionCacheSize <= 0 {
+		config.Security.PermissionCacheSize = 10000
+	}
+	if config.Security.PermissionCacheTTL <= 0 {
+		config.Security.PermissionCacheTTL = 300
+	}
+	if config.Security.ImpersonationExpired <= 0 {
+		config.Security.ImpersonationExpired = 15
+	}
+
+	// Driver email harus dipilih eksplisit di luar development agar email tidak diam-diam hanya ditulis ke log
+	if config.Mail.Driver == "" {
+		if config.AppEnv != "development" {
+			return nil, errors.New("MAIL_DRIVER is required outside development")
+		}
+		config.Mail.Driver = "log"
+	}
+
+	if config.Mail.From == "" {
+		config.Mail.From = "no-reply@localhost"
+	}
+	if config.Mail.Port == 0 {
+		config.Mail.Port = 1025
+	}
+	if config.Mail.FromName == "" {
+		config.Mail.FromName = config.AppName
+	}
+
+	config.OIDC.Providers = loadOIDCProviders(splitList(viper.GetString("OIDC_PROVIDERS")))
+	if config.OIDC.StateExpired <= 0 {
+		config.OIDC.StateExpired = 10
+	}
+
+	if config.PasswordPolicy.MinLength <= 0 {
+		config.PasswordPolicy.MinLength = 12
+	}
+	// bcrypt hanya memakai 72 byte pertama
+	if config.PasswordPolicy.MaxLength <= 0 || config.PasswordPolicy.MaxLength > 72 {
+		config.PasswordPolicy.MaxLength = 72
+	}
+	if !viper.IsSet("PASSWORD_HISTORY") {
+		config.PasswordPolicy.History = 5
+	}
+
+	if config.OAuth.CodeExpired <= 0 {
+		config.OAuth.CodeExpired = 10
+	}
+	if config.OAuth.AccessTokenExpired <= 0 {
+		config.OAuth.AccessTokenExpired = 60
+	}
+	if config.OAuth.RefreshTokenExpired <= 0 {
+		config.OAuth.RefreshTokenExpired = 720
+	}
 
 	return config, nil
 }
+
+// loadOIDCProviders membaca OIDC_<NAMA>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES dan _DISPLAY_NAME
+// untuk setiap nama provider di OIDC_PROVIDERS
+func loadOIDCProviders(names []string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range names {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  viper.GetString(prefix + "DISPLAY_NAME"),
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.Fields(strings.ReplaceAll(viper.GetString(prefix+"SCOPES"), ",", " ")),
+		}
+		if provider.DisplayName == "" {
+			provider.DisplayName = name
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// splitList memecah daftar dipisah koma dan membuang item kosong
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -2,14 +2,18 @@ package configs
 
 type (
 	Config struct {
-		AppName           string         `mapstructure:"APP_NAME"`
-		Version           string         `mapstructure:"VERSION"`
-		AppEnv            string         `mapstructure:"APP_ENV"`
-		Server            ServerConfig   `mapstructure:",squash"`
-		Database          DatabaseConfig `mapstructure:",squash"`
-		Redis             RedisConfig    `mapstructure:",squash"`
-		Security          SecurityConfig `mapstructure:",squash"`
-		Logger            LoggerConfig   `mapstructure:",squash"`
+		AppName           string               `mapstructure:"APP_NAME"`
+		Version           string               `mapstructure:"VERSION"`
+		AppEnv            string               `mapstructure:"APP_ENV"`
+		Server            ServerConfig         `mapstructure:",squash"`
+		Database          DatabaseConfig       `mapstructure:",squash"`
+		Redis             RedisConfig          `mapstructure:",squash"`
+		Security          SecurityConfig       `mapstructure:",squash"`
+		Logger            LoggerConfig         `mapstructure:",squash"`
+		Mail              MailConfig           `mapstructure:",squash"`
+		OIDC              OIDCConfig           `mapstructure:",squash"`
+		OAuth             OAuthConfig          `mapstructure:",squash"`
+		PasswordPolicy    PasswordPolicyConfig `mapstructure:",squash"`
 		ModulePermissions []string
 	}
 )
@@ -42,21 +46,112 @@ type (
 
 	// SecurityConfig menyimpan konfigurasi keamanan aplikasi
 	SecurityConfig struct {
-		CheckOrigin            bool   `mapstructure:"ACTIVATE_ORIGIN_VALIDATION"`
-		RateLimit              int    `mapstructure:"RATE_LIMIT" envDefault:"60"`
-		TrustedPlatform        string `mapstructure:"TRUSTED_PLATFORM"`
-		ExpectedHost           string `mapstructure:"EXPECTED_HOST"`
-		XFrameOptions          string `mapstructure:"X_FRAME_OPTIONS"`
-		ContentSecurity        string `mapstructure:"CONTENT_SECURITY_POLICY"`
-		XXSSProtection         string `mapstructure:"X_XSS_PROTECTION"`
-		StrictTransport        string `mapstructure:"STRICT_TRANSPORT_SECURITY"`
-		ReferrerPolicy         string `mapstructure:"REFERRER_POLICY"`
-		XContentTypeOpts       string `mapstructure:"X_CONTENT_TYPE_OPTIONS"`
-		PermissionsPolicy      string `mapstructure:"PERMISSIONS_POLICY"`
-		JWTSecretKey           string `mapstructure:"JWT_SECRET_KEY"`
-		JWTExpired             int    `mapstructure:"JWT_EXPIRED" envDefault:"15"`
-		JWTRefreshTokenExpired int    `mapstructure:"JWT_REFRESH_TOKEN_EXPIRED" envDefault:"24"`
-		// LimiterInstance        *limiter.Limiter
+		CheckOrigin            bool     `mapstructure:"ACTIVATE_ORIGIN_VALIDATION"`
+		RateLimit              string   `mapstructure:"RATE_LIMIT" envDefault:"60-M"`
+		RateLimitAuthenticated string   `mapstructure:"RATE_LIMIT_AUTHENTICATED"`
+		RateLimitOverrides     []string `mapstructure:"RATE_LIMIT_OVERRIDES"`
+		TrustedPlatform        string   `mapstructure:"TRUSTED_PLATFORM"`
+		TrustedProxies         []string `mapstructure:"TRUSTED_PROXIES"`
+		ExpectedHost           string   `mapstructure:"EXPECTED_HOST"`
+		XFrameOptions          string   `mapstructure:"X_FRAME_OPTIONS"`
+		ContentSecurity        string   `mapstructure:"CONTENT_SECURITY_POLICY"`
+		XXSSProtection         string   `mapstructure:"X_XSS_PROTECTION"`
+		StrictTransport        string   `mapstructure:"STRICT_TRANSPORT_SECURITY"`
+		ReferrerPolicy         string   `mapstructure:"REFERRER_POLICY"`
+		XContentTypeOpts       string   `mapstructure:"X_CONTENT_TYPE_OPTIONS"`
+		PermissionsPolicy      string   `mapstructure:"PERMISSIONS_POLICY"`
+		JWTSecretKey           string   `mapstructure:"JWT_SECRET_KEY"`
+		JWTSigningMethod       string   `mapstructure:"JWT_SIGNING_METHOD" envDefault:"HS256"`
+		JWTKeyID               string   `mapstructure:"JWT_KEY_ID"`
+		JWTPrivateKeyPath      string   `mapstructure:"JWT_PRIVATE_KEY_PATH"`
+		JWTVerificationKeys    string   `mapstructure:"JWT_VERIFICATION_KEYS"`
+		JWTIssuer              string   `mapstructure:"JWT_ISSUER"`
+		JWTAudience            string   `mapstructure:"JWT_AUDIENCE"`
+		JWTExpired             int      `mapstructure:"JWT_EXPIRED" envDefault:"15"`
+		JWTRefreshTokenExpired int      `mapstructure:"JWT_REFRESH_TOKEN_EXPIRED" envDefault:"24"`
+		PasswordResetExpired   int      `mapstructure:"PASSWORD_RESET_EXPIRED" envDefault:"30"`
+		PasswordResetURL       string   `mapstructure:"PASSWORD_RESET_URL"`
+		// RequireEmailVerification menolak login untuk user yang belum memverifikasi email
+		RequireEmailVerification bool   `mapstructure:"REQUIRE_EMAIL_VERIFICATION"`
+		EmailVerificationSecret  string `mapstructure:"EMAIL_VERIFICATION_SECRET"`
+		EmailVerificationExpired int    `mapstructure:"EMAIL_VERIFICATION_EXPIRED" envDefault:"24"`
+		EmailVerificationURL     string `mapstructure:"EMAIL_VERIFICATION_URL"`
+		// RegistrationMode mengatur registrasi publik: disabled, open atau invite
+		RegistrationMode           string   `mapstructure:"REGISTRATION_MODE" envDefault:"disabled"`
+		RegistrationAllowedDomains []string `mapstructure:"REGISTRATION_ALLOWED_DOMAINS"`
+		RegistrationDefaultRole    string   `mapstructure:"REGISTRATION_DEFAULT_ROLE"`
+		InvitationExpired          int      `mapstructure:"INVITATION_EXPIRED" envDefault:"72"`
+		InvitationURL              string   `mapstructure:"INVITATION_URL"`
+		// Proteksi brute-force login, window dan durasi lock dalam menit, delay dalam milidetik
+		LoginMaxAttempts      int `mapstructure:"LOGIN_MAX_ATTEMPTS" envDefault:"5"`
+		LoginMaxAttemptsPerIP int `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_IP" envDefault:"50"`
+		LoginAttemptWindow    int `mapstructure:"LOGIN_ATTEMPT_WINDOW" envDefault:"15"`
+		LoginLockoutDuration  int `mapstructure:"LOGIN_LOCKOUT_DURATION" envDefault:"15"`
+		LoginDelayBase        int `mapstructure:"LOGIN_DELAY_BASE" envDefault:"250"`
+		LoginDelayMax         int `mapstructure:"LOGIN_DELAY_MAX" envDefault:"5000"`
+		// Cache permission efektif per user, TTL dalam detik
+		PermissionCacheSize int `mapstructure:"PERMISSION_CACHE_SIZE" envDefault:"10000"`
+		PermissionCacheTTL  int `mapstructure:"PERMISSION_CACHE_TTL" envDefault:"300"`
+		// Masa berlaku token impersonation dalam menit
+		ImpersonationExpired int `mapstructure:"IMPERSONATION_EXPIRED" envDefault:"15"`
+	}
+
+	// MailConfig menyimpan konfigurasi pengiriman email
+	MailConfig struct {
+		Driver      string `mapstructure:"MAIL_DRIVER"`
+		From        string `mapstructure:"MAIL_FROM"`
+		FromName    string `mapstructure:"MAIL_FROM_NAME"`
+		Host        string `mapstructure:"SMTP_HOST"`
+		Port        int    `mapstructure:"SMTP_PORT" envDefault:"1025"`
+		Username    string `mapstructure:"SMTP_USERNAME"`
+		Password    string `mapstructure:"SMTP_PASSWORD"`
+		Encryption  string `mapstructure:"SMTP_ENCRYPTION" envDefault:"none"`
+		OutboxDir   string `mapstructure:"MAIL_OUTBOX_DIR" envDefault:"storage/outbox"`
+		TemplateDir string `mapstructure:"MAIL_TEMPLATE_DIR"`
+	}
+
+	// OIDCConfig menyimpan konfigurasi login lewat identity provider eksternal (OpenID Connect)
+	OIDCConfig struct {
+		// Providers dibaca dari OIDC_PROVIDERS dan OIDC_<NAMA>_* di LoadConfig
+		Providers []OIDCProviderConfig `mapstructure:"-"`
+		// RedirectURL adalah halaman frontend yang menerima code dan state dari provider
+		RedirectURL  string `mapstructure:"OIDC_REDIRECT_URL"`
+		AllowSignup  bool   `mapstructure:"OIDC_ALLOW_SIGNUP"`
+		StateExpired int    `mapstructure:"OIDC_STATE_EXPIRED" envDefault:"10"`
+	}
+
+	OIDCProviderConfig struct {
+		Name         string
+		DisplayName  string
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		Scopes       []string
+	}
+
+	// PasswordPolicyConfig menyimpan aturan password yang berlaku saat membuat, mengubah dan reset password.
+	// MaxAge dalam hari, 0 berarti password tidak pernah kedaluwarsa.
+	PasswordPolicyConfig struct {
+		MinLength           int  `mapstructure:"PASSWORD_MIN_LENGTH" envDefault:"12"`
+		MaxLength           int  `mapstructure:"PASSWORD_MAX_LENGTH" envDefault:"72"`
+		RequireUppercase    bool `mapstructure:"PASSWORD_REQUIRE_UPPERCASE"`
+		RequireLowercase    bool `mapstructure:"PASSWORD_REQUIRE_LOWERCASE"`
+		RequireDigit        bool `mapstructure:"PASSWORD_REQUIRE_DIGIT"`
+		RequireSymbol       bool `mapstructure:"PASSWORD_REQUIRE_SYMBOL"`
+		MinCharacterClasses int  `mapstructure:"PASSWORD_MIN_CHARACTER_CLASSES"`
+		AllowPersonalInfo   bool `mapstructure:"PASSWORD_ALLOW_PERSONAL_INFO"`
+		History             int  `mapstructure:"PASSWORD_HISTORY" envDefault:"5"`
+		MaxAge              int  `mapstructure:"PASSWORD_MAX_AGE"`
+		// BreachedListPath adalah file hash SHA-1 password yang bocor (format HASH atau HASH:COUNT per baris)
+		BreachedListPath string `mapstructure:"PASSWORD_BREACHED_LIST"`
+	}
+
+	// OAuthConfig menyimpan konfigurasi authorization server OAuth2 untuk client pihak ketiga.
+	// Masa berlaku code dan access token dalam menit, refresh token dalam jam.
+	OAuthConfig struct {
+		CodeExpired         int `mapstructure:"OAUTH_CODE_EXPIRED" envDefault:"10"`
+		AccessTokenExpired  int `mapstructure:"OAUTH_ACCESS_TOKEN_EXPIRED" envDefault:"60"`
+		RefreshTokenExpired int `mapstructure:"OAUTH_REFRESH_TOKEN_EXPIRED" envDefault:"720"`
 	}
 
 	// LoggerConfig menyimpan konfigurasi logger
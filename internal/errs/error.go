@@ -2,6 +2,7 @@
 package errs
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -50,3 +51,23 @@ func Unauthorized(msg string, err error) *CustomError {
 func Forbidden(msg string, err error) *CustomError {
 	return &CustomError{Code: http.StatusForbidden, Message: msg, Err: err}
 }
+
+func Conflict(msg string, err error) *CustomError {
+	return &CustomError{Code: http.StatusConflict, Message: msg, Err: err}
+}
+
+func TooManyRequests(msg string, err error) *CustomError {
+	return &CustomError{Code: http.StatusTooManyRequests, Message: msg, Err: err}
+}
+
+// IsNotFound bernilai true jika err (atau error yang dibungkusnya) adalah NotFound
+func IsNotFound(err error) bool {
+	var customErr *CustomError
+	return errors.As(err, &customErr) && customErr.Code == http.StatusNotFound
+}
+
+// IsConflict bernilai true jika err (atau error yang dibungkusnya) adalah Conflict
+func IsConflict(err error) bool {
+	var customErr *CustomError
+	return errors.As(err, &customErr) && customErr.Code == http.StatusConflict
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	service "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.IAPIKeyService
+	userService   service.IUserService
+	validate      *validator.Validate
+}
+
+func NewAPIKeyHandler(as service.IAPIKeyService, us service.IUserService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: as,
+		userService:   us,
+		validate:      validator.New(),
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary      Create personal API key
+// @Description  Create an API key for the current user. The key is only returned once, use it as "Authorization: ApiKey <key>"
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        api_key  body  account.CreateAPIKeyRequest  true  "API key data"
+// @Success      201  {object}  model.WebResponse{data=account.CreateAPIKeyResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Router       /api-keys [post]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) Create(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	return c.create(ctx, user, user)
+}
+
+// FindAllAPIKeys godoc
+// @Summary      List personal API keys
+// @Description  Retrieve API keys of the current user, including revoked keys
+// @Tags         api-keys
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]account.APIKey}
+// @Failure      401  {object}  model.WebResponse
+// @Router       /api-keys [get]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) FindAll(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	return c.findAll(ctx, user.ID.Hex())
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke personal API key
+// @Description  Revoke an API key of the current user
+// @Tags         api-keys
+// @Produce      json
+// @Param        id   path      string  true  "api key id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /api-keys/{id} [delete]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) Revoke(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	return c.revoke(ctx, user.ID.Hex(), ctx.Param("id"))
+}
+
+// CreateServiceAccount godoc
+// @Summary      Create service account
+// @Description  Create a user for automation that cannot log in and authenticates with API keys only. Assign roles with /roles/assign
+// @Tags         service-accounts
+// @Accept       json
+// @Produce      json
+// @Param        service_account  body  account.CreateServiceAccountRequest  true  "Service account data"
+// @Success      201  {object}  model.WebResponse{data=account.UserResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Router       /service-accounts [post]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) CreateServiceAccount(ctx echo.Context) error {
+	var payload account.CreateServiceAccountRequest
+	if err := ctx.Bind(&payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.validate.Struct(payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	user, err := c.userService.CreateServiceAccount(ctx.Request().Context(), &payload)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "service account created successfully", user.ToUserResponse())
+	return nil
+}
+
+// CreateServiceAccountAPIKey godoc
+// @Summary      Create service account API key
+// @Description  Create an API key for a service account. The key cannot grant permissions the caller does not have and is only returned once
+// @Tags         service-accounts
+// @Accept       json
+// @Produce      json
+// @Param        id       path  string                       true  "service account id"
+// @Param        api_key  body  account.CreateAPIKeyRequest  true  "API key data"
+// @Success      201  {object}  model.WebResponse{data=account.CreateAPIKeyResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /service-accounts/{id}/api-keys [post]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) CreateServiceAccountKey(ctx echo.Context) error {
+	creator, ok := ctx.Get("user").(*account.User)
+	if !ok || creator == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	owner, err := c.findServiceAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.create(ctx, owner, creator)
+}
+
+// FindAllServiceAccountAPIKeys godoc
+// @Summary      List service account API keys
+// @Description  Retrieve API keys of a service account, including revoked keys
+// @Tags         service-accounts
+// @Produce      json
+// @Param        id   path      string  true  "service account id"
+// @Success      200  {object}  model.WebResponse{data=[]account.APIKey}
+// @Failure      404  {object}  model.WebResponse
+// @Router       /service-accounts/{id}/api-keys [get]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) FindAllServiceAccountKeys(ctx echo.Context) error {
+	owner, err := c.findServiceAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.findAll(ctx, owner.ID.Hex())
+}
+
+// RevokeServiceAccountAPIKey godoc
+// @Summary      Revoke service account API key
+// @Description  Revoke an API key of a service account
+// @Tags         service-accounts
+// @Produce      json
+// @Param        id      path  string  true  "service account id"
+// @Param        key_id  path  string  true  "api key id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /service-accounts/{id}/api-keys/{key_id} [delete]
+// @Security ApiKeyAuth
+func (c *APIKeyHandler) RevokeServiceAccountKey(ctx echo.Context) error {
+	owner, err := c.findServiceAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.revoke(ctx, owner.ID.Hex(), ctx.Param("key_id"))
+}
+
+// findServiceAccount memastikan :id adalah service account, key milik user biasa hanya bisa dibuat oleh user itu sendiri
+func (c *APIKeyHandler) findServiceAccount(ctx echo.Context) (*account.User, error) {
+	id := ctx.Param("id")
+	if err := c.validate.Var(id, "required"); err != nil {
+		return nil, errs.BadRequest("bad request", err)
+	}
+
+	user, err := c.userService.FindById(ctx.Request().Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.ServiceAccount {
+		return nil, errs.NotFound("service account not found", nil)
+	}
+
+	return user, nil
+}
+
+func (c *APIKeyHandler) create(ctx echo.Context, owner *account.User, creator *account.User) error {
+	var payload account.CreateAPIKeyRequest
+	if err := ctx.Bind(&payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.validate.Struct(payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	result, err := c.apiKeyService.Create(ctx.Request().Context(), owner, creator, &payload)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "api key created successfully, store it now as it will not be shown again", result)
+	return nil
+}
+
+func (c *APIKeyHandler) findAll(ctx echo.Context, ownerID string) error {
+	keys, err := c.apiKeyService.FindByOwner(ctx.Request().Context(), ownerID)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "api keys retrieved successfully", keys)
+	return nil
+}
+
+func (c *APIKeyHandler) revoke(ctx echo.Context, ownerID string, id string) error {
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.apiKeyService.Revoke(ctx.Request().Context(), ownerID, id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "api key revoked successfully", nil)
+	return nil
+}
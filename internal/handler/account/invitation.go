@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	service "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type InvitationHandler struct {
+	invitationService service.IInvitationService
+	validate          *validator.Validate
+}
+
+func NewInvitationHandler(is service.IInvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: is,
+		validate:          validator.New(),
+	}
+}
+
+// CreateInvitation godoc
+// @Summary      Invite an user
+// @Description  Send an invitation email with a pre-selected set of roles
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param        invitation  body  account.CreateInvitationRequest  true  "Invitation Data"
+// @Success      201  {object}  model.WebResponse{data=account.Invitation}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /invitations [post]
+// @Security ApiKeyAuth
+func (c *InvitationHandler) Create(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var payload account.CreateInvitationRequest
+	if err := ctx.Bind(&payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.validate.Struct(payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	invitation, err := c.invitationService.Create(ctx.Request().Context(), user, &payload)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "invitation sent successfully", invitation)
+	return nil
+}
+
+// FindAllInvitations godoc
+// @Summary      Get all invitations
+// @Description  Retrieve a list of invitations
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param limit query int false "total data per-page" minimum(1) default(10)
+// @Param page query int false "page" minimum(1) default(1)
+// @Param search query string false "email keyword"
+// @Success      200     {object}  model.WebResponse{data=model.DataWithPagination{items=[]account.Invitation}}
+// @Failure      500     {object}  model.WebResponse
+// @Router       /invitations [get]
+// @Security ApiKeyAuth
+func (c *InvitationHandler) FindAll(ctx echo.Context) error {
+	var filter model.PaginationFilter
+
+	// Binding query parameters
+	if err := ctx.Bind(&filter); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	invitations, totalItem, err := c.invitationService.FindAll(ctx.Request().Context(), &filter)
+	if err != nil {
+		return err
+	}
+
+	paginate := helper.BuildPagination(&filter, totalItem)
+	result := model.DataWithPagination{
+		Items:  invitations,
+		Paging: paginate,
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "invitations retrieved successfully", result)
+	return nil
+}
+
+// ResendInvitation godoc
+// @Summary      Resend invitation
+// @Description  Send a new invitation link, the previous link stops working
+// @Tags         invitations
+// @Produce      json
+// @Param id path string true "id"
+// @Success      200     {object}  model.WebResponse
+// @Failure      400     {object}  model.WebResponse
+// @Failure      404     {object}  model.WebResponse
+// @Router       /invitations/{id}/resend [post]
+// @Security ApiKeyAuth
+func (c *InvitationHandler) Resend(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.invitationService.Resend(ctx.Request().Context(), user, id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "invitation resent successfully", nil)
+	return nil
+}
+
+// RevokeInvitation godoc
+// @Summary      Revoke invitation
+// @Description  Revoke a pending invitation
+// @Tags         invitations
+// @Produce      json
+// @Param id path string true "id"
+// @Success      200     {object}  model.WebResponse
+// @Failure      400     {object}  model.WebResponse
+// @Failure      404     {object}  model.WebResponse
+// @Router       /invitations/{id} [delete]
+// @Security ApiKeyAuth
+func (c *InvitationHandler) Revoke(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.invitationService.Revoke(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "invitation revoked successfully", nil)
+	return nil
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept invitation
+// @Description  Create an account from an invitation token with the invitee's own name and password
+// @Tags         invitations
+// @Accept       json
+// @Produce      json
+// @Param        request  body  account.AcceptInvitationRequest  true  "Invitation token and account data"
+// @Success      201  {object}  model.WebResponse{data=account.UserResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /invitations/accept [post]
+func (c *InvitationHandler) Accept(ctx echo.Context) error {
+	var payload account.AcceptInvitationRequest
+	if err := ctx.Bind(&payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.validate.Struct(payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	user, err := c.invitationService.Accept(ctx.Request().Context(), &payload)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "invitation accepted successfully", user)
+	return nil
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+type PermissionHandler struct {
+	catalog *permission.Catalog
+}
+
+func NewPermissionHandler(catalog *permission.Catalog) *PermissionHandler {
+	return &PermissionHandler{
+		catalog: catalog,
+	}
+}
+
+// FindAllPermissions godoc
+// @Summary      Get permission catalog
+// @Description  Retrieve all permissions grouped by resource, including descriptions and implied permissions
+// @Tags         permissions
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]permission.Group}
+// @Failure      401  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Router       /permissions [get]
+// @Security ApiKeyAuth
+func (c *PermissionHandler) FindAll(ctx echo.Context) error {
+	helper.SendSuccess(ctx, http.StatusOK, "permissions retrieved successfully", c.catalog.Groups())
+	return nil
+}
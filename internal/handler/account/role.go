@@ -38,11 +38,6 @@ func NewRoleHandler(rs service.IRoleService) *RoleHandler {
 // @Router       /roles [post]
 // @Security ApiKeyAuth
 func (c *RoleHandler) Create(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:create"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var role account.CreateRoleRequest
 	ctx.Bind(&role)
 
@@ -72,11 +67,6 @@ func (c *RoleHandler) Create(ctx echo.Context) error {
 // @Router       /roles [get]
 // @Security ApiKeyAuth
 func (c *RoleHandler) FindAll(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:read"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var filter model.PaginationFilter
 
 	if err := ctx.Bind(&filter); err != nil {
@@ -110,11 +100,6 @@ func (c *RoleHandler) FindAll(ctx echo.Context) error {
 // @Router       /roles/{id} [get]
 // @Security ApiKeyAuth
 func (c *RoleHandler) FindById(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:read"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 
 	if err := c.validate.Var(id, "required"); err != nil {
@@ -145,11 +130,6 @@ func (c *RoleHandler) FindById(ctx echo.Context) error {
 // @Router       /roles/{id} [put]
 // @Security ApiKeyAuth
 func (c *RoleHandler) Update(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:update"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 	var role account.UpdateRoleRequest
 
@@ -183,11 +163,6 @@ func (c *RoleHandler) Update(ctx echo.Context) error {
 // @Router       /roles/{id} [delete]
 // @Security ApiKeyAuth
 func (c *RoleHandler) Delete(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:delete"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 
 	if err := c.validate.Var(id, "required"); err != nil {
@@ -217,11 +192,6 @@ func (c *RoleHandler) Delete(ctx echo.Context) error {
 // @Router       /roles/assign [post]
 // @Security ApiKeyAuth
 func (c *RoleHandler) AssignUser(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:assign"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var payload account.AssignRoleModel
 	ctx.Bind(&payload)
 
@@ -251,11 +221,6 @@ func (c *RoleHandler) AssignUser(ctx echo.Context) error {
 // @Router       /roles/unassign [post]
 // @Security ApiKeyAuth
 func (c *RoleHandler) UnAssignUser(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"roles:unassign"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var payload account.AssignRoleModel
 	ctx.Bind(&payload)
 
@@ -0,0 +1,29 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/account"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func NewAPIKeyRoute(router *echo.Group, handler *handler.APIKeyHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Kredensial hanya bisa dikelola dari session user, bukan dengan API key
+	route := router.Group("/v1/api-keys")
+	{
+		route.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+
+		route.POST("", handler.Create)
+		route.GET("", handler.FindAll)
+		route.DELETE("/:id", handler.Revoke)
+	}
+
+	serviceAccountRoutes := router.Group("/v1/service-accounts")
+	{
+		serviceAccountRoutes.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+
+		serviceAccountRoutes.POST("", handler.CreateServiceAccount, authMiddleware.RequirePermission("service_accounts:manage"))
+		serviceAccountRoutes.GET("/:id/api-keys", handler.FindAllServiceAccountKeys, authMiddleware.RequirePermission("service_accounts:manage"))
+		serviceAccountRoutes.POST("/:id/api-keys", handler.CreateServiceAccountKey, authMiddleware.RequirePermission("service_accounts:manage"))
+		serviceAccountRoutes.DELETE("/:id/api-keys/:key_id", handler.RevokeServiceAccountKey, authMiddleware.RequirePermission("service_accounts:manage"))
+	}
+}
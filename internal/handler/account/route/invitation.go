@@ -0,0 +1,20 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/account"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func NewInvitationRoute(router *echo.Group, handler *handler.InvitationHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
+	route := router.Group("/v1/invitations")
+	{
+		// Accept dipakai oleh calon user yang belum punya akun
+		route.POST("/accept", handler.Accept, rateLimiter.Limit("invitation_accept", "10-M"))
+
+		route.POST("", handler.Create, authMiddleware.AuthRequired(), authMiddleware.RequirePermission("users:invite"))
+		route.GET("", handler.FindAll, authMiddleware.AuthRequired(), authMiddleware.RequirePermission("users:invite"))
+		route.POST("/:id/resend", handler.Resend, authMiddleware.AuthRequired(), authMiddleware.RequirePermission("users:invite"))
+		route.DELETE("/:id", handler.Revoke, authMiddleware.AuthRequired(), authMiddleware.RequirePermission("users:invite"))
+	}
+}
@@ -0,0 +1,15 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/account"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func NewPermissionRoute(router *echo.Group, handler *handler.PermissionHandler, authMiddleware *middleware.AuthMiddleware) {
+	route := router.Group("/v1/permissions")
+	route.Use(authMiddleware.AuthRequired())
+	{
+		route.GET("", handler.FindAll, authMiddleware.RequirePermission("roles:read"))
+	}
+}
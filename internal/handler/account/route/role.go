@@ -11,13 +11,13 @@ func NewRoleRoute(router *echo.Group, handler *handler.RoleHandler, authMiddlewa
 	route.Use(authMiddleware.AuthRequired())
 	{
 		// route.Use(middleware.AuthMiddleware(app))
-		route.POST("", handler.Create)
-		route.GET("", handler.FindAll)
-		route.GET("/:id", handler.FindById)
-		route.PUT("/:id", handler.Update)
-		route.DELETE("/:id", handler.Delete)
-		route.POST("/assign", handler.AssignUser)
-		route.POST("/unassign", handler.UnAssignUser)
+		route.POST("", handler.Create, authMiddleware.RequirePermission("roles:create"))
+		route.GET("", handler.FindAll, authMiddleware.RequirePermission("roles:read"))
+		route.GET("/:id", handler.FindById, authMiddleware.RequirePermission("roles:read"))
+		route.PUT("/:id", handler.Update, authMiddleware.RequirePermission("roles:update"))
+		route.DELETE("/:id", handler.Delete, authMiddleware.RequirePermission("roles:delete"))
+		route.POST("/assign", handler.AssignUser, authMiddleware.RequirePermission("roles:assign"))
+		route.POST("/unassign", handler.UnAssignUser, authMiddleware.RequirePermission("roles:unassign"))
 
 	}
 }
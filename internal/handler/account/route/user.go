@@ -11,12 +11,14 @@ func NewUserRoute(router *echo.Group, handler *handler.UserHandler, authMiddlewa
 	{
 		userRoutes.Use(authMiddleware.AuthRequired())
 
-		userRoutes.POST("", handler.Create)
-		userRoutes.GET("/", handler.FindAll)
-		userRoutes.GET("/:id", handler.FindById)
-		userRoutes.GET("/me", handler.GetCurrentUser)
-		userRoutes.PUT("/:id", handler.Update)
-		userRoutes.DELETE("/:id", handler.Delete)
-
+		userRoutes.POST("", handler.Create, authMiddleware.RequirePermission("users:create"))
+		userRoutes.GET("/", handler.FindAll, authMiddleware.RequirePermission("users:read"))
+		userRoutes.GET("/:id", handler.FindById, authMiddleware.RequirePermission("users:read"))
+		userRoutes.GET("/me", handler.GetCurrentUser, authMiddleware.RequirePermission("users:read"))
+		userRoutes.PUT("/:id", handler.Update, authMiddleware.RequirePermission("users:update"))
+		userRoutes.DELETE("/:id", handler.Delete, authMiddleware.RequirePermission("users:delete"))
+		userRoutes.POST("/:id/unlock", handler.Unlock, authMiddleware.RequirePermission("users:update"))
+		userRoutes.POST("/:id/disable", handler.Disable, authMiddleware.RequirePermission("users:update"))
+		userRoutes.POST("/:id/enable", handler.Enable, authMiddleware.RequirePermission("users:update"))
 	}
 }
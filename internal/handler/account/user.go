@@ -35,8 +35,14 @@ func NewUserHandler(us service.IUserService) *UserHandler {
 // @Security     ApiKeyAuth
 func (c *UserHandler) GetCurrentUser(ctx echo.Context) error {
 	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:read"}) {
-		return errs.Forbidden("Forbidden", nil)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	// User di context tidak membawa detail role, ambil ulang untuk response
+	user, err := c.userService.FindById(ctx.Request().Context(), user.ID.Hex())
+	if err != nil {
+		return err
 	}
 
 	resp := user.ToUserResponse()
@@ -58,11 +64,6 @@ func (c *UserHandler) GetCurrentUser(ctx echo.Context) error {
 // @Router       /users [post]
 // @Security ApiKeyAuth
 func (c *UserHandler) Create(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:create"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var payload account.CreateUserRequest
 	ctx.Bind(&payload)
 
@@ -70,7 +71,7 @@ func (c *UserHandler) Create(ctx echo.Context) error {
 		return errs.BadRequest("bad request", err)
 	}
 
-	if err := c.userService.Create(ctx.Request().Context(), &payload); err != nil {
+	if _, err := c.userService.Create(ctx.Request().Context(), &payload); err != nil {
 		return err
 	}
 
@@ -92,11 +93,6 @@ func (c *UserHandler) Create(ctx echo.Context) error {
 // @Router       /users [get]
 // @Security ApiKeyAuth
 func (c *UserHandler) FindAll(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:read"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	var filter model.PaginationFilter
 
 	// Binding query parameters
@@ -131,11 +127,6 @@ func (c *UserHandler) FindAll(ctx echo.Context) error {
 // @Router       /users/{id} [get]
 // @Security ApiKeyAuth
 func (c *UserHandler) FindById(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:read"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 
 	if err := c.validate.Var(id, "required"); err != nil {
@@ -166,11 +157,6 @@ func (c *UserHandler) FindById(ctx echo.Context) error {
 // @Router       /users/{id} [put]
 // @Security ApiKeyAuth
 func (c *UserHandler) Update(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:update"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 	var payload account.UpdateUserRequest
 
@@ -180,7 +166,7 @@ func (c *UserHandler) Update(ctx echo.Context) error {
 		return errs.BadRequest("bad request", err)
 	}
 
-	if err := c.validate.Struct(user); err != nil {
+	if err := c.validate.Struct(payload); err != nil {
 		return errs.BadRequest("bad request", err)
 	}
 
@@ -204,11 +190,6 @@ func (c *UserHandler) Update(ctx echo.Context) error {
 // @Router       /users/{id} [delete]
 // @Security ApiKeyAuth
 func (c *UserHandler) Delete(ctx echo.Context) error {
-	user, ok := ctx.Get("user").(*account.User)
-	if !ok || user == nil || !user.IsHasAccess([]string{"users:delete"}) {
-		return errs.Forbidden("Forbidden", nil)
-	}
-
 	id := ctx.Param("id")
 
 	if err := c.validate.Var(id, "required"); err != nil {
@@ -223,3 +204,85 @@ func (c *UserHandler) Delete(ctx echo.Context) error {
 	helper.SendSuccess(ctx, http.StatusOK, "user deleted successfully", nil)
 	return nil
 }
+
+// UnlockUser godoc
+// @Summary      Unlock user
+// @Description  Remove a temporary login lock from an user account
+// @Tags         users
+// @Produce      json
+// @Param id path string true "id"
+// @Success      200     {object}  model.WebResponse
+// @Failure      403     {object}  model.WebResponse
+// @Failure      404     {object}  model.WebResponse
+// @Router       /users/{id}/unlock [post]
+// @Security ApiKeyAuth
+func (c *UserHandler) Unlock(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.userService.Unlock(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "user unlocked successfully", nil)
+	return nil
+}
+
+// DisableUser godoc
+// @Summary      Disable user
+// @Description  Disable an user account, existing sessions, API keys and OAuth tokens of the account are rejected until it is enabled again
+// @Tags         users
+// @Produce      json
+// @Param id path string true "id"
+// @Success      200     {object}  model.WebResponse
+// @Failure      403     {object}  model.WebResponse
+// @Failure      404     {object}  model.WebResponse
+// @Router       /users/{id}/disable [post]
+// @Security ApiKeyAuth
+func (c *UserHandler) Disable(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if user, ok := ctx.Get("real_user").(*account.User); ok && user.ID.Hex() == id {
+		return errs.BadRequest("you cannot disable your own account", nil)
+	}
+
+	if err := c.userService.Disable(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "user disabled successfully", nil)
+	return nil
+}
+
+// EnableUser godoc
+// @Summary      Enable user
+// @Description  Enable a disabled user account
+// @Tags         users
+// @Produce      json
+// @Param id path string true "id"
+// @Success      200     {object}  model.WebResponse
+// @Failure      403     {object}  model.WebResponse
+// @Failure      404     {object}  model.WebResponse
+// @Router       /users/{id}/enable [post]
+// @Security ApiKeyAuth
+func (c *UserHandler) Enable(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.userService.Enable(ctx.Request().Context(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "user enabled successfully", nil)
+	return nil
+}
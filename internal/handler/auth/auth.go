@@ -5,21 +5,35 @@ import (
 
 	"github.com/HasanNugroho/golang-starter/internal/errs"
 	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
 	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
 	"github.com/HasanNugroho/golang-starter/internal/service/auth"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
 type AuthHandler struct {
-	authService auth.IAuthService
-	validate    *validator.Validate
+	authService         auth.IAuthService
+	sessionService      auth.ISessionService
+	tokenService        auth.ITokenService
+	mfaService          auth.IMFAService
+	passwordService     auth.IPasswordService
+	verificationService accountservice.IEmailVerificationService
+	registrationService auth.IRegistrationService
+	validate            *validator.Validate
 }
 
-func NewAuthHandler(rs auth.IAuthService) *AuthHandler {
+func NewAuthHandler(rs auth.IAuthService, ss auth.ISessionService, ts auth.ITokenService, ms auth.IMFAService, ps auth.IPasswordService, vs accountservice.IEmailVerificationService, rgs auth.IRegistrationService) *AuthHandler {
 	return &AuthHandler{
-		authService: rs,
-		validate:    validator.New(),
+		authService:         rs,
+		sessionService:      ss,
+		tokenService:        ts,
+		mfaService:          ms,
+		passwordService:     ps,
+		verificationService: vs,
+		registrationService: rgs,
+		validate:            validator.New(),
 	}
 }
 
@@ -46,7 +60,7 @@ func (c *AuthHandler) Login(ctx echo.Context) error {
 		return errs.BadRequest("validation error", err)
 	}
 
-	resp, err := c.authService.Login(ctx.Request().Context(), request)
+	resp, err := c.authService.Login(ctx.Request().Context(), request, clientInfo(ctx))
 	if err != nil {
 		return err
 	}
@@ -78,7 +92,7 @@ func (c *AuthHandler) RefreshToken(ctx echo.Context) error {
 		return errs.BadRequest("validation error", err)
 	}
 
-	resp, err := c.authService.RefreshToken(ctx.Request().Context(), request)
+	resp, err := c.authService.RefreshToken(ctx.Request().Context(), request, clientInfo(ctx))
 	if err != nil {
 		return err
 	}
@@ -86,3 +100,159 @@ func (c *AuthHandler) RefreshToken(ctx echo.Context) error {
 	helper.SendSuccess(ctx, http.StatusOK, "login successful", resp)
 	return nil
 }
+
+// Logout godoc
+// @Summary      User logout
+// @Description  Revoke the presented access token, the caller's refresh token and the current session
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        logout  body  auth.LogoutRequest  false  "Refresh token of the current session"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/logout [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) Logout(ctx echo.Context) error {
+	var request model.LogoutRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+	request.AccessToken = helper.ExtractToken(ctx)
+
+	if err := c.authService.Logout(ctx.Request().Context(), request); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "logout successful", nil)
+	return nil
+}
+
+// LogoutAll godoc
+// @Summary      User logout from all devices
+// @Description  Revoke the presented tokens and every session of the current user
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        logout  body  auth.LogoutRequest  false  "Refresh token of the current session"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/logout/all [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) LogoutAll(ctx echo.Context) error {
+	var request model.LogoutRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+	request.AccessToken = helper.ExtractToken(ctx)
+
+	if err := c.authService.LogoutAll(ctx.Request().Context(), request); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "logout successful", nil)
+	return nil
+}
+
+// FindSessions godoc
+// @Summary      List sessions
+// @Description  Retrieve all active sessions of the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]auth.SessionResponse}
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/sessions [get]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) FindSessions(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	sessions, err := c.sessionService.FindByUser(ctx.Request().Context(), user.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	currentSessionID, _ := ctx.Get("session_id").(string)
+	result := []model.SessionResponse{}
+	for _, session := range *sessions {
+		result = append(result, session.ToSessionResponse(currentSessionID))
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "sessions retrieved successfully", result)
+	return nil
+}
+
+// RevokeSession godoc
+// @Summary      Revoke session
+// @Description  Revoke a session of the current user by ID
+// @Tags         auth
+// @Produce      json
+// @Param        id   path      string  true  "session id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/sessions/{id} [delete]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) RevokeSession(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	id := ctx.Param("id")
+
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.sessionService.Revoke(ctx.Request().Context(), user.ID.Hex(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "session revoked successfully", nil)
+	return nil
+}
+
+// RevokeAllSessions godoc
+// @Summary      Revoke all sessions
+// @Description  Revoke every session of the current user, including the current one
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/sessions [delete]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) RevokeAllSessions(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	if err := c.sessionService.RevokeAll(ctx.Request().Context(), user.ID.Hex()); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "sessions revoked successfully", nil)
+	return nil
+}
+
+// JWKS mengembalikan public key untuk verifikasi token oleh service lain (RFC 7517)
+func (c *AuthHandler) JWKS(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, c.tokenService.JWKS())
+}
+
+func clientInfo(ctx echo.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IPAddress: ctx.RealIP(),
+		Device:    ctx.Request().Header.Get("User-Agent"),
+	}
+}
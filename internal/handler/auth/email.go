@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/labstack/echo/v4"
+)
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Confirm an email address using the token from the verification link
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  account.VerifyEmailRequest  true  "Verification token"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/email/verify [post]
+func (c *AuthHandler) VerifyEmail(ctx echo.Context) error {
+	var request account.VerifyEmailRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	if err := c.verificationService.Verify(ctx.Request().Context(), request.Token); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "email verified successfully", nil)
+	return nil
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification link for an unverified or pending email of the given account
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  account.ResendVerificationRequest  true  "Account email"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/email/resend [post]
+func (c *AuthHandler) ResendVerification(ctx echo.Context) error {
+	var request account.ResendVerificationRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	if err := c.verificationService.Resend(ctx.Request().Context(), request); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "if the email is registered and unverified, a verification link has been sent", nil)
+	return nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/auth"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type ImpersonationHandler struct {
+	impersonationService auth.IImpersonationService
+	validate             *validator.Validate
+}
+
+func NewImpersonationHandler(is auth.IImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: is,
+		validate:             validator.New(),
+	}
+}
+
+// StartImpersonation godoc
+// @Summary      Start impersonation
+// @Description  Issue a short-lived access token to act as another user. The token carries an act claim with the staff user, has no refresh token and cannot manage credentials. Administrators cannot be impersonated
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  auth.ImpersonationRequest  true  "Target user and reason"
+// @Success      200  {object}  model.WebResponse{data=auth.ImpersonationResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /auth/impersonation [post]
+// @Security     ApiKeyAuth
+func (c *ImpersonationHandler) Start(ctx echo.Context) error {
+	actor, ok := ctx.Get("user").(*account.User)
+	if !ok || actor == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+	sessionID, _ := ctx.Get("session_id").(string)
+
+	var request model.ImpersonationRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	result, err := c.impersonationService.Start(ctx.Request().Context(), actor, sessionID, request, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "impersonation started", result)
+	return nil
+}
+
+// StopImpersonation godoc
+// @Summary      Stop impersonation
+// @Description  Revoke the impersonation token used for this request
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Router       /auth/impersonation [delete]
+// @Security     ApiKeyAuth
+func (c *ImpersonationHandler) Stop(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+	actor, ok := ctx.Get("real_user").(*account.User)
+	if !ok || actor == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	if err := c.impersonationService.Stop(ctx.Request().Context(), actor, user, helper.ExtractToken(ctx), clientInfo(ctx)); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "impersonation stopped", nil)
+	return nil
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// EnrollMFA godoc
+// @Summary      Enroll two-factor authentication
+// @Description  Generate a new TOTP secret and otpauth:// URI for the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=auth.MFAEnrollResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/mfa/enroll [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) EnrollMFA(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	resp, err := c.mfaService.Enroll(ctx.Request().Context(), user)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "two-factor enrollment started", resp)
+	return nil
+}
+
+// ActivateMFA godoc
+// @Summary      Activate two-factor authentication
+// @Description  Verify a TOTP code for the enrolled secret and enable two-factor authentication
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body  auth.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  model.WebResponse{data=auth.MFARecoveryCodesResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/mfa/activate [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) ActivateMFA(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var request model.MFACodeRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	resp, err := c.mfaService.Activate(ctx.Request().Context(), user, request, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "two-factor authentication enabled", resp)
+	return nil
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate recovery codes
+// @Description  Generate a new set of one-time recovery codes and invalidate the old ones
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body  auth.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  model.WebResponse{data=auth.MFARecoveryCodesResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/mfa/recovery-codes [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) RegenerateRecoveryCodes(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var request model.MFACodeRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	resp, err := c.mfaService.RegenerateRecoveryCodes(ctx.Request().Context(), user, request, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "recovery codes regenerated", resp)
+	return nil
+}
+
+// DisableMFA godoc
+// @Summary      Disable two-factor authentication
+// @Description  Disable two-factor authentication after verifying a TOTP code
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body  auth.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/mfa/disable [post]
+// @Security     ApiKeyAuth
+func (c *AuthHandler) DisableMFA(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var request model.MFACodeRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	if err := c.mfaService.Disable(ctx.Request().Context(), user, request, clientInfo(ctx)); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "two-factor authentication disabled", nil)
+	return nil
+}
+
+// VerifyMFA godoc
+// @Summary      Complete two-factor login
+// @Description  Exchange the mfa_token returned by login and a TOTP code or recovery code for an access token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        mfa  body  auth.MFAVerifyRequest  true  "MFA token and TOTP or recovery code"
+// @Success      200  {object}  model.WebResponse{data=auth.AuthResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/mfa/verify [post]
+func (c *AuthHandler) VerifyMFA(ctx echo.Context) error {
+	var request model.MFAVerifyRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	resp, err := c.authService.VerifyMFA(ctx.Request().Context(), request, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "login successful", resp)
+	return nil
+}
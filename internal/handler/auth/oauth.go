@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/auth"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type OAuthHandler struct {
+	oauthService  auth.IOAuthService
+	clientService auth.IOAuthClientService
+	validate      *validator.Validate
+}
+
+func NewOAuthHandler(os auth.IOAuthService, cs auth.IOAuthClientService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService:  os,
+		clientService: cs,
+		validate:      validator.New(),
+	}
+}
+
+// CreateOAuthClient godoc
+// @Summary      Register OAuth2 client
+// @Description  Register a third-party client. Scopes are permission names from /permissions. The client secret is only returned once; public clients get no secret and must use PKCE
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        client  body  auth.CreateOAuthClientRequest  true  "Client data"
+// @Success      201  {object}  model.WebResponse{data=auth.CreateOAuthClientResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Router       /oauth/clients [post]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) CreateClient(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var payload model.CreateOAuthClientRequest
+	if err := ctx.Bind(&payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.validate.Struct(payload); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	result, err := c.clientService.Create(ctx.Request().Context(), user, &payload)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "oauth client created successfully, store the secret now as it will not be shown again", result)
+	return nil
+}
+
+// FindAllOAuthClients godoc
+// @Summary      List OAuth2 clients
+// @Description  Retrieve registered clients, including revoked clients
+// @Tags         oauth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]auth.OAuthClient}
+// @Failure      403  {object}  model.WebResponse
+// @Router       /oauth/clients [get]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) FindAllClients(ctx echo.Context) error {
+	clients, err := c.clientService.FindAll(ctx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "oauth clients retrieved successfully", clients)
+	return nil
+}
+
+// RevokeOAuthClient godoc
+// @Summary      Revoke OAuth2 client
+// @Description  Revoke a client and every token issued to it
+// @Tags         oauth
+// @Produce      json
+// @Param        client_id  path  string  true  "client id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /oauth/clients/{client_id} [delete]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) RevokeClient(ctx echo.Context) error {
+	clientID := ctx.Param("client_id")
+	if err := c.validate.Var(clientID, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.clientService.Revoke(ctx.Request().Context(), clientID); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "oauth client revoked successfully", nil)
+	return nil
+}
+
+// OAuthAuthorize godoc
+// @Summary      Start OAuth2 authorization
+// @Description  Called by the consent page with the authorization request parameters of the client. Returns redirect_uri when the browser must be sent back to the client (consent already granted or request rejected), otherwise the data for the consent page
+// @Tags         oauth
+// @Produce      json
+// @Param        response_type          query  string  true   "must be code"
+// @Param        client_id              query  string  true   "client id"
+// @Param        redirect_uri           query  string  false  "registered redirect uri"
+// @Param        scope                  query  string  false  "space separated permissions"
+// @Param        state                  query  string  false  "opaque client state"
+// @Param        code_challenge         query  string  true   "PKCE code challenge"
+// @Param        code_challenge_method  query  string  true   "must be S256"
+// @Success      200  {object}  model.WebResponse{data=auth.OAuthAuthorizeResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Router       /oauth/authorize [get]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) Authorize(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var request model.OAuthAuthorizeRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	result, err := c.oauthService.Authorize(ctx.Request().Context(), user, request)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "authorization request validated successfully", result)
+	return nil
+}
+
+// OAuthConsent godoc
+// @Summary      Approve or deny OAuth2 authorization
+// @Description  Submit the user's decision from the consent page. Redirect the browser to the returned redirect_uri
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  auth.OAuthConsentDecisionRequest  true  "Authorization request and decision"
+// @Success      200  {object}  model.WebResponse{data=auth.OAuthAuthorizeResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Router       /oauth/authorize [post]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) Decide(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	var request model.OAuthConsentDecisionRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	result, err := c.oauthService.Decide(ctx.Request().Context(), user, request)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "authorization decision saved successfully", result)
+	return nil
+}
+
+// OAuthToken godoc
+// @Summary      OAuth2 token endpoint
+// @Description  RFC 6749 token endpoint for the authorization_code (with PKCE), refresh_token and client_credentials grants. Authenticate with HTTP Basic or client_id/client_secret in the form. Responses use the OAuth2 format, not the standard envelope
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "authorization_code, refresh_token or client_credentials"
+// @Param        code           formData  string  false  "authorization code"
+// @Param        redirect_uri   formData  string  false  "redirect uri used in the authorization request"
+// @Param        code_verifier  formData  string  false  "PKCE code verifier"
+// @Param        refresh_token  formData  string  false  "refresh token"
+// @Param        scope          formData  string  false  "space separated permissions"
+// @Param        client_id      formData  string  false  "client id"
+// @Param        client_secret  formData  string  false  "client secret"
+// @Success      200  {object}  auth.OAuthTokenResponse
+// @Failure      400  {object}  auth.OAuthError
+// @Failure      401  {object}  auth.OAuthError
+// @Router       /oauth/token [post]
+func (c *OAuthHandler) Token(ctx echo.Context) error {
+	var request model.OAuthTokenRequest
+	if err := ctx.Bind(&request); err != nil {
+		return c.sendOAuthError(ctx, model.NewOAuthError(http.StatusBadRequest, "invalid_request", "invalid request format"))
+	}
+
+	credentials, err := clientCredentials(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	result, err := c.oauthService.Token(ctx.Request().Context(), credentials, request)
+	if err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	noStore(ctx)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// OAuthIntrospect godoc
+// @Summary      OAuth2 token introspection
+// @Description  RFC 7662 introspection for confidential clients. Tokens issued to other clients are reported as inactive
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "access or refresh token"
+// @Param        token_type_hint  formData  string  false  "access_token or refresh_token"
+// @Param        client_id        formData  string  false  "client id"
+// @Param        client_secret    formData  string  false  "client secret"
+// @Success      200  {object}  auth.OAuthIntrospectionResponse
+// @Failure      401  {object}  auth.OAuthError
+// @Router       /oauth/introspect [post]
+func (c *OAuthHandler) Introspect(ctx echo.Context) error {
+	var request model.OAuthTokenLookupRequest
+	if err := ctx.Bind(&request); err != nil {
+		return c.sendOAuthError(ctx, model.NewOAuthError(http.StatusBadRequest, "invalid_request", "invalid request format"))
+	}
+
+	credentials, err := clientCredentials(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	result, err := c.oauthService.Introspect(ctx.Request().Context(), credentials, request)
+	if err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	noStore(ctx)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// OAuthRevoke godoc
+// @Summary      OAuth2 token revocation
+// @Description  RFC 7009 revocation. Revoking a refresh token also revokes the access tokens of the same grant. Unknown tokens are answered with 200
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Param        token            formData  string  true   "access or refresh token"
+// @Param        token_type_hint  formData  string  false  "access_token or refresh_token"
+// @Param        client_id        formData  string  false  "client id"
+// @Param        client_secret    formData  string  false  "client secret"
+// @Success      200
+// @Failure      401  {object}  auth.OAuthError
+// @Router       /oauth/revoke [post]
+func (c *OAuthHandler) Revoke(ctx echo.Context) error {
+	var request model.OAuthTokenLookupRequest
+	if err := ctx.Bind(&request); err != nil {
+		return c.sendOAuthError(ctx, model.NewOAuthError(http.StatusBadRequest, "invalid_request", "invalid request format"))
+	}
+
+	credentials, err := clientCredentials(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	if err := c.oauthService.Revoke(ctx.Request().Context(), credentials, request); err != nil {
+		return c.sendOAuthError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// FindOAuthConsents godoc
+// @Summary      List OAuth2 consents
+// @Description  Retrieve the clients the current user has authorized
+// @Tags         oauth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]auth.OAuthConsent}
+// @Failure      401  {object}  model.WebResponse
+// @Router       /oauth/consents [get]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) FindConsents(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	consents, err := c.oauthService.FindConsents(ctx.Request().Context(), user.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "consents retrieved successfully", consents)
+	return nil
+}
+
+// RevokeOAuthConsent godoc
+// @Summary      Revoke OAuth2 consent
+// @Description  Withdraw the consent given to a client and revoke every token it holds for the current user
+// @Tags         oauth
+// @Produce      json
+// @Param        client_id  path  string  true  "client id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /oauth/consents/{client_id} [delete]
+// @Security     ApiKeyAuth
+func (c *OAuthHandler) RevokeConsent(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	clientID := ctx.Param("client_id")
+	if err := c.validate.Var(clientID, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.oauthService.RevokeConsent(ctx.Request().Context(), user.ID.Hex(), clientID); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "consent revoked successfully", nil)
+	return nil
+}
+
+// sendOAuthError menulis error protokol dalam format RFC 6749, error lain diteruskan ke error middleware
+func (c *OAuthHandler) sendOAuthError(ctx echo.Context, err error) error {
+	var oauthErr *model.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return err
+	}
+
+	noStore(ctx)
+	if oauthErr.Status == http.StatusUnauthorized {
+		ctx.Response().Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	return ctx.JSON(oauthErr.Status, oauthErr)
+}
+
+// clientCredentials mengambil kredensial client dari HTTP Basic (client_secret_basic) atau body form
+// (client_secret_post), client tidak boleh memakai keduanya sekaligus
+func clientCredentials(ctx echo.Context, formClientID string, formClientSecret string) (model.OAuthClientCredentials, error) {
+	username, password, ok := ctx.Request().BasicAuth()
+	if !ok {
+		return model.OAuthClientCredentials{ClientID: formClientID, ClientSecret: formClientSecret}, nil
+	}
+
+	if formClientSecret != "" {
+		return model.OAuthClientCredentials{}, model.NewOAuthError(http.StatusBadRequest, "invalid_request", "use only one client authentication method")
+	}
+
+	// Nilai Basic auth di-encode dengan application/x-www-form-urlencoded (RFC 6749 bagian 2.3.1)
+	clientID, err := url.QueryUnescape(username)
+	if err != nil {
+		return model.OAuthClientCredentials{}, model.NewOAuthError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+	clientSecret, err := url.QueryUnescape(password)
+	if err != nil {
+		return model.OAuthClientCredentials{}, model.NewOAuthError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+
+	return model.OAuthClientCredentials{ClientID: clientID, ClientSecret: clientSecret}, nil
+}
+
+func noStore(ctx echo.Context) {
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	ctx.Response().Header().Set("Pragma", "no-cache")
+}
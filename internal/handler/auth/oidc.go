@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/auth"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type OIDCHandler struct {
+	oidcService auth.IOIDCService
+	validate    *validator.Validate
+}
+
+func NewOIDCHandler(os auth.IOIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: os,
+		validate:    validator.New(),
+	}
+}
+
+// OIDCProviders godoc
+// @Summary      List identity providers
+// @Description  Retrieve the external identity providers available for sign in
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]auth.OIDCProviderResponse}
+// @Router       /auth/oidc/providers [get]
+func (c *OIDCHandler) Providers(ctx echo.Context) error {
+	helper.SendSuccess(ctx, http.StatusOK, "identity providers retrieved successfully", c.oidcService.Providers())
+	return nil
+}
+
+// OIDCAuthorize godoc
+// @Summary      Start identity provider login
+// @Description  Create the authorization URL (authorization code + PKCE). Redirect the browser to it; the provider redirects back to OIDC_REDIRECT_URL with code and state
+// @Tags         auth
+// @Produce      json
+// @Param        provider  path  string  true  "provider name"
+// @Success      200  {object}  model.WebResponse{data=auth.OIDCAuthorizeResponse}
+// @Failure      404  {object}  model.WebResponse
+// @Router       /auth/oidc/{provider}/authorize [get]
+func (c *OIDCHandler) Authorize(ctx echo.Context) error {
+	result, err := c.oidcService.Authorize(ctx.Request().Context(), ctx.Param("provider"))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "authorization url created successfully", result)
+	return nil
+}
+
+// OIDCCallback godoc
+// @Summary      Finish identity provider login
+// @Description  Exchange the code and state received from the provider for our token pair
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        provider  path  string                     true  "provider name"
+// @Param        request   body  auth.OIDCCallbackRequest  true  "Code and state"
+// @Success      200  {object}  model.WebResponse{data=auth.AuthResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      401  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Router       /auth/oidc/{provider}/callback [post]
+func (c *OIDCHandler) Callback(ctx echo.Context) error {
+	var request model.OIDCCallbackRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	result, err := c.oidcService.Callback(ctx.Request().Context(), ctx.Param("provider"), request, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "login successful", result)
+	return nil
+}
+
+// FindIdentities godoc
+// @Summary      List linked identities
+// @Description  Retrieve external identities linked to the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  model.WebResponse{data=[]account.UserIdentity}
+// @Failure      401  {object}  model.WebResponse
+// @Router       /auth/identities [get]
+// @Security     ApiKeyAuth
+func (c *OIDCHandler) FindIdentities(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	identities, err := c.oidcService.FindIdentities(ctx.Request().Context(), user.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "identities retrieved successfully", identities)
+	return nil
+}
+
+// UnlinkIdentity godoc
+// @Summary      Unlink identity
+// @Description  Remove an external identity from the current user
+// @Tags         auth
+// @Produce      json
+// @Param        id   path      string  true  "identity id"
+// @Success      200  {object}  model.WebResponse
+// @Failure      404  {object}  model.WebResponse
+// @Router       /auth/identities/{id} [delete]
+// @Security     ApiKeyAuth
+func (c *OIDCHandler) Unlink(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(*account.User)
+	if !ok || user == nil {
+		return errs.Unauthorized("Unauthorized", nil)
+	}
+
+	id := ctx.Param("id")
+	if err := c.validate.Var(id, "required"); err != nil {
+		return errs.BadRequest("bad request", err)
+	}
+
+	if err := c.oidcService.Unlink(ctx.Request().Context(), user.ID.Hex(), id); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "identity unlinked successfully", nil)
+	return nil
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// ForgotPassword godoc
+// @Summary      Forgot password
+// @Description  Send a single-use password reset link to the given email if it is registered
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  auth.ForgotPasswordRequest  true  "Account email"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/password/forgot [post]
+func (c *AuthHandler) ForgotPassword(ctx echo.Context) error {
+	var request model.ForgotPasswordRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	if err := c.passwordService.ForgotPassword(ctx.Request().Context(), request); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "if the email is registered, a reset link has been sent", nil)
+	return nil
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password using a reset token and revoke every existing session
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  auth.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200  {object}  model.WebResponse
+// @Failure      400  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/password/reset [post]
+func (c *AuthHandler) ResetPassword(ctx echo.Context) error {
+	var request model.ResetPasswordRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	if err := c.passwordService.ResetPassword(ctx.Request().Context(), request); err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusOK, "password reset successfully", nil)
+	return nil
+}
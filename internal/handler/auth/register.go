@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// Register godoc
+// @Summary      Register
+// @Description  Public self-registration, governed by REGISTRATION_MODE and REGISTRATION_ALLOWED_DOMAINS
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body  auth.RegisterRequest  true  "Account data"
+// @Success      201  {object}  model.WebResponse{data=account.UserResponse}
+// @Failure      400  {object}  model.WebResponse
+// @Failure      403  {object}  model.WebResponse
+// @Failure      500  {object}  model.WebResponse
+// @Router       /auth/register [post]
+func (c *AuthHandler) Register(ctx echo.Context) error {
+	var request model.RegisterRequest
+	if err := ctx.Bind(&request); err != nil {
+		return errs.BadRequest("invalid request format", err)
+	}
+
+	if err := c.validate.Struct(request); err != nil {
+		return errs.BadRequest("validation error", err)
+	}
+
+	user, err := c.registrationService.Register(ctx.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	helper.SendSuccess(ctx, http.StatusCreated, "registration successful", user)
+	return nil
+}
@@ -2,15 +2,46 @@ package route
 
 import (
 	handler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
 	"github.com/labstack/echo/v4"
 )
 
-func NewAuthRoute(router *echo.Group, handler *handler.AuthHandler) {
+func NewAuthRoute(router *echo.Group, handler *handler.AuthHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
 	route := router.Group("/v1/auth")
 	{
 		// route.Use(middleware.AuthMiddleware(app))
-		route.POST("/login", handler.Login)
-		route.POST("/refresh", handler.RefreshToken)
+		route.POST("/register", handler.Register, rateLimiter.Limit("register", "5-M"))
+		route.POST("/login", handler.Login, rateLimiter.Limit("login", "10-M"))
+		route.POST("/refresh", handler.RefreshToken, rateLimiter.Limit("refresh", "30-M"))
+		route.POST("/logout", handler.Logout, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+		route.POST("/logout/all", handler.LogoutAll, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+		route.POST("/password/forgot", handler.ForgotPassword, rateLimiter.Limit("password_forgot", "5-M"))
+		route.POST("/password/reset", handler.ResetPassword, rateLimiter.Limit("password_reset", "10-M"))
+		route.POST("/email/verify", handler.VerifyEmail, rateLimiter.Limit("email_verify", "10-M"))
+		route.POST("/email/resend", handler.ResendVerification, rateLimiter.Limit("email_resend", "5-M"))
+		route.POST("/mfa/verify", handler.VerifyMFA, rateLimiter.Limit("mfa_verify", "10-M"))
+	}
+
+	mfaRoutes := route.Group("/mfa")
+	{
+		mfaRoutes.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
 
+		mfaRoutes.POST("/enroll", handler.EnrollMFA)
+		mfaRoutes.POST("/activate", handler.ActivateMFA, rateLimiter.Limit("mfa_manage", "10-M"))
+		mfaRoutes.POST("/disable", handler.DisableMFA, rateLimiter.Limit("mfa_manage", "10-M"))
+		mfaRoutes.POST("/recovery-codes", handler.RegenerateRecoveryCodes, rateLimiter.Limit("mfa_manage", "10-M"))
 	}
+
+	sessionRoutes := route.Group("/sessions")
+	{
+		sessionRoutes.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+
+		sessionRoutes.GET("", handler.FindSessions)
+		sessionRoutes.DELETE("", handler.RevokeAllSessions)
+		sessionRoutes.DELETE("/:id", handler.RevokeSession)
+	}
+}
+
+func NewWellKnownRoute(router *echo.Echo, handler *handler.AuthHandler) {
+	router.GET("/.well-known/jwks.json", handler.JWKS)
 }
@@ -0,0 +1,17 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+func NewImpersonationRoute(router *echo.Group, handler *handler.ImpersonationHandler, authMiddleware *middleware.AuthMiddleware) {
+	route := router.Group("/v1/auth/impersonation")
+	{
+		// Impersonation hanya bisa dimulai dari session staff, tidak dari API key, token OAuth atau impersonation lain
+		route.POST("", handler.Start, authMiddleware.AuthRequired(), authMiddleware.SessionRequired(), authMiddleware.RequirePermission(permission.ManageSystem))
+		route.DELETE("", handler.Stop, authMiddleware.AuthRequired(), authMiddleware.ImpersonationRequired())
+	}
+}
@@ -0,0 +1,35 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func NewOAuthRoute(router *echo.Group, handler *handler.OAuthHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
+	clientRoutes := router.Group("/v1/oauth/clients")
+	{
+		clientRoutes.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+
+		clientRoutes.POST("", handler.CreateClient, authMiddleware.RequirePermission("oauth_clients:manage"))
+		clientRoutes.GET("", handler.FindAllClients, authMiddleware.RequirePermission("oauth_clients:manage"))
+		clientRoutes.DELETE("/:client_id", handler.RevokeClient, authMiddleware.RequirePermission("oauth_clients:manage"))
+	}
+
+	// Authorization dan consent hanya bisa dilakukan dari session user, bukan dengan API key atau token OAuth
+	authorizeRoutes := router.Group("/v1/oauth")
+	{
+		authorizeRoutes.GET("/authorize", handler.Authorize, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+		authorizeRoutes.POST("/authorize", handler.Decide, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+		authorizeRoutes.GET("/consents", handler.FindConsents, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+		authorizeRoutes.DELETE("/consents/:client_id", handler.RevokeConsent, authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+	}
+
+	// Endpoint untuk client, diautentikasi dengan kredensial client
+	clientAuthRoutes := router.Group("/v1/oauth")
+	{
+		clientAuthRoutes.POST("/token", handler.Token, rateLimiter.Limit("oauth_token", "60-M"))
+		clientAuthRoutes.POST("/introspect", handler.Introspect, rateLimiter.Limit("oauth_introspect", "300-M"))
+		clientAuthRoutes.POST("/revoke", handler.Revoke, rateLimiter.Limit("oauth_revoke", "60-M"))
+	}
+}
@@ -0,0 +1,24 @@
+package route
+
+import (
+	handler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
+	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+func NewOIDCRoute(router *echo.Group, handler *handler.OIDCHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
+	route := router.Group("/v1/auth/oidc")
+	{
+		route.GET("/providers", handler.Providers)
+		route.GET("/:provider/authorize", handler.Authorize, rateLimiter.Limit("oidc_authorize", "30-M"))
+		route.POST("/:provider/callback", handler.Callback, rateLimiter.Limit("oidc_callback", "10-M"))
+	}
+
+	identityRoutes := router.Group("/v1/auth/identities")
+	{
+		identityRoutes.Use(authMiddleware.AuthRequired(), authMiddleware.SessionRequired())
+
+		identityRoutes.GET("", handler.FindIdentities)
+		identityRoutes.DELETE("/:id", handler.Unlink)
+	}
+}
@@ -1,6 +1,11 @@
 package helper
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword hashes a given password using bcrypt
 func HashPassword(password []byte) (string, error) {
@@ -16,3 +21,9 @@ func VerifyPassword(hashedPassword string, plainPassword []byte) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), plainPassword)
 	return err == nil
 }
+
+// HashToken menghasilkan hash SHA-256 (hex) untuk token acak berentropi tinggi
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,8 @@
 package helper
 
 import (
+	"strings"
+
 	"github.com/HasanNugroho/golang-starter/internal/model"
 	"github.com/labstack/echo/v4"
 )
@@ -22,3 +24,21 @@ func SendError(c echo.Context, statusCode int, message string, err interface{})
 		Data:    err,
 	})
 }
+
+// ExtractAPIKey mengambil API key dari header "Authorization: ApiKey <key>"
+func ExtractAPIKey(c echo.Context) (string, bool) {
+	header := strings.TrimSpace(c.Request().Header.Get("Authorization"))
+	if len(header) > 7 && strings.EqualFold(header[:7], "ApiKey ") {
+		return strings.TrimSpace(header[7:]), true
+	}
+	return "", false
+}
+
+// ExtractToken mengambil token dari header Authorization, dengan atau tanpa prefix Bearer
+func ExtractToken(c echo.Context) string {
+	token := strings.TrimSpace(c.Request().Header.Get("Authorization"))
+	if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	return token
+}
@@ -0,0 +1,164 @@
+package helper
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Header bawaan platform, hanya dipercaya jika koneksi langsung berasal dari edge platform yang terdaftar di TRUSTED_PROXIES
+var platformHeaders = map[string]string{
+	"cf":     "CF-Connecting-IP",
+	"google": "X-Appengine-Remote-Addr",
+}
+
+// IPResolver menentukan IP client. Header X-Forwarded-For, Forwarded dan header platform
+// hanya dipercaya jika koneksi langsung berasal dari proxy yang terdaftar di TRUSTED_PROXIES.
+type IPResolver struct {
+	trusted        []netip.Prefix
+	platformHeader string
+}
+
+// NewIPResolver membuat resolver dari daftar CIDR/IP proxy dan TRUSTED_PLATFORM.
+// Platform cf dan google wajib disertai TRUSTED_PROXIES berisi range IP platform tersebut,
+// tanpa itu siapa pun yang mengakses origin langsung bisa memalsukan IP.
+func NewIPResolver(trustedProxies []string, platform string) (*IPResolver, error) {
+	resolver := &IPResolver{}
+
+	for _, value := range trustedProxies {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			resolver.trusted = append(resolver.trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		resolver.trusted = append(resolver.trusted, prefix.Masked())
+	}
+
+	platform = strings.TrimSpace(platform)
+	if header, ok := platformHeaders[strings.ToLower(platform)]; ok {
+		if len(resolver.trusted) == 0 {
+			return nil, fmt.Errorf("TRUSTED_PROXIES must list the IP ranges of %s when TRUSTED_PLATFORM=%s", header, platform)
+		}
+		resolver.platformHeader = header
+	} else {
+		resolver.platformHeader = platform
+	}
+
+	return resolver, nil
+}
+
+// ExtractIP bisa dipasang sebagai echo.IPExtractor sehingga c.RealIP() memakai aturan yang sama di seluruh aplikasi
+func (r *IPResolver) ExtractIP(req *http.Request) string {
+	host := remoteHost(req)
+	remote, ok := parseIP(host)
+	if !ok {
+		// RemoteAddr bukan IP (misalnya unix socket), dipakai apa adanya agar tetap bisa membedakan client
+		return host
+	}
+
+	if !r.isTrusted(remote) {
+		return remote.String()
+	}
+
+	if r.platformHeader != "" {
+		if ip, ok := parseIP(req.Header.Get(r.platformHeader)); ok {
+			return ip.String()
+		}
+	}
+
+	chain := forwardedFor(req)
+	if len(chain) == 0 {
+		return remote.String()
+	}
+
+	// Telusuri dari kanan: alamat pertama yang bukan proxy terpercaya adalah client
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip, ok := parseIP(chain[i])
+		if !ok {
+			// Nilai tidak valid di posisi ini ditulis oleh pihak yang tidak dipercaya
+			break
+		}
+		if !r.isTrusted(ip) || i == 0 {
+			return ip.String()
+		}
+	}
+
+	return remote.String()
+}
+
+func (r *IPResolver) isTrusted(ip netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost mengambil host dari RemoteAddr tanpa port
+func remoteHost(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// forwardedFor mengambil rantai IP dari X-Forwarded-For, atau dari parameter for= pada header Forwarded (RFC 7239)
+func forwardedFor(req *http.Request) []string {
+	var chain []string
+
+	if values := req.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				chain = append(chain, strings.TrimSpace(item))
+			}
+		}
+		return chain
+	}
+
+	for _, value := range req.Header.Values("Forwarded") {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					chain = append(chain, forwardedNode(val))
+				}
+			}
+		}
+	}
+
+	return chain
+}
+
+// forwardedNode membuang tanda kutip, kurung siku IPv6 dan port dari node Forwarded
+func forwardedNode(value string) string {
+	value = strings.Trim(strings.TrimSpace(value), `"`)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return host
+	}
+	return strings.Trim(value, "[]")
+}
+
+func parseIP(value string) (netip.Addr, bool) {
+	ip, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
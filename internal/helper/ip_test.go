@@ -0,0 +1,193 @@
+package helper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewIPResolver(t *testing.T) {
+	tests := []struct {
+		name     string
+		trusted  []string
+		platform string
+		wantErr  bool
+	}{
+		{name: "no proxies"},
+		{name: "ip and cidr", trusted: []string{"10.0.0.1", " 192.168.0.0/16 ", "", "2001:db8::/32"}},
+		{name: "custom header without proxies", platform: "X-Real-Ip"},
+		{name: "invalid ip", trusted: []string{"10.0.0.300"}, wantErr: true},
+		{name: "invalid cidr", trusted: []string{"10.0.0.0/33"}, wantErr: true},
+		{name: "cloudflare without proxies", platform: "cf", wantErr: true},
+		{name: "google without proxies", platform: "google", wantErr: true},
+		{name: "cloudflare with proxies", trusted: []string{"173.245.48.0/20"}, platform: "cf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewIPResolver(tt.trusted, tt.platform)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewIPResolver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIPResolverExtractIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    []string
+		platform   string
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{
+			name:       "direct connection",
+			remoteAddr: "203.0.113.10:1234",
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "ipv6 direct connection",
+			remoteAddr: "[2001:db8::1]:1234",
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "ipv4 mapped ipv6 is unmapped",
+			remoteAddr: "[::ffff:203.0.113.10]:1234",
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "spoofed forwarded for from an untrusted peer",
+			remoteAddr: "203.0.113.10:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "spoofed forwarded for when no proxy is trusted",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.10:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"198.51.100.2"}},
+			platform:   "X-Real-Ip",
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "client behind a trusted proxy",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "chain of trusted proxies",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, 10.0.0.5, 10.0.0.4"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "address prepended by the client is ignored",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1, 10.0.0.4"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "multiple forwarded for headers",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"1.1.1.1", "198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "garbage in the chain stops the walk",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"not-an-ip, 10.0.0.4"}},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "every hop is trusted",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3"}},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "forwarded header",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"Forwarded": {`for="[2001:db8::7]:4711";proto=https, for=10.0.0.4`}},
+			want:       "2001:db8::7",
+		},
+		{
+			name:       "trusted proxy without forwarding headers",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:1234",
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "cloudflare header from cloudflare",
+			trusted:    []string{"173.245.48.0/20"},
+			platform:   "cf",
+			remoteAddr: "173.245.48.1:1234",
+			headers:    map[string][]string{"Cf-Connecting-Ip": {"198.51.100.1"}, "X-Forwarded-For": {"1.1.1.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed cloudflare header from the internet",
+			trusted:    []string{"173.245.48.0/20"},
+			platform:   "cf",
+			remoteAddr: "203.0.113.10:1234",
+			headers:    map[string][]string{"Cf-Connecting-Ip": {"198.51.100.1"}},
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "invalid platform header falls back to forwarded for",
+			trusted:    []string{"10.0.0.0/8"},
+			platform:   "google",
+			remoteAddr: "10.0.0.2:1234",
+			headers:    map[string][]string{"X-Appengine-Remote-Addr": {"unknown"}, "X-Forwarded-For": {"198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "custom platform header",
+			trusted:    []string{"127.0.0.1"},
+			platform:   "X-Real-Ip",
+			remoteAddr: "127.0.0.1:1234",
+			headers:    map[string][]string{"X-Real-Ip": {"198.51.100.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "remote address without port",
+			remoteAddr: "203.0.113.10",
+			want:       "203.0.113.10",
+		},
+		{
+			name:       "unparsable remote address is used as is",
+			remoteAddr: "@/run/app.sock",
+			want:       "@/run/app.sock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewIPResolver(tt.trusted, tt.platform)
+			if err != nil {
+				t.Fatalf("NewIPResolver() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+
+			if got := resolver.ExtractIP(req); got != tt.want {
+				t.Errorf("ExtractIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type (
+	// KeySet menyimpan key untuk menandatangani token dan daftar key yang diterima saat verifikasi
+	KeySet struct {
+		signingKID    string
+		signingMethod jwt.SigningMethod
+		signingKey    interface{}
+		verification  map[string]verificationKey
+	}
+
+	verificationKey struct {
+		method jwt.SigningMethod
+		key    interface{}
+	}
+
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// LoadKeySet membaca konfigurasi signing JWT.
+// HS256 memakai JWT_SECRET_KEY, sedangkan RS256/ES256/EdDSA membaca private key PEM dari JWT_PRIVATE_KEY_PATH.
+// JWT_VERIFICATION_KEYS berformat "kid=path,kid=path" dan berisi public key lama yang masih diterima saat rotasi.
+func LoadKeySet(cfg configs.SecurityConfig) (*KeySet, error) {
+	keys := &KeySet{
+		signingKID:   cfg.JWTKeyID,
+		verification: make(map[string]verificationKey),
+	}
+
+	method := strings.ToUpper(cfg.JWTSigningMethod)
+	switch method {
+	case "", "HS256":
+		if cfg.JWTSecretKey == "" {
+			return nil, errors.New("JWT_SECRET_KEY is required for HS256")
+		}
+		keys.signingMethod = jwt.SigningMethodHS256
+		keys.signingKey = []byte(cfg.JWTSecretKey)
+		keys.verification[keys.signingKID] = verificationKey{method: jwt.SigningMethodHS256, key: keys.signingKey}
+
+	case "RS256", "ES256", "EDDSA":
+		if cfg.JWTKeyID == "" {
+			return nil, errors.New("JWT_KEY_ID is required for asymmetric signing")
+		}
+
+		privateKey, err := loadPrivateKey(cfg.JWTPrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey := privateKey.Public()
+		signingMethod, err := signingMethodFor(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(signingMethod.Alg(), method) {
+			return nil, fmt.Errorf("private key does not match signing method %s", cfg.JWTSigningMethod)
+		}
+
+		keys.signingMethod = signingMethod
+		keys.signingKey = privateKey
+		keys.verification[keys.signingKID] = verificationKey{method: signingMethod, key: publicKey}
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT signing method: %s", cfg.JWTSigningMethod)
+	}
+
+	for _, entry := range strings.Split(cfg.JWTVerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT_VERIFICATION_KEYS entry: %s", entry)
+		}
+
+		publicKey, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		signingMethod, err := signingMethodFor(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keys.verification[kid] = verificationKey{method: signingMethod, key: publicKey}
+	}
+
+	return keys, nil
+}
+
+// Sign menandatangani claims dengan key aktif
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+	if k.signingKID != "" {
+		token.Header["kid"] = k.signingKID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// KeyFunc memilih key verifikasi berdasarkan header kid dan memastikan algoritmanya sesuai
+func (k *KeySet) KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := k.verification[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return key.key, nil
+}
+
+// JWKS mengembalikan public key yang dipakai untuk verifikasi token dalam format JSON Web Key Set
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	for kid, key := range k.verification {
+		jwk := JWK{Use: "sig", Alg: key.method.Alg(), Kid: kid}
+
+		switch publicKey := key.key.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = publicKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			// secret HMAC tidak boleh dipublikasikan
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+
+	return jwks
+}
+
+// PublicKey mengubah JWK menjadi public key, dipakai untuk memverifikasi token dari penerbit lain
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on curve")
+		}
+		return key, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+}
+
+func signingMethodFor(publicKey crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, errors.New("only P-256 curve is supported for ES256")
+		}
+		return jwt.SigningMethodES256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
+
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key in %s", path)
+	}
+	return signer, nil
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,15 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateRandomString menghasilkan string hex acak dari n byte
+func GenerateRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
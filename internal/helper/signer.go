@@ -0,0 +1,42 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignPayload menghasilkan token "<payload>.<signature>" (base64url) yang ditandatangani dengan HMAC-SHA256
+func SignPayload(secret []byte, payload string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + sign(secret, encoded)
+}
+
+// VerifySignedPayload memeriksa tanda tangan token dari SignPayload dan mengembalikan payload aslinya
+func VerifySignedPayload(secret []byte, token string) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, encoded))) {
+		return "", ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	return string(payload), nil
+}
+
+func sign(secret []byte, data string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
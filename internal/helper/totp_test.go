@@ -0,0 +1,126 @@
+package helper
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret adalah secret SHA1 dari lampiran B RFC 6238 ("12345678901234567890") dalam base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPRFC6238Vectors(t *testing.T) {
+	// Kode di RFC 6238 terdiri dari 8 digit, kode 6 digit adalah 6 digit terakhirnya
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0), 0)
+			if !ok {
+				t.Fatalf("ValidateTOTP() ok = false, want true")
+			}
+			if want := tt.unix / totpPeriod; step != want {
+				t.Errorf("ValidateTOTP() step = %d, want %d", step, want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// 050471 adalah kode untuk step 37037037 (detik 1111111110-1111111139)
+	const code = "050471"
+	const step int64 = 37037037
+	const now int64 = 1111111111
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		at       int64
+		lastStep int64
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current step", secret: rfc6238Secret, code: code, at: now, wantStep: step, wantOk: true},
+		{name: "one step early", secret: rfc6238Secret, code: code, at: now - 30, wantStep: step, wantOk: true},
+		{name: "one step late", secret: rfc6238Secret, code: code, at: now + 30, wantStep: step, wantOk: true},
+		{name: "two steps early", secret: rfc6238Secret, code: code, at: now - 60, wantOk: false},
+		{name: "two steps late", secret: rfc6238Secret, code: code, at: now + 60, wantOk: false},
+		{name: "lowercase secret", secret: strings.ToLower(rfc6238Secret), code: code, at: now, wantStep: step, wantOk: true},
+		{name: "surrounding spaces", secret: rfc6238Secret, code: " " + code + " ", at: now, wantStep: step, wantOk: true},
+		{name: "wrong code", secret: rfc6238Secret, code: "050472", at: now, wantOk: false},
+		{name: "too short", secret: rfc6238Secret, code: "05047", at: now, wantOk: false},
+		{name: "eight digit code", secret: rfc6238Secret, code: "14050471", at: now, wantOk: false},
+		{name: "invalid secret", secret: "not base32!", code: code, at: now, wantOk: false},
+		// Kode yang sudah dipakai tidak bisa diulang selama masih di jendela toleransi
+		{name: "replayed step", secret: rfc6238Secret, code: code, at: now, lastStep: step, wantOk: false},
+		{name: "older than the last step", secret: rfc6238Secret, code: code, at: now + 30, lastStep: step + 1, wantOk: false},
+		{name: "newer than the last step", secret: rfc6238Secret, code: code, at: now, lastStep: step - 1, wantStep: step, wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOk := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.at, 0), tt.lastStep)
+			if gotStep != tt.wantStep || gotOk != tt.wantOk {
+				t.Errorf("ValidateTOTP() = %d, %v, want %d, %v", gotStep, gotOk, tt.wantStep, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() = %q is not base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("GenerateTOTPSecret() key length = %d, want 20", len(key))
+	}
+
+	now := time.Now()
+	if _, ok := ValidateTOTP(secret, hotp(key, uint64(now.Unix()/totpPeriod)), now, 0); !ok {
+		t.Errorf("ValidateTOTP() rejected a code for a generated secret")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Golang Starter", "jane@example.com", rfc6238Secret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("TOTPURI() = %q is not a valid url: %v", uri, err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("TOTPURI() = %q, want otpauth://totp/", uri)
+	}
+	if parsed.Path != "/Golang Starter:jane@example.com" {
+		t.Errorf("TOTPURI() label = %q", parsed.Path)
+	}
+	if strings.Contains(uri, "+") {
+		t.Errorf("TOTPURI() = %q encodes spaces as +", uri)
+	}
+
+	query := parsed.Query()
+	want := map[string]string{"secret": rfc6238Secret, "issuer": "Golang Starter", "algorithm": "SHA1", "digits": "6", "period": "30"}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("TOTPURI() %s = %q, want %q", key, got, value)
+		}
+	}
+}
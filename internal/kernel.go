@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,8 +13,8 @@ import (
 	accountRoute "github.com/HasanNugroho/golang-starter/internal/handler/account/route"
 	authHandler "github.com/HasanNugroho/golang-starter/internal/handler/auth"
 	authRoute "github.com/HasanNugroho/golang-starter/internal/handler/auth/route"
-	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"github.com/HasanNugroho/golang-starter/internal/middleware"
+	accountRepository "github.com/HasanNugroho/golang-starter/internal/repository/account"
 	"github.com/labstack/echo/v4"
 )
 
@@ -34,30 +35,62 @@ func Init(config *configs.Config, router *echo.Echo) {
 		panic(1)
 	}
 
-	helper.SetJWTHelper(config.Security.JWTSecretKey, time.Duration(config.Security.JWTExpired)*time.Minute, time.Duration(config.Security.JWTRefreshTokenExpired)*time.Hour, redisClient)
-
-	container, err := app.BuildContainer(config, mongoDB, logger)
+	container, err := app.BuildContainer(config, mongoDB, redisClient, logger)
 	if err != nil {
 		logger.Fatal().Msg(err.Error())
 		panic(1)
 	}
 
+	// Pastikan key JWT, katalog permission dan password policy valid sebelum route didaftarkan
+	for _, name := range []string{"jwtKeys", "permissionCatalog", "passwordPolicy"} {
+		if _, err := container.SafeGet(name); err != nil {
+			logger.Fatal().Msg(err.Error())
+			panic(1)
+		}
+	}
+
+	// Unique index dipakai untuk mencegah data ganda dari request yang berjalan bersamaan, sehingga wajib ada sebelum menerima request
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Database.Timeout)*time.Second)
+	for _, name := range []string{"userRepository", "apiKeyRepository", "invitationRepository", "identityRepository"} {
+		if err := container.Get(name).(accountRepository.IIndexedRepository).EnsureIndexes(ctx); err != nil {
+			cancel()
+			logger.Fatal().Err(err).Str("repository", name).Msg("failed to create indexes")
+			panic(1)
+		}
+	}
+	cancel()
+
 	apiGroup := router.Group("/api")
 	authMiddleware := container.Get("authMiddleware").(*middleware.AuthMiddleware)
+	rateLimiter := container.Get("rateLimiter").(*middleware.RateLimiter)
+	apiGroup.Use(rateLimiter.Global())
 
 	roleHandler := container.Get("roleHandler").(*accountHandler.RoleHandler)
+	permissionHandler := container.Get("permissionHandler").(*accountHandler.PermissionHandler)
 	userHandler := container.Get("userHandler").(*accountHandler.UserHandler)
+	apiKeyHandler := container.Get("apiKeyHandler").(*accountHandler.APIKeyHandler)
+	invitationHandler := container.Get("invitationHandler").(*accountHandler.InvitationHandler)
+	oidcHandler := container.Get("oidcHandler").(*authHandler.OIDCHandler)
+	oauthHandler := container.Get("oauthHandler").(*authHandler.OAuthHandler)
+	impersonationHandler := container.Get("impersonationHandler").(*authHandler.ImpersonationHandler)
 	authHandler := container.Get("authHandler").(*authHandler.AuthHandler)
 
 	// Daftarkan route
 	accountRoute.NewRoleRoute(apiGroup, roleHandler, authMiddleware)
+	accountRoute.NewPermissionRoute(apiGroup, permissionHandler, authMiddleware)
 	accountRoute.NewUserRoute(apiGroup, userHandler, authMiddleware)
-	authRoute.NewAuthRoute(apiGroup, authHandler)
+	accountRoute.NewAPIKeyRoute(apiGroup, apiKeyHandler, authMiddleware)
+	accountRoute.NewInvitationRoute(apiGroup, invitationHandler, authMiddleware, rateLimiter)
+	authRoute.NewAuthRoute(apiGroup, authHandler, authMiddleware, rateLimiter)
+	authRoute.NewImpersonationRoute(apiGroup, impersonationHandler, authMiddleware)
+	authRoute.NewOIDCRoute(apiGroup, oidcHandler, authMiddleware, rateLimiter)
+	authRoute.NewOAuthRoute(apiGroup, oauthHandler, authMiddleware, rateLimiter)
+	authRoute.NewWellKnownRoute(router, authHandler)
 
 	// Siapkan fungsi shutdown untuk melakukan cleanup (misal: shutdown Redis dan container)
 	shutdownFunc := func() {
-		configs.ShutdownRedis(redisClient)
 		container.Delete()
+		configs.ShutdownRedis(redisClient)
 	}
 
 	// Jalankan goroutine untuk menangani sinyal terminasi
@@ -1,67 +1,99 @@
 package middleware
 
 import (
-	"fmt"
+	"errors"
+	"net/http"
+	"strings"
 
 	"github.com/HasanNugroho/golang-starter/internal/errs"
 	"github.com/HasanNugroho/golang-starter/internal/helper"
-	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/HasanNugroho/golang-starter/internal/service/auth"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog"
 )
 
 type AuthMiddleware struct {
-	userService account.IUserService
-	logger      *zerolog.Logger
+	userService    accountservice.IUserService
+	sessionService auth.ISessionService
+	tokenService   auth.ITokenService
+	permissions    accountservice.IPermissionService
+	apiKeyService  accountservice.IAPIKeyService
+	oauthService   auth.IOAuthService
+	rateLimiter    *RateLimiter
+	logger         *zerolog.Logger
 }
 
-func NewAuthMiddleware(logger *zerolog.Logger, userService account.IUserService) *AuthMiddleware {
-	return &AuthMiddleware{userService: userService, logger: logger}
+func NewAuthMiddleware(logger *zerolog.Logger, userService accountservice.IUserService, sessionService auth.ISessionService, tokenService auth.ITokenService, permissions accountservice.IPermissionService, apiKeyService accountservice.IAPIKeyService, oauthService auth.IOAuthService, rateLimiter *RateLimiter) *AuthMiddleware {
+	return &AuthMiddleware{userService: userService, sessionService: sessionService, tokenService: tokenService, permissions: permissions, apiKeyService: apiKeyService, oauthService: oauthService, rateLimiter: rateLimiter, logger: logger}
 }
 
 func (m *AuthMiddleware) AuthRequired() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		// Limit per user/API key baru bisa dihitung setelah identitas request diketahui
+		next = m.rateLimiter.Authenticated()(next)
+
 		return func(c echo.Context) error {
-			tokenString := c.Request().Header.Get("Authorization")
+			if rawKey, ok := helper.ExtractAPIKey(c); ok {
+				return m.authenticateAPIKey(c, next, rawKey)
+			}
+
+			tokenString := helper.ExtractToken(c)
 			if tokenString == "" {
 				m.logger.Error().Msg("missing authorization header")
 				return errs.Unauthorized("Unauthorized", nil)
 			}
 
-			claims, err := helper.ParseToken(tokenString)
-			fmt.Println(err)
+			if strings.HasPrefix(tokenString, model.OAuthAccessTokenScheme+"_") {
+				return m.authenticateOAuthToken(c, next, tokenString)
+			}
 
+			claims, err := m.tokenService.ParseAccessToken(c.Request().Context(), tokenString)
 			if err != nil {
 				m.logger.Error().Err(err).Msg("invalid or expired token")
 				return errs.Unauthorized("Unauthorized", err)
 			}
 
-			data, ok := claims["data"].(map[string]interface{})
-			if !ok {
-				m.logger.Error().Msg("invalid token payload")
-				return errs.Unauthorized("Unauthorized", nil)
-			}
-
-			userID, ok := data["user_id"].(string)
-			if !ok {
-				m.logger.Error().Msg("invalid token payload")
-				return errs.Unauthorized("Unauthorized", nil)
-			}
+			userID := claims.UserID()
+			sessionID := claims.SessionID
 
 			// Ambil informasi IP dan Device (User-Agent) dari request
-			ipAddress := c.Request().RemoteAddr
-			if forwardedIP := c.Request().Header.Get("X-Forwarded-For"); forwardedIP != "" {
-				ipAddress = forwardedIP
+			ipAddress := c.RealIP()
+			device := c.Request().Header.Get("User-Agent")
+
+			// Token impersonation terikat ke session staff (claim act), bukan session user yang ditiru
+			sessionOwnerID := userID
+			if claims.IsImpersonation() {
+				sessionOwnerID = claims.Actor.Subject
 			}
 
-			device := c.Request().Header.Get("User-Agent")
+			client := model.ClientInfo{IPAddress: ipAddress, Device: device}
+			if _, err := m.sessionService.Validate(c.Request().Context(), sessionOwnerID, sessionID, client); err != nil {
+				m.logger.Error().Err(err).Str("user_id", sessionOwnerID).Str("session_id", sessionID).Str("ip_address", ipAddress).Msg("session expired or revoked")
+				return errs.Unauthorized("Unauthorized", err)
+			}
 
-			user, err := m.userService.FindById(c.Request().Context(), userID)
+			// Detail role tidak diambil, permission efektif dibaca dari cache
+			user, err := m.userService.FindByIdWithoutRoles(c.Request().Context(), userID)
 			if err != nil {
 				m.logger.Error().Err(err).Str("user_id", userID).Str("ip_address", ipAddress).Str("device", device).Msg("user not found")
 				return errs.Unauthorized("Unauthorized", err)
 			}
 
+			// Access token yang terbit sebelum akun dinonaktifkan tidak boleh tetap berlaku sampai kedaluwarsa.
+			// Lock karena login gagal tidak dicek di sini, lock bisa dipicu siapa pun yang tahu email user.
+			if user.Disabled {
+				return errs.Forbidden("account is disabled", nil)
+			}
+
+			permissions, err := m.permissions.Resolve(c.Request().Context(), user)
+			if err != nil {
+				return err
+			}
+
 			// m.logger.Info().
 			// 	Str("user_id", userResponse.ID).
 			// 	Str("ip_address", ipAddress).
@@ -69,8 +101,238 @@ func (m *AuthMiddleware) AuthRequired() echo.MiddlewareFunc {
 			// 	Msg("User access successfully")
 
 			c.Set("user", user)
+			c.Set("real_user", user)
+			c.Set("session_id", sessionID)
+			c.Set("permissions", permissions)
+
+			if claims.IsImpersonation() {
+				actor, err := m.authenticateActor(c, claims.Actor.Subject)
+				if err != nil {
+					return err
+				}
+				c.Set("real_user", actor)
+				return m.auditImpersonation(c, next, actor, user)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// authenticateActor memastikan staff yang melakukan impersonation masih ada dan masih memiliki
+// manage:system, sehingga impersonation langsung berhenti ketika hak admin dicabut
+func (m *AuthMiddleware) authenticateActor(c echo.Context, actorID string) (*account.User, error) {
+	ctx := c.Request().Context()
+
+	actor, err := m.userService.FindByIdWithoutRoles(ctx, actorID)
+	if err != nil {
+		m.logger.Error().Err(err).Str("actor_id", actorID).Msg("impersonation actor not found")
+		return nil, errs.Unauthorized("Unauthorized", err)
+	}
+
+	permissions, err := m.permissions.Resolve(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if actor.Disabled || !permissions.Has(permission.ManageSystem) {
+		m.logger.Warn().Str("event", "security.impersonation_rejected").Str("actor_id", actorID).Msg("actor is no longer allowed to impersonate")
+		return nil, errs.Unauthorized("Unauthorized", nil)
+	}
+
+	return actor, nil
+}
+
+// auditImpersonation mencatat setiap request yang mengubah data selama impersonation
+func (m *AuthMiddleware) auditImpersonation(c echo.Context, next echo.HandlerFunc, actor *account.User, user *account.User) error {
+	err := next(c)
+
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return err
+	}
+
+	status := c.Response().Status
+	var customErr *errs.CustomError
+	if errors.As(err, &customErr) {
+		status = customErr.StatusCode()
+	} else if err != nil {
+		status = http.StatusInternalServerError
+	}
+
+	m.logger.Info().
+		Str("event", "audit.impersonation_request").
+		Str("actor_id", actor.ID.Hex()).
+		Str("user_id", user.ID.Hex()).
+		Str("method", c.Request().Method).
+		Str("path", c.Path()).
+		Str("uri", c.Request().RequestURI).
+		Int("status", status).
+		Str("ip_address", c.RealIP()).
+		Msg("mutating request under impersonation")
+
+	return err
+}
+
+// authenticateAPIKey mengautentikasi request dengan "Authorization: ApiKey <key>".
+// Permission request adalah irisan permission owner dan permission key.
+func (m *AuthMiddleware) authenticateAPIKey(c echo.Context, next echo.HandlerFunc, rawKey string) error {
+	ctx := c.Request().Context()
+	ipAddress := c.RealIP()
+
+	key, err := m.apiKeyService.Authenticate(ctx, rawKey, ipAddress)
+	if err != nil {
+		m.logger.Warn().Err(err).Str("event", "security.api_key_rejected").Str("ip_address", ipAddress).Msg("invalid api key")
+		return errs.Unauthorized("Unauthorized", err)
+	}
+
+	user, err := m.userService.FindByIdWithoutRoles(ctx, key.OwnerID.Hex())
+	if err != nil {
+		m.logger.Error().Err(err).Str("api_key_id", key.ID.Hex()).Msg("api key owner not found")
+		return errs.Unauthorized("Unauthorized", err)
+	}
+
+	if user.Disabled {
+		return errs.Forbidden("account is disabled", nil)
+	}
+
+	ownerPermissions, err := m.permissions.Resolve(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	c.Set("user", user)
+	c.Set("real_user", user)
+	c.Set("api_key_id", key.ID.Hex())
+	c.Set("permissions", m.apiKeyService.Permissions(key, ownerPermissions))
+
+	return next(c)
+}
+
+// authenticateOAuthToken mengautentikasi access token OAuth2 yang diterbitkan untuk client pihak ketiga.
+// Permission request adalah irisan permission user dan scope yang disetujui.
+func (m *AuthMiddleware) authenticateOAuthToken(c echo.Context, next echo.HandlerFunc, rawToken string) error {
+	ctx := c.Request().Context()
+
+	token, err := m.oauthService.AuthenticateAccessToken(ctx, rawToken)
+	if err != nil {
+		m.logger.Warn().Err(err).Str("event", "security.oauth_token_rejected").Str("ip_address", c.RealIP()).Msg("invalid oauth access token")
+		return errs.Unauthorized("Unauthorized", err)
+	}
+
+	user, err := m.userService.FindByIdWithoutRoles(ctx, token.UserID)
+	if err != nil {
+		m.logger.Error().Err(err).Str("client_id", token.ClientID).Msg("oauth token owner not found")
+		return errs.Unauthorized("Unauthorized", err)
+	}
+
+	if user.Disabled {
+		return errs.Forbidden("account is disabled", nil)
+	}
+
+	ownerPermissions, err := m.permissions.Resolve(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	c.Set("user", user)
+	c.Set("real_user", user)
+	c.Set("oauth_client_id", token.ClientID)
+	c.Set("permissions", m.oauthService.Permissions(token, ownerPermissions))
+
+	return next(c)
+}
+
+// SessionRequired menolak request yang diautentikasi dengan API key atau token OAuth2, dipakai untuk endpoint
+// yang mengelola kredensial (session, 2FA, API key) agar key yang bocor tidak bisa memperluas akses.
+// Request selama impersonation juga ditolak karena staff tidak boleh mengubah kredensial user.
+func (m *AuthMiddleware) SessionRequired() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get("session_id").(string); !ok {
+				return errs.Forbidden("this endpoint requires a user session", nil)
+			}
+
+			if IsImpersonating(c) {
+				return errs.Forbidden("this endpoint is not available while impersonating", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ImpersonationRequired hanya mengizinkan request dengan token impersonation
+func (m *AuthMiddleware) ImpersonationRequired() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !IsImpersonating(c) {
+				return errs.BadRequest("no active impersonation", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequirePermission mengizinkan request hanya jika user memiliki permission tersebut
+func (m *AuthMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return m.RequireAll(permission)
+}
+
+// RequireAny mengizinkan request jika user memiliki minimal satu dari permission yang diberikan
+func (m *AuthMiddleware) RequireAny(permissions ...string) echo.MiddlewareFunc {
+	return m.requirePermissions(permissions, func(set permission.Set) bool {
+		return set.HasAny(permissions...)
+	})
+}
+
+// RequireAll mengizinkan request hanya jika user memiliki seluruh permission yang diberikan
+func (m *AuthMiddleware) RequireAll(permissions ...string) echo.MiddlewareFunc {
+	return m.requirePermissions(permissions, func(set permission.Set) bool {
+		return set.HasAll(permissions...)
+	})
+}
+
+// requirePermissions harus dipasang setelah AuthRequired yang mengisi "permissions" di context
+func (m *AuthMiddleware) requirePermissions(permissions []string, allowed func(permission.Set) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			set, ok := c.Get("permissions").(permission.Set)
+			if !ok || !allowed(set) {
+				user, _ := c.Get("user").(*account.User)
+				event := m.logger.Warn().Str("event", "security.permission_denied").Strs("required", permissions).Str("path", c.Path())
+				if user != nil {
+					event = event.Str("user_id", user.ID.Hex())
+				}
+				event.Msg("permission denied")
+				return errs.Forbidden("Forbidden", nil)
+			}
 
 			return next(c)
 		}
 	}
 }
+
+// EffectiveUser mengambil user yang dipakai untuk otorisasi, yaitu user yang ditiru selama impersonation
+func EffectiveUser(c echo.Context) *account.User {
+	user, _ := c.Get("user").(*account.User)
+	return user
+}
+
+// RealUser mengambil user yang sebenarnya melakukan request, yaitu staff selama impersonation
+func RealUser(c echo.Context) *account.User {
+	user, _ := c.Get("real_user").(*account.User)
+	return user
+}
+
+// IsImpersonating bernilai true jika request dilakukan staff atas nama user lain
+func IsImpersonating(c echo.Context) bool {
+	user, real := EffectiveUser(c), RealUser(c)
+	return user != nil && real != nil && user.ID != real.ID
+}
+
+// Permissions mengambil permission efektif user yang sudah diisi oleh AuthRequired
+func Permissions(c echo.Context) permission.Set {
+	set, _ := c.Get("permissions").(permission.Set)
+	return set
+}
@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	model "github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/HasanNugroho/golang-starter/internal/service/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeUserService hanya mengimplementasikan FindByIdWithoutRoles, method lain panic karena interface yang di-embed bernilai nil
+type fakeUserService struct {
+	accountservice.IUserService
+	users map[string]*account.User
+}
+
+func (f *fakeUserService) FindByIdWithoutRoles(ctx context.Context, id string) (*account.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return &account.User{}, errs.NotFound("user not found", nil)
+	}
+	return user, nil
+}
+
+// fakeSessionService menganggap session valid jika terdaftar untuk user tersebut
+type fakeSessionService struct {
+	auth.ISessionService
+	sessions map[string]string
+}
+
+func (f *fakeSessionService) Validate(ctx context.Context, userID string, sessionID string, client model.ClientInfo) (*model.Session, error) {
+	if f.sessions[sessionID] != userID {
+		return nil, errs.NotFound("session not found", nil)
+	}
+	return &model.Session{ID: sessionID, UserID: userID}, nil
+}
+
+type fakePermissionService struct {
+	grants map[bson.ObjectID][]string
+}
+
+func (f *fakePermissionService) Resolve(ctx context.Context, user *account.User) (permission.Set, error) {
+	set := make(permission.Set)
+	for _, grant := range f.grants[user.ID] {
+		set[grant] = struct{}{}
+	}
+	return set, nil
+}
+
+func (f *fakePermissionService) InvalidateUser(ctx context.Context, userID string) {}
+
+func (f *fakePermissionService) InvalidateAll(ctx context.Context) {}
+
+type impersonationFixture struct {
+	middleware  *AuthMiddleware
+	tokens      *auth.TokenService
+	permissions *fakePermissionService
+	actor       *account.User
+	user        *account.User
+}
+
+func newImpersonationFixture(t *testing.T) *impersonationFixture {
+	t.Helper()
+
+	config := &configs.Config{
+		Security: configs.SecurityConfig{
+			JWTSecretKey:         "test-secret",
+			JWTExpired:           15,
+			ImpersonationExpired: 15,
+			JWTIssuer:            "test",
+			JWTAudience:          "test",
+		},
+	}
+	keys, err := helper.LoadKeySet(config.Security)
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+
+	logger := zerolog.Nop()
+	limiter, err := NewRateLimiter(nil, &logger, &configs.Config{})
+	if err != nil {
+		t.Fatalf("NewRateLimiter() error = %v", err)
+	}
+
+	actor := &account.User{ID: bson.NewObjectID(), Email: "admin@example.com"}
+	user := &account.User{ID: bson.NewObjectID(), Email: "jane@example.com"}
+
+	users := &fakeUserService{users: map[string]*account.User{actor.ID.Hex(): actor, user.ID.Hex(): user}}
+	sessions := &fakeSessionService{sessions: map[string]string{"actor-session": actor.ID.Hex(), "user-session": user.ID.Hex()}}
+	permissions := &fakePermissionService{grants: map[bson.ObjectID][]string{
+		actor.ID: {permission.ManageSystem, "users:read"},
+		user.ID:  {"users:read"},
+	}}
+	tokens := auth.NewTokenService(keys, repository.NewMemoryTokenRepository(), &logger, config)
+
+	return &impersonationFixture{
+		middleware:  NewAuthMiddleware(&logger, users, sessions, tokens, permissions, nil, nil, limiter),
+		tokens:      tokens,
+		permissions: permissions,
+		actor:       actor,
+		user:        user,
+	}
+}
+
+func (f *impersonationFixture) impersonationToken(t *testing.T) string {
+	t.Helper()
+
+	token, _, err := f.tokens.GenerateImpersonationToken(f.user.ID.Hex(), f.actor.ID.Hex(), "actor-session")
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken() error = %v", err)
+	}
+	return token
+}
+
+func (f *impersonationFixture) accessToken(t *testing.T, user *account.User, sessionID string) string {
+	t.Helper()
+
+	token, err := f.tokens.GenerateAccessToken(user.ID.Hex(), sessionID)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	return token
+}
+
+// serve menjalankan handler di belakang AuthRequired dan middleware tambahan, lalu mengembalikan context request
+func (f *impersonationFixture) serve(token string, middlewares ...echo.MiddlewareFunc) (echo.Context, bool, error) {
+	called := false
+	handler := echo.HandlerFunc(func(c echo.Context) error {
+		called = true
+		return nil
+	})
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	handler = f.middleware.AuthRequired()(handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	err := handler(c)
+	return c, called, err
+}
+
+func errorStatus(err error) int {
+	var customErr *errs.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.StatusCode()
+	}
+	return 0
+}
+
+func TestAuthRequiredImpersonationToken(t *testing.T) {
+	f := newImpersonationFixture(t)
+
+	c, called, err := f.serve(f.impersonationToken(t))
+	if err != nil || !called {
+		t.Fatalf("AuthRequired() error = %v, called = %v", err, called)
+	}
+
+	if got := EffectiveUser(c); got == nil || got.ID != f.user.ID {
+		t.Errorf("EffectiveUser() = %v, want %s", got, f.user.ID.Hex())
+	}
+	if got := RealUser(c); got == nil || got.ID != f.actor.ID {
+		t.Errorf("RealUser() = %v, want %s", got, f.actor.ID.Hex())
+	}
+	if !IsImpersonating(c) {
+		t.Errorf("IsImpersonating() = false, want true")
+	}
+	// Otorisasi memakai permission user yang ditiru, bukan permission staff
+	if Permissions(c).Has(permission.ManageSystem) {
+		t.Errorf("Permissions() = %v, want the impersonated user's permissions", Permissions(c).List())
+	}
+}
+
+func TestImpersonationRouteGuards(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       func(f *impersonationFixture, t *testing.T) string
+		middlewares func(f *impersonationFixture) []echo.MiddlewareFunc
+		wantStatus  int
+	}{
+		{
+			name:  "impersonation token on an impersonation route",
+			token: (*impersonationFixture).impersonationToken,
+			middlewares: func(f *impersonationFixture) []echo.MiddlewareFunc {
+				return []echo.MiddlewareFunc{f.middleware.ImpersonationRequired()}
+			},
+		},
+		{
+			name: "regular token on an impersonation route",
+			token: func(f *impersonationFixture, t *testing.T) string {
+				return f.accessToken(t, f.actor, "actor-session")
+			},
+			middlewares: func(f *impersonationFixture) []echo.MiddlewareFunc {
+				return []echo.MiddlewareFunc{f.middleware.ImpersonationRequired()}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			// Staff tidak boleh mengubah session, 2FA atau API key user yang ditiru
+			name:  "impersonation token on a credential route",
+			token: (*impersonationFixture).impersonationToken,
+			middlewares: func(f *impersonationFixture) []echo.MiddlewareFunc {
+				return []echo.MiddlewareFunc{f.middleware.SessionRequired()}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			// Impersonation bertingkat ditolak karena permission yang dipakai adalah milik user yang ditiru
+			name:  "impersonation token starting another impersonation",
+			token: (*impersonationFixture).impersonationToken,
+			middlewares: func(f *impersonationFixture) []echo.MiddlewareFunc {
+				return []echo.MiddlewareFunc{f.middleware.SessionRequired(), f.middleware.RequirePermission(permission.ManageSystem)}
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "impersonation token after the actor lost manage:system",
+			token: func(f *impersonationFixture, t *testing.T) string {
+				f.permissions.grants[f.actor.ID] = []string{"users:read"}
+				return f.impersonationToken(t)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "impersonation token after the actor was disabled",
+			token: func(f *impersonationFixture, t *testing.T) string {
+				f.actor.Disabled = true
+				return f.impersonationToken(t)
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "impersonation token after the actor's session was revoked",
+			token: func(f *impersonationFixture, t *testing.T) string {
+				token := f.impersonationToken(t)
+				delete(f.middleware.sessionService.(*fakeSessionService).sessions, "actor-session")
+				return token
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "stopped impersonation token",
+			token: func(f *impersonationFixture, t *testing.T) string {
+				token := f.impersonationToken(t)
+				if err := f.tokens.Revoke(context.Background(), model.TokenTypeAccess, token); err != nil {
+					t.Fatalf("Revoke() error = %v", err)
+				}
+				return token
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newImpersonationFixture(t)
+
+			var middlewares []echo.MiddlewareFunc
+			if tt.middlewares != nil {
+				middlewares = tt.middlewares(f)
+			}
+
+			_, called, err := f.serve(tt.token(f, t), middlewares...)
+			if got := errorStatus(err); got != tt.wantStatus {
+				t.Fatalf("request error = %v, want status %d", err, tt.wantStatus)
+			}
+			if called != (tt.wantStatus == 0) {
+				t.Errorf("handler called = %v, want %v", called, tt.wantStatus == 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const rateLimitPrefix = "ratelimit:"
+
+// slidingWindowScript mencatat request dalam sorted set (score = waktu dalam milidetik) secara atomik,
+// sehingga limit tetap konsisten walaupun aplikasi berjalan di beberapa instance.
+// Mengembalikan {allowed, jumlah request di window, score request tertua}.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {allowed, count, oldest[2] or ARGV[1]}
+`)
+
+// Rate adalah jumlah request yang diizinkan dalam satu periode
+type Rate struct {
+	Limit  int64
+	Period time.Duration
+}
+
+// ParseRate membaca format <requests>-<unit> (S, M, H, D), misalnya 100-M.
+// Angka tanpa unit dianggap per menit.
+func ParseRate(value string) (Rate, error) {
+	value = strings.TrimSpace(value)
+	count, unit, found := strings.Cut(value, "-")
+	if !found {
+		unit = "M"
+	}
+
+	limit, err := strconv.ParseInt(strings.TrimSpace(count), 10, 64)
+	if err != nil || limit <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q", value)
+	}
+
+	periods := map[string]time.Duration{
+		"S": time.Second,
+		"M": time.Minute,
+		"H": time.Hour,
+		"D": 24 * time.Hour,
+	}
+	period, ok := periods[strings.ToUpper(strings.TrimSpace(unit))]
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid rate unit %q", unit)
+	}
+
+	return Rate{Limit: limit, Period: period}, nil
+}
+
+type RateLimiter struct {
+	redis         *redis.Client
+	logger        *zerolog.Logger
+	rate          *Rate
+	authenticated *Rate
+	overrides     map[string]Rate
+}
+
+// NewRateLimiter membuat rate limiter dari RATE_LIMIT (limit per IP untuk request anonim, kosong berarti nonaktif),
+// RATE_LIMIT_AUTHENTICATED (limit per user/API key) dan RATE_LIMIT_OVERRIDES (<nama>=<rate>,...)
+// yang menimpa limit bawaan per route
+func NewRateLimiter(redisClient *redis.Client, logger *zerolog.Logger, config *configs.Config) (*RateLimiter, error) {
+	limiter := &RateLimiter{
+		redis:     redisClient,
+		logger:    logger,
+		overrides: make(map[string]Rate),
+	}
+
+	if strings.TrimSpace(config.Security.RateLimit) != "" {
+		rate, err := ParseRate(config.Security.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT: %w", err)
+		}
+		limiter.rate = &rate
+	}
+
+	if strings.TrimSpace(config.Security.RateLimitAuthenticated) != "" {
+		rate, err := ParseRate(config.Security.RateLimitAuthenticated)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_AUTHENTICATED: %w", err)
+		}
+		limiter.authenticated = &rate
+	}
+
+	for _, item := range config.Security.RateLimitOverrides {
+		name, value, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("RATE_LIMIT_OVERRIDES: invalid entry %q", item)
+		}
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_OVERRIDES: %w", err)
+		}
+		limiter.overrides[strings.TrimSpace(name)] = rate
+	}
+
+	return limiter, nil
+}
+
+// Global menerapkan RATE_LIMIT per IP ke seluruh route di group. Middleware ini berjalan sebelum autentikasi,
+// sehingga setiap request dihitung ke IP secara atomik sebelum handler dijalankan, lalu hitungan tersebut
+// dikembalikan jika request ternyata berhasil diautentikasi. Request yang berhasil diautentikasi
+// dibatasi per user/API key oleh Authenticated.
+func (r *RateLimiter) Global() echo.MiddlewareFunc {
+	if r.rate == nil {
+		return noopMiddleware
+	}
+
+	rate := *r.rate
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := rateLimitPrefix + "global:ip:" + c.RealIP()
+
+			result, err := r.hit(c, key, rate)
+			if err != nil {
+				r.logger.Error().Err(err).Str("limiter", "global").Msg("rate limiter unavailable")
+				return next(c)
+			}
+
+			r.setHeaders(c, rate, result)
+			if !result.allowed {
+				return r.reject(c, "global", key, rate, result)
+			}
+
+			handlerErr := next(c)
+
+			if isAuthenticated(c) {
+				r.release(c, key, result.member)
+			}
+			return handlerErr
+		}
+	}
+}
+
+// Authenticated menerapkan RATE_LIMIT_AUTHENTICATED per API key atau user, dipasang oleh AuthRequired
+// setelah identitas request diketahui
+func (r *RateLimiter) Authenticated() echo.MiddlewareFunc {
+	if r.authenticated == nil {
+		return noopMiddleware
+	}
+	return r.middleware("authenticated", *r.authenticated)
+}
+
+// Limit menerapkan limit khusus untuk satu route. Nilai bawaan bisa ditimpa lewat RATE_LIMIT_OVERRIDES dengan nama yang sama.
+func (r *RateLimiter) Limit(name string, value string) echo.MiddlewareFunc {
+	rate, ok := r.overrides[name]
+	if !ok {
+		parsed, err := ParseRate(value)
+		if err != nil {
+			panic(fmt.Sprintf("rate limit %s: %v", name, err))
+		}
+		rate = parsed
+	}
+	return r.middleware(name, rate)
+}
+
+func (r *RateLimiter) middleware(name string, rate Rate) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return r.enforce(c, next, name, rate, rateLimitIdentity(c))
+		}
+	}
+}
+
+type rateLimitResult struct {
+	allowed bool
+	count   int64
+	resetAt time.Time
+	// member adalah entry request di sorted set, dipakai untuk mengembalikan hitungan
+	member string
+}
+
+// enforce mencatat request ke window milik identity lalu menolaknya jika limit terlampaui
+func (r *RateLimiter) enforce(c echo.Context, next echo.HandlerFunc, name string, rate Rate, identity string) error {
+	key := rateLimitPrefix + name + ":" + identity
+
+	result, err := r.hit(c, key, rate)
+	if err != nil {
+		// Redis bermasalah: request tetap dilayani daripada seluruh API ikut mati
+		r.logger.Error().Err(err).Str("limiter", name).Msg("rate limiter unavailable")
+		return next(c)
+	}
+
+	r.setHeaders(c, rate, result)
+	if !result.allowed {
+		return r.reject(c, name, key, rate, result)
+	}
+
+	return next(c)
+}
+
+// hit mencatat satu request ke window jika masih di bawah limit
+func (r *RateLimiter) hit(c echo.Context, key string, rate Rate) (rateLimitResult, error) {
+	return r.run(c, key, rate, rate.Limit)
+}
+
+// release menghapus request yang sudah dicatat hit dari window
+func (r *RateLimiter) release(c echo.Context, key string, member string) {
+	// Request bisa saja sudah dibatalkan client, hitungan tetap harus dikembalikan
+	ctx := context.WithoutCancel(c.Request().Context())
+	if err := r.redis.ZRem(ctx, key, member).Err(); err != nil {
+		r.logger.Error().Err(err).Str("key", key).Msg("failed to release rate limit entry")
+	}
+}
+
+func (r *RateLimiter) run(c echo.Context, key string, rate Rate, limit int64) (rateLimitResult, error) {
+	now := time.Now()
+
+	member := strconv.FormatInt(now.UnixNano(), 10)
+	if suffix, err := helper.GenerateRandomString(4); err == nil {
+		member += "-" + suffix
+	}
+
+	result, err := slidingWindowScript.Run(c.Request().Context(), r.redis, []string{key},
+		now.UnixMilli(), rate.Period.Milliseconds(), limit, member).Slice()
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+	if len(result) != 3 {
+		return rateLimitResult{}, fmt.Errorf("unexpected rate limiter result: %v", result)
+	}
+
+	allowed, _ := result[0].(int64)
+	count, _ := result[1].(int64)
+	oldest, _ := strconv.ParseFloat(fmt.Sprint(result[2]), 64)
+
+	return rateLimitResult{
+		allowed: allowed == 1,
+		count:   count,
+		resetAt: time.UnixMilli(int64(oldest)).Add(rate.Period),
+		member:  member,
+	}, nil
+}
+
+func (r *RateLimiter) setHeaders(c echo.Context, rate Rate, result rateLimitResult) {
+	header := c.Response().Header()
+	header.Set("RateLimit-Limit", strconv.FormatInt(rate.Limit, 10))
+	header.Set("RateLimit-Remaining", strconv.FormatInt(max(rate.Limit-result.count, 0), 10))
+	header.Set("RateLimit-Reset", strconv.FormatInt(resetSeconds(result.resetAt), 10))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rate.Limit, int64(rate.Period.Seconds())))
+}
+
+func (r *RateLimiter) reject(c echo.Context, name string, key string, rate Rate, result rateLimitResult) error {
+	r.setHeaders(c, rate, result)
+	c.Response().Header().Set("Retry-After", strconv.FormatInt(max(resetSeconds(result.resetAt), 1), 10))
+	r.logger.Warn().Str("event", "security.rate_limited").Str("limiter", name).Str("key", key).Msg("rate limit exceeded")
+	return errs.TooManyRequests(http.StatusText(http.StatusTooManyRequests), nil)
+}
+
+func resetSeconds(resetAt time.Time) int64 {
+	reset := int64(math.Ceil(time.Until(resetAt).Seconds()))
+	if reset < 0 {
+		return 0
+	}
+	return reset
+}
+
+func noopMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
+
+func isAuthenticated(c echo.Context) bool {
+	user, ok := c.Get("user").(*account.User)
+	return ok && user != nil
+}
+
+// rateLimitIdentity memakai API key atau user yang sudah terautentikasi, selain itu IP client.
+// Header mentah tidak dipakai agar limit tidak bisa dihindari dengan mengirim credential acak.
+func rateLimitIdentity(c echo.Context) string {
+	if apiKeyID, ok := c.Get("api_key_id").(string); ok && apiKeyID != "" {
+		return "apikey:" + apiKeyID
+	}
+	if user, ok := c.Get("user").(*account.User); ok && user != nil {
+		return "user:" + user.ID.Hex()
+	}
+	return "ip:" + c.RealIP()
+}
@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rate
+		wantErr bool
+	}{
+		{value: "100-M", want: Rate{Limit: 100, Period: time.Minute}},
+		{value: "5-S", want: Rate{Limit: 5, Period: time.Second}},
+		{value: "10-h", want: Rate{Limit: 10, Period: time.Hour}},
+		{value: "1000-D", want: Rate{Limit: 1000, Period: 24 * time.Hour}},
+		{value: " 20 - m ", want: Rate{Limit: 20, Period: time.Minute}},
+		{value: "60", want: Rate{Limit: 60, Period: time.Minute}},
+		{value: "", wantErr: true},
+		{value: "abc-M", wantErr: true},
+		{value: "0-M", wantErr: true},
+		{value: "-5-M", wantErr: true},
+		{value: "10-W", wantErr: true},
+		{value: "10-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRate(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRate(%q) = %+v, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRate(%q) error = %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRate(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		name              string
+		security          configs.SecurityConfig
+		wantRate          *Rate
+		wantAuthenticated *Rate
+		wantOverrides     map[string]Rate
+		wantErr           bool
+	}{
+		{
+			name:          "empty rate disables the global limit",
+			security:      configs.SecurityConfig{},
+			wantOverrides: map[string]Rate{},
+		},
+		{
+			name:              "global and authenticated limits",
+			security:          configs.SecurityConfig{RateLimit: "60-M", RateLimitAuthenticated: "600-M"},
+			wantRate:          &Rate{Limit: 60, Period: time.Minute},
+			wantAuthenticated: &Rate{Limit: 600, Period: time.Minute},
+			wantOverrides:     map[string]Rate{},
+		},
+		{
+			name:     "overrides",
+			security: configs.SecurityConfig{RateLimitOverrides: []string{"login=5-M", " refresh = 30-H"}},
+			wantOverrides: map[string]Rate{
+				"login":   {Limit: 5, Period: time.Minute},
+				"refresh": {Limit: 30, Period: time.Hour},
+			},
+		},
+		{
+			name:     "override without a name",
+			security: configs.SecurityConfig{RateLimitOverrides: []string{"5-M"}},
+			wantErr:  true,
+		},
+		{
+			name:     "override with an invalid rate",
+			security: configs.SecurityConfig{RateLimitOverrides: []string{"login=five"}},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid global rate",
+			security: configs.SecurityConfig{RateLimit: "60-X"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid authenticated rate",
+			security: configs.SecurityConfig{RateLimitAuthenticated: "many"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			limiter, err := NewRateLimiter(nil, &logger, &configs.Config{Security: tt.security})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewRateLimiter() want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewRateLimiter() error = %v", err)
+			}
+
+			if !equalRate(limiter.rate, tt.wantRate) {
+				t.Errorf("rate = %+v, want %+v", limiter.rate, tt.wantRate)
+			}
+			if !equalRate(limiter.authenticated, tt.wantAuthenticated) {
+				t.Errorf("authenticated = %+v, want %+v", limiter.authenticated, tt.wantAuthenticated)
+			}
+			if len(limiter.overrides) != len(tt.wantOverrides) {
+				t.Fatalf("overrides = %+v, want %+v", limiter.overrides, tt.wantOverrides)
+			}
+			for name, rate := range tt.wantOverrides {
+				if limiter.overrides[name] != rate {
+					t.Errorf("overrides[%q] = %+v, want %+v", name, limiter.overrides[name], rate)
+				}
+			}
+		})
+	}
+}
+
+func equalRate(a *Rate, b *Rate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func newTestRateLimiter(t *testing.T, rate string) *RateLimiter {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	logger := zerolog.Nop()
+	limiter, err := NewRateLimiter(client, &logger, &configs.Config{Security: configs.SecurityConfig{RateLimit: rate}})
+	if err != nil {
+		t.Fatalf("NewRateLimiter() error = %v", err)
+	}
+	return limiter
+}
+
+// testAuthHandler meniru AuthRequired: header Authorization "valid" diautentikasi, selain itu ditolak
+func testAuthHandler(calls *atomic.Int64) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		calls.Add(1)
+		switch c.Request().Header.Get(echo.HeaderAuthorization) {
+		case "":
+			return nil
+		case "valid":
+			c.Set("user", &account.User{})
+			return nil
+		default:
+			time.Sleep(10 * time.Millisecond)
+			return errs.Unauthorized("Unauthorized", nil)
+		}
+	}
+}
+
+func serveRateLimited(e *echo.Echo, handler echo.HandlerFunc, authorization string) error {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.10:1234"
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	return handler(e.NewContext(req, httptest.NewRecorder()))
+}
+
+func isTooManyRequests(err error) bool {
+	var customErr *errs.CustomError
+	return errors.As(err, &customErr) && customErr.StatusCode() == http.StatusTooManyRequests
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []string
+		// wantLimited berisi index request yang harus ditolak
+		wantLimited []int
+	}{
+		{
+			name:        "anonymous requests are limited per ip",
+			requests:    []string{"", "", "", ""},
+			wantLimited: []int{3},
+		},
+		{
+			name:     "authenticated requests do not use the ip limit",
+			requests: []string{"valid", "valid", "valid", "valid", "", "", ""},
+		},
+		{
+			name:        "rejected credentials count towards the ip limit",
+			requests:    []string{"invalid", "invalid", "invalid", "valid", ""},
+			wantLimited: []int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int64
+			handler := newTestRateLimiter(t, "3-M").Global()(testAuthHandler(&calls))
+			e := echo.New()
+
+			limited := map[int]bool{}
+			for _, i := range tt.wantLimited {
+				limited[i] = true
+			}
+
+			for i, authorization := range tt.requests {
+				err := serveRateLimited(e, handler, authorization)
+				if isTooManyRequests(err) != limited[i] {
+					t.Errorf("request %d (%q) error = %v, want limited %v", i, authorization, err, limited[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterGlobalConcurrentRequests(t *testing.T) {
+	const limit = 5
+
+	var calls atomic.Int64
+	handler := newTestRateLimiter(t, strconv.Itoa(limit)+"-M").Global()(testAuthHandler(&calls))
+	e := echo.New()
+
+	// Request dengan credential yang ditolak berjalan bersamaan, hanya sebanyak limit yang boleh sampai ke handler
+	var wg sync.WaitGroup
+	for i := 0; i < 4*limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = serveRateLimited(e, handler, "invalid")
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != limit {
+		t.Errorf("handler calls = %d, want %d", got, limit)
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/labstack/echo/v4"
+)
+
+const corsMaxAge = 600
+
+var (
+	corsAllowMethods  = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	corsAllowHeaders  = []string{"Authorization", "Content-Type", "X-Requested-With"}
+	corsExposeHeaders = []string{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
+)
+
+// SecurityHeaders menambahkan header keamanan dari SecurityConfig, header yang kosong tidak dikirim.
+// Strict-Transport-Security hanya dikirim lewat HTTPS karena browser mengabaikannya di HTTP.
+func SecurityHeaders(config configs.SecurityConfig) echo.MiddlewareFunc {
+	headers := map[string]string{
+		"X-Frame-Options":         config.XFrameOptions,
+		"Content-Security-Policy": config.ContentSecurity,
+		"X-XSS-Protection":        config.XXSSProtection,
+		"Referrer-Policy":         config.ReferrerPolicy,
+		"X-Content-Type-Options":  config.XContentTypeOpts,
+		"Permissions-Policy":      config.PermissionsPolicy,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			for name, value := range headers {
+				if value != "" {
+					header.Set(name, value)
+				}
+			}
+
+			if config.StrictTransport != "" && c.Scheme() == "https" {
+				header.Set("Strict-Transport-Security", config.StrictTransport)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ExpectedHost menolak request dengan header Host selain EXPECTED_HOST (bisa lebih dari satu, dipisah koma).
+// Port pada header Host diabaikan kecuali EXPECTED_HOST juga menyertakan port.
+func ExpectedHost(expected string) echo.MiddlewareFunc {
+	allowed := make(map[string]struct{})
+	for _, host := range strings.Split(expected, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowed[host] = struct{}{}
+		}
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(allowed) == 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			host := strings.ToLower(c.Request().Host)
+			if _, ok := allowed[host]; ok {
+				return next(c)
+			}
+
+			if hostname, _, err := net.SplitHostPort(host); err == nil {
+				if _, ok := allowed[hostname]; ok {
+					return next(c)
+				}
+			}
+
+			return errs.BadRequest("invalid host header", nil)
+		}
+	}
+}
+
+// CORS mengizinkan origin dari ALLOWED_ORIGINS ("*" untuk semua origin) dan menjawab preflight request.
+// Hanya origin yang terdaftar eksplisit yang mendapat Access-Control-Allow-Credentials, "*" dikirim apa adanya tanpa credential.
+// Jika checkOrigin aktif (ACTIVATE_ORIGIN_VALIDATION), request dengan Origin yang tidak terdaftar ditolak.
+func CORS(origins []string, checkOrigin bool) echo.MiddlewareFunc {
+	allowAll := false
+	allowed := make(map[string]struct{})
+	for _, origin := range origins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			allowAll = true
+		} else if origin != "" {
+			allowed[strings.ToLower(origin)] = struct{}{}
+		}
+	}
+
+	allowMethods := strings.Join(corsAllowMethods, ", ")
+	allowHeaders := strings.Join(corsAllowHeaders, ", ")
+	exposeHeaders := strings.Join(corsExposeHeaders, ", ")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			header := c.Response().Header()
+			origin := req.Header.Get(echo.HeaderOrigin)
+			preflight := req.Method == http.MethodOptions && req.Header.Get(echo.HeaderAccessControlRequestMethod) != ""
+
+			header.Add(echo.HeaderVary, echo.HeaderOrigin)
+			if origin == "" {
+				return next(c)
+			}
+
+			_, ok := allowed[strings.ToLower(origin)]
+			if !ok && !allowAll {
+				if checkOrigin {
+					return errs.Forbidden("origin not allowed", nil)
+				}
+				if preflight {
+					return c.NoContent(http.StatusNoContent)
+				}
+				return next(c)
+			}
+
+			if ok {
+				// Origin terdaftar dipantulkan agar request dengan credential tetap diizinkan
+				header.Set(echo.HeaderAccessControlAllowOrigin, origin)
+				header.Set(echo.HeaderAccessControlAllowCredentials, "true")
+			} else {
+				header.Set(echo.HeaderAccessControlAllowOrigin, "*")
+			}
+
+			if !preflight {
+				header.Set(echo.HeaderAccessControlExposeHeaders, exposeHeaders)
+				return next(c)
+			}
+
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			header.Set(echo.HeaderAccessControlAllowMethods, allowMethods)
+			header.Set(echo.HeaderAccessControlAllowHeaders, allowHeaders)
+			header.Set(echo.HeaderAccessControlMaxAge, strconv.Itoa(corsMaxAge))
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// APIKeyScheme adalah awalan setiap API key, format lengkapnya sk_<prefix>_<secret>
+const APIKeyScheme = "sk"
+
+type (
+	APIKey struct {
+		ID      bson.ObjectID `bson:"_id,omitempty" json:"id"`
+		OwnerID bson.ObjectID `bson:"owner_id" json:"owner_id"`
+		Name    string        `bson:"name" json:"name"`
+		// Prefix dipakai untuk mencari key dan ditampilkan agar key mudah dikenali,
+		// KeyHash adalah hash SHA-256 dari key lengkap yang hanya ditampilkan sekali saat dibuat
+		Prefix  string `bson:"prefix" json:"prefix"`
+		KeyHash string `bson:"key_hash" json:"-"`
+		// Permissions membatasi key ke sebagian permission pemilik, kosong berarti seluruh permission pemilik
+		Permissions []string      `bson:"permissions" json:"permissions"`
+		ExpiresAt   *time.Time    `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+		LastUsedAt  *time.Time    `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+		LastUsedIP  string        `bson:"last_used_ip,omitempty" json:"last_used_ip,omitempty"`
+		RevokedAt   *time.Time    `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+		CreatedBy   bson.ObjectID `bson:"created_by" json:"created_by"`
+		CreatedAt   time.Time     `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	}
+)
+
+type (
+	CreateAPIKeyRequest struct {
+		Name        string     `json:"name" validate:"required,max=100"`
+		Permissions []string   `json:"permissions" validate:"dive,required"`
+		ExpiresAt   *time.Time `json:"expires_at"`
+	}
+
+	// CreateAPIKeyResponse berisi key lengkap yang hanya ditampilkan sekali
+	CreateAPIKeyResponse struct {
+		Key    string  `json:"key"`
+		APIKey *APIKey `json:"api_key"`
+	}
+
+	CreateServiceAccountRequest struct {
+		Name string `json:"name" validate:"required,max=100"`
+	}
+)
+
+// IsActive bernilai true jika key belum dicabut dan belum kedaluwarsa
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
@@ -0,0 +1,11 @@
+package account
+
+type (
+	VerifyEmailRequest struct {
+		Token string `json:"token" validate:"required"`
+	}
+
+	ResendVerificationRequest struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+)
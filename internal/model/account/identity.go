@@ -0,0 +1,21 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type (
+	// UserIdentity menghubungkan user dengan akun di identity provider eksternal (issuer + subject)
+	UserIdentity struct {
+		ID          bson.ObjectID `bson:"_id,omitempty" json:"id"`
+		UserID      bson.ObjectID `bson:"user_id" json:"user_id"`
+		Provider    string        `bson:"provider" json:"provider"`
+		Issuer      string        `bson:"issuer" json:"issuer"`
+		Subject     string        `bson:"subject" json:"subject"`
+		Email       string        `bson:"email" json:"email"`
+		LastLoginAt *time.Time    `bson:"last_login_at,omitempty" json:"last_login_at,omitempty"`
+		CreatedAt   time.Time     `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	}
+)
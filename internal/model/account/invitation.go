@@ -0,0 +1,48 @@
+package account
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Status undangan
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+)
+
+type (
+	Invitation struct {
+		ID    bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+		Email string          `bson:"email" json:"email"`
+		Roles []bson.ObjectID `bson:"roles" json:"roles"`
+		// TokenHash adalah hash SHA-256 dari token undangan, token aslinya hanya dikirim lewat email
+		TokenHash  string        `bson:"token_hash" json:"-"`
+		Status     string        `bson:"status" json:"status"`
+		InvitedBy  bson.ObjectID `bson:"invited_by" json:"invited_by"`
+		ExpiresAt  time.Time     `bson:"expires_at" json:"expires_at"`
+		AcceptedAt *time.Time    `bson:"accepted_at,omitempty" json:"accepted_at,omitempty"`
+		CreatedAt  time.Time     `bson:"created_at,omitempty" json:"created_at,omitempty"`
+		UpdatedAt  time.Time     `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+	}
+)
+
+type (
+	CreateInvitationRequest struct {
+		Email string   `json:"email" validate:"required,email"`
+		Roles []string `json:"roles" validate:"dive,required"`
+	}
+
+	AcceptInvitationRequest struct {
+		Token    string `json:"token" validate:"required"`
+		Name     string `json:"name" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+)
+
+// IsExpired bernilai true jika undangan masih pending namun sudah melewati ExpiresAt
+func (i *Invitation) IsExpired() bool {
+	return i.Status == InvitationPending && time.Now().After(i.ExpiresAt)
+}
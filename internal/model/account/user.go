@@ -1,62 +1,135 @@
 package account
 
 import (
+	"strings"
 	"time"
 
-	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type (
 	User struct {
-		ID          bson.ObjectID   `bson:"_id,omitempty" json:"id"`
-		Email       string          `bson:"email" json:"email"`
-		Name        string          `bson:"name" json:"name"`
-		Password    string          `bson:"password" json:"password"`
-		Roles       []bson.ObjectID `bson:"roles" json:"roles"`
-		RolesDetail *[]Role         `bson:"-"`
-		CreatedAt   time.Time       `bson:"created_at,omitempty" json:"created_at,omitempty"`
-		UpdatedAt   time.Time       `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+		ID       bson.ObjectID `bson:"_id,omitempty" json:"id"`
+		Email    string        `bson:"email" json:"email"`
+		Name     string        `bson:"name" json:"name"`
+		Password string        `bson:"password" json:"password"`
+		// PasswordHistory berisi hash bcrypt password sebelumnya, terbaru di depan
+		PasswordHistory   []string        `bson:"password_history,omitempty" json:"-"`
+		PasswordChangedAt *time.Time      `bson:"password_changed_at,omitempty" json:"password_changed_at,omitempty"`
+		Roles             []bson.ObjectID `bson:"roles" json:"roles"`
+		RolesDetail       *[]Role         `bson:"-"`
+		TOTPSecret        string          `bson:"totp_secret,omitempty" json:"-"`
+		TOTPEnabled       bool            `bson:"totp_enabled" json:"totp_enabled"`
+		// TOTPLastStep adalah time step kode TOTP terakhir yang diterima, kode dengan step yang sama tidak bisa dipakai ulang
+		TOTPLastStep int64 `bson:"totp_last_step,omitempty" json:"-"`
+		// RecoveryCodes berisi hash bcrypt dari kode pemulihan 2FA yang belum dipakai
+		RecoveryCodes []string `bson:"recovery_codes,omitempty" json:"-"`
+		// Status verifikasi email. PendingEmail adalah email baru yang menunggu verifikasi,
+		// Email lama tetap dipakai login sampai email baru dikonfirmasi
+		EmailVerified bool       `bson:"email_verified" json:"email_verified"`
+		VerifiedAt    *time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+		PendingEmail  string     `bson:"pending_email,omitempty" json:"pending_email,omitempty"`
+		// LockedUntil diisi ketika akun dikunci sementara karena terlalu banyak login gagal.
+		// Lock ini hanya menolak login password baru, session, API key dan token OAuth yang sudah ada tetap berlaku.
+		LockedUntil *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+		// Disabled diisi admin untuk menonaktifkan akun, seluruh login dan kredensial akun tersebut ditolak
+		Disabled bool `bson:"disabled,omitempty" json:"disabled"`
+		// ServiceAccount adalah user untuk otomasi, tidak bisa login dan hanya memakai API key
+		ServiceAccount bool      `bson:"service_account,omitempty" json:"service_account"`
+		CreatedAt      time.Time `bson:"created_at,omitempty" json:"created_at,omitempty"`
+		UpdatedAt      time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 	}
 )
 
 type (
 	UserResponse struct {
-		ID        string    `bson:"_id,omitempty" json:"id"`
-		Email     string    `bson:"email" json:"email"`
-		Name      string    `bson:"name" json:"name"`
-		Roles     *[]Role   `bson:"roles" json:"roles"`
-		CreatedAt time.Time `bson:"created_at,omitempty" json:"created_at,omitempty"`
-		UpdatedAt time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+		ID             string     `bson:"_id,omitempty" json:"id"`
+		Email          string     `bson:"email" json:"email"`
+		Name           string     `bson:"name" json:"name"`
+		Roles          *[]Role    `bson:"roles" json:"roles"`
+		EmailVerified  bool       `bson:"email_verified" json:"email_verified"`
+		VerifiedAt     *time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+		PendingEmail   string     `bson:"pending_email,omitempty" json:"pending_email,omitempty"`
+		LockedUntil    *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+		Disabled       bool       `bson:"disabled" json:"disabled"`
+		TOTPEnabled    bool       `bson:"totp_enabled" json:"totp_enabled"`
+		ServiceAccount bool       `bson:"service_account" json:"service_account"`
+		CreatedAt      time.Time  `bson:"created_at,omitempty" json:"created_at,omitempty"`
+		UpdatedAt      time.Time  `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 	}
 
 	CreateUserRequest struct {
 		Email    string `json:"email" validate:"required,email"`
 		Name     string `json:"name" validate:"required"`
-		Password string `json:"password" validate:"required,min=6"`
+		Password string `json:"password" validate:"required"`
+		// EmailVerified hanya diisi oleh alur internal yang sudah membuktikan kepemilikan email, misalnya undangan
+		EmailVerified bool `json:"-"`
+		// RandomPassword menandai password acak dari alur internal (misalnya provisioning OIDC) yang tidak perlu dicek policy
+		RandomPassword bool `json:"-"`
 	}
 
 	UpdateUserRequest struct {
-		Email    string `json:"email" validate:"email"`
+		Email    string `json:"email" validate:"omitempty,email"`
 		Name     string `json:"name" validate:""`
-		Password string `json:"password" validate:"min=6"`
+		Password string `json:"password" validate:""`
 	}
 )
 
+// IsLocked bernilai true selama akun masih dalam masa lock
+func (u *User) IsLocked() bool {
+	return u.LockedUntil != nil && time.Now().Before(*u.LockedUntil)
+}
+
+// PasswordLastChanged mengembalikan waktu password terakhir diganti, user lama memakai waktu pembuatan akun
+func (u *User) PasswordLastChanged() time.Time {
+	if u.PasswordChangedAt != nil {
+		return *u.PasswordChangedAt
+	}
+	return u.CreatedAt
+}
+
 func (u *User) VerifyPassword(plainPassword string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(plainPassword))
 	return err == nil
 }
 
+// MatchRecoveryCode mencari hash kode pemulihan yang cocok dengan kode yang diberikan
+func (u *User) MatchRecoveryCode(code string) (string, bool) {
+	normalized := NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return "", false
+	}
+
+	for _, hash := range u.RecoveryCodes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(normalized)) == nil {
+			return hash, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeRecoveryCode menghapus spasi dan tanda hubung agar format input tidak berpengaruh
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
 func (u *User) ToUserResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID.Hex(),
-		Email:     u.Email,
-		Name:      u.Name,
-		Roles:     u.RolesDetail,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:             u.ID.Hex(),
+		Email:          u.Email,
+		Name:           u.Name,
+		Roles:          u.RolesDetail,
+		EmailVerified:  u.EmailVerified,
+		VerifiedAt:     u.VerifiedAt,
+		PendingEmail:   u.PendingEmail,
+		LockedUntil:    u.LockedUntil,
+		Disabled:       u.Disabled,
+		TOTPEnabled:    u.TOTPEnabled,
+		ServiceAccount: u.ServiceAccount,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
 	}
 }
 
@@ -71,33 +144,14 @@ func (r *UserResponse) ToUser() *User {
 	}
 }
 
-func (u *User) IsHasAccess(permissions []string) bool {
-	permSet := make(map[string]struct{})
-
-	for _, role := range *u.RolesDetail {
-		for _, p := range role.Permissions {
-			permSet[p] = struct{}{}
-		}
-	}
-
-	if _, ok := permSet["manage:system"]; ok {
-		return true
-	}
-
-	defaultPerms, err := helper.LoadStringListFromYAML("./internal/constant/data.yaml", "default_permission")
-	if err != nil {
-		return false
-	}
-
-	for p := range defaultPerms {
-		permSet[p] = struct{}{}
-	}
-
-	for _, p := range permissions {
-		if _, ok := permSet[p]; ok {
-			return true
+// Grants mengembalikan permission mentah dari seluruh role user, termasuk wildcard.
+// Permission efektif dihitung oleh permission.Catalog.Resolve.
+func (u *User) Grants() []string {
+	var grants []string
+	if u.RolesDetail != nil {
+		for _, role := range *u.RolesDetail {
+			grants = append(grants, role.Permissions...)
 		}
 	}
-
-	return false
+	return grants
 }
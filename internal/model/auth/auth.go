@@ -9,10 +9,17 @@ type (
 	AuthResponse struct {
 		Token        string      `json:"token"`
 		RefreshToken string      `json:"refresh_token"`
+		MFARequired  bool        `json:"mfa_required,omitempty"`
+		MFAToken     string      `json:"mfa_token,omitempty"`
 		Data         interface{} `json:"data"`
 	}
 
 	RenewalTokenRequest struct {
 		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
+
+	LogoutRequest struct {
+		AccessToken  string `json:"-"`
+		RefreshToken string `json:"refresh_token"`
+	}
 )
@@ -0,0 +1,67 @@
+package auth
+
+import "github.com/golang-jwt/jwt/v5"
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	TokenTypeMFA     = "mfa_pending"
+)
+
+type (
+	AccessClaims struct {
+		SessionID string `json:"sid"`
+		TokenType string `json:"typ"`
+		// Actor diisi pada token impersonation, sub adalah user yang ditiru dan act.sub adalah staff
+		// yang sebenarnya melakukan request (RFC 8693 bagian 4.1)
+		Actor *ActorClaim `json:"act,omitempty"`
+		jwt.RegisteredClaims
+	}
+
+	ActorClaim struct {
+		Subject string `json:"sub"`
+	}
+
+	RefreshClaims struct {
+		SessionID string `json:"sid"`
+		FamilyID  string `json:"fid"`
+		TokenType string `json:"typ"`
+		jwt.RegisteredClaims
+	}
+
+	// MFAClaims dipakai untuk token sementara setelah password valid namun kode 2FA belum diverifikasi
+	MFAClaims struct {
+		TokenType string `json:"typ"`
+		jwt.RegisteredClaims
+	}
+)
+
+// UserID mengembalikan id user pemilik token (claim sub)
+func (c *AccessClaims) UserID() string {
+	return c.Subject
+}
+
+// IsImpersonation bernilai true jika token diterbitkan untuk impersonation
+func (c *AccessClaims) IsImpersonation() bool {
+	return c.Actor != nil && c.Actor.Subject != ""
+}
+
+// UserID mengembalikan id user pemilik token (claim sub)
+func (c *RefreshClaims) UserID() string {
+	return c.Subject
+}
+
+// UserID mengembalikan id user pemilik token (claim sub)
+func (c *MFAClaims) UserID() string {
+	return c.Subject
+}
+
+type (
+	// TokenFamily menyimpan refresh token yang sedang aktif dalam satu rantai rotasi
+	TokenFamily struct {
+		ID         string
+		UserID     string
+		SessionID  string
+		CurrentJTI string
+	}
+)
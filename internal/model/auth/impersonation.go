@@ -0,0 +1,18 @@
+package auth
+
+import "github.com/HasanNugroho/golang-starter/internal/model/account"
+
+type (
+	// ImpersonationRequest wajib menyertakan alasan yang dicatat di audit log
+	ImpersonationRequest struct {
+		UserID string `json:"user_id" validate:"required"`
+		Reason string `json:"reason" validate:"required,max=500"`
+	}
+
+	// ImpersonationResponse berisi access token berumur pendek tanpa refresh token
+	ImpersonationResponse struct {
+		Token     string                `json:"token"`
+		ExpiresIn int                   `json:"expires_in"`
+		User      *account.UserResponse `json:"user"`
+	}
+)
@@ -0,0 +1,23 @@
+package auth
+
+type (
+	MFAEnrollResponse struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+
+	MFACodeRequest struct {
+		Code string `json:"code" validate:"required,len=6,numeric"`
+	}
+
+	// MFAVerifyRequest menerima kode TOTP atau salah satu kode pemulihan
+	MFAVerifyRequest struct {
+		MFAToken     string `json:"mfa_token" validate:"required"`
+		Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+		RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+	}
+
+	MFARecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+)
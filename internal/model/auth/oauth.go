@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantClientCredentials = "client_credentials"
+	OAuthGrantRefreshToken      = "refresh_token"
+
+	// Awalan token OAuth, formatnya <scheme>_<secret> sehingga mudah dibedakan dari JWT session
+	OAuthAccessTokenScheme  = "oat"
+	OAuthRefreshTokenScheme = "ort"
+	OAuthClientSecretScheme = "ocs"
+
+	OAuthTokenTypeAccess  = "access_token"
+	OAuthTokenTypeRefresh = "refresh_token"
+)
+
+type (
+	// OAuthClient adalah aplikasi pihak ketiga yang terdaftar. Scopes adalah permission dari
+	// katalog yang boleh diminta client, client tanpa secret adalah public client (wajib PKCE).
+	OAuthClient struct {
+		ID           bson.ObjectID `bson:"_id,omitempty" json:"id"`
+		ClientID     string        `bson:"client_id" json:"client_id"`
+		Name         string        `bson:"name" json:"name"`
+		SecretHash   string        `bson:"secret_hash,omitempty" json:"-"`
+		RedirectURIs []string      `bson:"redirect_uris" json:"redirect_uris"`
+		Scopes       []string      `bson:"scopes" json:"scopes"`
+		GrantTypes   []string      `bson:"grant_types" json:"grant_types"`
+		// ServiceAccountID adalah user yang diwakili token client_credentials
+		ServiceAccountID *bson.ObjectID `bson:"service_account_id,omitempty" json:"service_account_id,omitempty"`
+		CreatedBy        bson.ObjectID  `bson:"created_by" json:"created_by"`
+		CreatedAt        time.Time      `bson:"created_at,omitempty" json:"created_at,omitempty"`
+		RevokedAt        *time.Time     `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	}
+
+	// OAuthConsent mencatat scope yang sudah disetujui user untuk sebuah client
+	OAuthConsent struct {
+		ID        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+		UserID    bson.ObjectID `bson:"user_id" json:"user_id"`
+		ClientID  string        `bson:"client_id" json:"client_id"`
+		Scopes    []string      `bson:"scopes" json:"scopes"`
+		CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+		UpdatedAt time.Time     `bson:"updated_at" json:"updated_at"`
+	}
+
+	// OAuthAuthorizationCode disimpan di Redis sampai ditukar di token endpoint
+	OAuthAuthorizationCode struct {
+		ClientID      string   `json:"client_id"`
+		UserID        string   `json:"user_id"`
+		RedirectURI   string   `json:"redirect_uri"`
+		Scopes        []string `json:"scopes"`
+		CodeChallenge string   `json:"code_challenge"`
+	}
+
+	// OAuthGrant mengelompokkan token yang berasal dari satu otorisasi, mencabut grant
+	// mencabut seluruh access dan refresh token di dalamnya
+	OAuthGrant struct {
+		ID       string `json:"id"`
+		ClientID string `json:"client_id"`
+		UserID   string `json:"user_id"`
+	}
+
+	// OAuthToken adalah data token opaque yang disimpan di Redis dengan key hash token
+	OAuthToken struct {
+		Type      string    `json:"type"`
+		GrantID   string    `json:"grant_id"`
+		ClientID  string    `json:"client_id"`
+		UserID    string    `json:"user_id"`
+		Scopes    []string  `json:"scopes"`
+		IssuedAt  time.Time `json:"issued_at"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// OAuthError adalah error protokol OAuth2 (RFC 6749 bagian 5.2)
+	OAuthError struct {
+		Status      int    `json:"-"`
+		Code        string `json:"error"`
+		Description string `json:"error_description,omitempty"`
+	}
+)
+
+type (
+	CreateOAuthClientRequest struct {
+		Name         string   `json:"name" validate:"required,max=100"`
+		RedirectURIs []string `json:"redirect_uris" validate:"dive,required,uri"`
+		Scopes       []string `json:"scopes" validate:"required,min=1,dive,required"`
+		GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code client_credentials refresh_token"`
+		// Public client (SPA, aplikasi mobile) tidak mendapat secret
+		Public           bool   `json:"public"`
+		ServiceAccountID string `json:"service_account_id"`
+	}
+
+	// CreateOAuthClientResponse berisi client secret yang hanya ditampilkan sekali
+	CreateOAuthClientResponse struct {
+		ClientSecret string       `json:"client_secret,omitempty"`
+		Client       *OAuthClient `json:"client"`
+	}
+
+	OAuthAuthorizeRequest struct {
+		ResponseType        string `query:"response_type" json:"response_type" validate:"required"`
+		ClientID            string `query:"client_id" json:"client_id" validate:"required"`
+		RedirectURI         string `query:"redirect_uri" json:"redirect_uri"`
+		Scope               string `query:"scope" json:"scope"`
+		State               string `query:"state" json:"state"`
+		CodeChallenge       string `query:"code_challenge" json:"code_challenge"`
+		CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method"`
+	}
+
+	OAuthConsentDecisionRequest struct {
+		OAuthAuthorizeRequest
+		Approve bool `json:"approve"`
+	}
+
+	OAuthScope struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	// OAuthAuthorizeResponse berisi RedirectURI jika frontend harus langsung mengarahkan browser ke client
+	// (consent sudah ada atau request ditolak), selain itu berisi data untuk halaman consent
+	OAuthAuthorizeResponse struct {
+		RedirectURI     string       `json:"redirect_uri,omitempty"`
+		ConsentRequired bool         `json:"consent_required"`
+		ClientID        string       `json:"client_id,omitempty"`
+		ClientName      string       `json:"client_name,omitempty"`
+		Scopes          []OAuthScope `json:"scopes,omitempty"`
+	}
+
+	// OAuthTokenRequest adalah body form token endpoint, client secret juga bisa dikirim lewat Basic auth
+	OAuthTokenRequest struct {
+		GrantType    string `form:"grant_type"`
+		Code         string `form:"code"`
+		RedirectURI  string `form:"redirect_uri"`
+		CodeVerifier string `form:"code_verifier"`
+		RefreshToken string `form:"refresh_token"`
+		Scope        string `form:"scope"`
+		ClientID     string `form:"client_id"`
+		ClientSecret string `form:"client_secret"`
+	}
+
+	OAuthTokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		Scope        string `json:"scope"`
+	}
+
+	// OAuthTokenLookupRequest adalah body form endpoint introspection (RFC 7662) dan revocation (RFC 7009)
+	OAuthTokenLookupRequest struct {
+		Token         string `form:"token"`
+		TokenTypeHint string `form:"token_type_hint"`
+		ClientID      string `form:"client_id"`
+		ClientSecret  string `form:"client_secret"`
+	}
+
+	OAuthIntrospectionResponse struct {
+		Active    bool   `json:"active"`
+		Scope     string `json:"scope,omitempty"`
+		ClientID  string `json:"client_id,omitempty"`
+		Subject   string `json:"sub,omitempty"`
+		TokenType string `json:"token_type,omitempty"`
+		ExpiresAt int64  `json:"exp,omitempty"`
+		IssuedAt  int64  `json:"iat,omitempty"`
+	}
+
+	// OAuthClientCredentials adalah kredensial client dari Basic auth atau body form
+	OAuthClientCredentials struct {
+		ClientID     string
+		ClientSecret string
+	}
+)
+
+func NewOAuthError(status int, code string, description string) *OAuthError {
+	return &OAuthError{Status: status, Code: code, Description: description}
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+// IsActive bernilai true jika client belum dicabut
+func (c *OAuthClient) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// IsPublic bernilai true untuk client tanpa secret
+func (c *OAuthClient) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	for _, allowed := range c.GrantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package auth
+
+type (
+	// OIDCState disimpan di Redis selama user berada di halaman login provider
+	OIDCState struct {
+		Provider     string `json:"provider"`
+		CodeVerifier string `json:"code_verifier"`
+		Nonce        string `json:"nonce"`
+		RedirectURI  string `json:"redirect_uri"`
+	}
+
+	OIDCProviderResponse struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+	}
+
+	OIDCAuthorizeResponse struct {
+		AuthorizationURL string `json:"authorization_url"`
+		State            string `json:"state"`
+	}
+
+	// OIDCCallbackRequest berisi code dan state yang diterima frontend dari redirect provider
+	OIDCCallbackRequest struct {
+		Code  string `json:"code" validate:"required"`
+		State string `json:"state" validate:"required"`
+	}
+)
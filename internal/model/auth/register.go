@@ -0,0 +1,16 @@
+package auth
+
+// Mode registrasi publik yang diatur lewat REGISTRATION_MODE
+const (
+	RegistrationDisabled = "disabled"
+	RegistrationOpen     = "open"
+	RegistrationInvite   = "invite"
+)
+
+type (
+	RegisterRequest struct {
+		Email    string `json:"email" validate:"required,email"`
+		Name     string `json:"name" validate:"required"`
+		Password string `json:"password" validate:"required"`
+	}
+)
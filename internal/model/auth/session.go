@@ -0,0 +1,45 @@
+package auth
+
+import "time"
+
+type (
+	Session struct {
+		ID         string    `json:"id"`
+		UserID     string    `json:"user_id"`
+		Device     string    `json:"device"`
+		IPAddress  string    `json:"ip_address"`
+		CreatedAt  time.Time `json:"created_at"`
+		LastSeenAt time.Time `json:"last_seen_at"`
+		ExpiresAt  time.Time `json:"expires_at"`
+	}
+)
+
+type (
+	SessionResponse struct {
+		ID         string    `json:"id"`
+		Device     string    `json:"device"`
+		IPAddress  string    `json:"ip_address"`
+		CreatedAt  time.Time `json:"created_at"`
+		LastSeenAt time.Time `json:"last_seen_at"`
+		ExpiresAt  time.Time `json:"expires_at"`
+		Current    bool      `json:"current"`
+	}
+
+	// ClientInfo berisi informasi perangkat yang melakukan request
+	ClientInfo struct {
+		IPAddress string
+		Device    string
+	}
+)
+
+func (s *Session) ToSessionResponse(currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		Device:     s.Device,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.ID == currentSessionID,
+	}
+}
@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// LogNotifier hanya mencatat penerima dan subject ke log, dipakai saat development.
+// Isi pesan tidak ditulis karena berisi token reset password, undangan dan verifikasi email.
+type LogNotifier struct {
+	logger *zerolog.Logger
+}
+
+func NewLogNotifier(logger *zerolog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, message Message) error {
+	n.logger.Info().
+		Str("to", message.To).
+		Str("subject", message.Subject).
+		Msg("notification sent")
+	return nil
+}
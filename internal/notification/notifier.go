@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Message adalah pesan yang dikirim ke user. Jika Template diisi, Subject dan Body
+	// dirender dari template dengan Data sebelum dikirim.
+	Message struct {
+		To       string
+		Subject  string
+		Body     string
+		Template string
+		Data     interface{}
+	}
+
+	INotifier interface {
+		Send(ctx context.Context, message Message) error
+	}
+)
+
+// NewNotifier membuat notifier sesuai MAIL_DRIVER (smtp, outbox atau log) yang sudah dibungkus renderer template
+func NewNotifier(cfg configs.MailConfig, logger *zerolog.Logger) (INotifier, error) {
+	templates, err := NewTemplates(cfg.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifier INotifier
+	switch strings.ToLower(cfg.Driver) {
+	case "smtp":
+		notifier = NewSMTPNotifier(cfg)
+	case "outbox":
+		notifier, err = NewOutboxNotifier(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+	case "log":
+		notifier = NewLogNotifier(logger)
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER: %s", cfg.Driver)
+	}
+
+	return NewTemplateNotifier(templates, notifier), nil
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/rs/zerolog"
+)
+
+// OutboxNotifier menyimpan setiap pesan sebagai file .eml di MAIL_OUTBOX_DIR,
+// dipakai saat development dan testing tanpa server SMTP
+type OutboxNotifier struct {
+	dir    string
+	from   mail.Address
+	logger *zerolog.Logger
+}
+
+func NewOutboxNotifier(config configs.MailConfig, logger *zerolog.Logger) (*OutboxNotifier, error) {
+	dir := config.OutboxDir
+	if dir == "" {
+		dir = "storage/outbox"
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	return &OutboxNotifier{
+		dir:    dir,
+		from:   mail.Address{Name: config.FromName, Address: config.From},
+		logger: logger,
+	}, nil
+}
+
+func (n *OutboxNotifier) Send(ctx context.Context, message Message) error {
+	data, err := buildMIME(n.from, message)
+	if err != nil {
+		return err
+	}
+
+	suffix, err := helper.GenerateRandomString(4)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), suffix)
+	path := filepath.Join(n.dir, name)
+
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return err
+	}
+
+	n.logger.Info().
+		Str("to", message.To).
+		Str("subject", message.Subject).
+		Str("file", path).
+		Msg("notification written to outbox")
+	return nil
+}
@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+)
+
+const smtpTimeout = 10 * time.Second
+
+// SMTPNotifier mengirim pesan sebagai email lewat server SMTP
+type SMTPNotifier struct {
+	config configs.MailConfig
+}
+
+func NewSMTPNotifier(config configs.MailConfig) *SMTPNotifier {
+	return &SMTPNotifier{config: config}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, message Message) error {
+	from := mail.Address{Name: n.config.FromName, Address: n.config.From}
+	data, err := buildMIME(from, message)
+	if err != nil {
+		return err
+	}
+
+	to, _ := mail.ParseAddress(message.To)
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	client, err := n.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial membuka koneksi sesuai SMTP_ENCRYPTION: tls (implicit, biasanya port 465),
+// starttls (upgrade koneksi plain) atau none (tanpa enkripsi, untuk server lokal seperti mailpit)
+func (n *SMTPNotifier) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	tlsConfig := &tls.Config{ServerName: n.config.Host}
+	encryption := strings.ToLower(n.config.Encryption)
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if encryption == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if err := client.Hello(helloName()); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if encryption == "starttls" {
+		if supported, _ := client.Extension("STARTTLS"); !supported {
+			client.Close()
+			return nil, fmt.Errorf("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func helloName() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "localhost"
+	}
+	return name
+}
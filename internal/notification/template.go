@@ -0,0 +1,118 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// Templates berisi template pesan. Setiap file <nama>.tmpl wajib mendefinisikan blok "subject" dan "body".
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// NewTemplates memuat template bawaan, lalu menimpanya dengan file *.tmpl dari dir jika diisi
+func NewTemplates(dir string) (*Templates, error) {
+	t := &Templates{templates: make(map[string]*template.Template)}
+
+	entries, err := defaultTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		content, err := defaultTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if err := t.add(entry.Name(), string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return t, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.add(filepath.Base(file), string(content)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Render menghasilkan subject dan body dari template dengan nama tertentu
+func (t *Templates) Render(name string, data interface{}) (string, string, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("template %s not found", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()) + "\n", nil
+}
+
+func (t *Templates) add(filename string, content string) error {
+	name := strings.TrimSuffix(filename, ".tmpl")
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", filename, err)
+	}
+
+	for _, block := range []string{"subject", "body"} {
+		if tmpl.Lookup(block) == nil {
+			return fmt.Errorf("template %s must define %q", filename, block)
+		}
+	}
+
+	t.templates[name] = tmpl
+	return nil
+}
+
+// TemplateNotifier merender Message.Template sebelum diteruskan ke notifier lain
+type TemplateNotifier struct {
+	templates *Templates
+	next      INotifier
+}
+
+func NewTemplateNotifier(templates *Templates, next INotifier) *TemplateNotifier {
+	return &TemplateNotifier{templates: templates, next: next}
+}
+
+func (n *TemplateNotifier) Send(ctx context.Context, message Message) error {
+	if message.Template != "" {
+		subject, body, err := n.templates.Render(message.Template, message.Data)
+		if err != nil {
+			return err
+		}
+		message.Subject = subject
+		message.Body = body
+	}
+
+	return n.next.Send(ctx, message)
+}
@@ -0,0 +1,89 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+)
+
+type (
+	// Registry berisi seluruh provider yang dikonfigurasi lewat OIDC_PROVIDERS
+	Registry struct {
+		providers map[string]*Provider
+	}
+
+	// Bool menerima email_verified berupa boolean maupun string "true", beberapa provider mengirim string
+	Bool bool
+)
+
+func NewRegistry(config configs.OIDCConfig) *Registry {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	registry := &Registry{providers: make(map[string]*Provider)}
+	for _, providerConfig := range config.Providers {
+		if providerConfig.Issuer == "" || providerConfig.ClientID == "" {
+			continue
+		}
+		registry.providers[providerConfig.Name] = NewProvider(providerConfig, client)
+	}
+	return registry
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	provider, ok := r.providers[strings.ToLower(name)]
+	return provider, ok
+}
+
+// List mengembalikan provider berurutan berdasarkan nama
+func (r *Registry) List() []*Provider {
+	providers := make([]*Provider, 0, len(r.providers))
+	for _, provider := range r.providers {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name() < providers[j].Name()
+	})
+	return providers
+}
+
+// NewPKCE membuat code verifier acak beserta code challenge S256 (RFC 7636)
+func NewPKCE() (string, string, error) {
+	verifier, err := RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// RandomString menghasilkan string acak base64url dari n byte, dipakai untuk state, nonce dan verifier
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*b = false
+		return nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*b = Bool(parsed)
+	return nil
+}
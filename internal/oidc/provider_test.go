@@ -0,0 +1,324 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "golang-starter"
+	testClientSecret = "secret"
+	testKeyID        = "test-key"
+	testNonce        = "nonce"
+)
+
+// mockProvider adalah identity provider lokal yang melayani discovery, JWKS dan token endpoint
+type mockProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	issuer    string
+	discovery func(issuer string) Discovery
+	idToken   string
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	mock := &mockProvider{key: key}
+	mock.discovery = func(issuer string) Discovery {
+		return Discovery{
+			Issuer:                issuer,
+			AuthorizationEndpoint: issuer + "/authorize",
+			TokenEndpoint:         issuer + "/token",
+			JWKSURI:               issuer + "/jwks",
+			SigningAlgorithms:     []string{"RS256"},
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(mock.discovery(mock.issuer))
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(helper.JWKS{Keys: []helper.JWK{{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: testKeyID,
+			N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || clientID != testClientID || clientSecret != testClientSecret ||
+			r.FormValue("grant_type") != "authorization_code" || r.FormValue("code") != "code" || r.FormValue("code_verifier") != "verifier" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access", IDToken: mock.idToken, TokenType: "Bearer", ExpiresIn: 3600})
+	})
+
+	mock.server = httptest.NewServer(mux)
+	mock.issuer = mock.server.URL
+	t.Cleanup(mock.server.Close)
+
+	return mock
+}
+
+func (m *mockProvider) provider() *Provider {
+	return NewProvider(configs.OIDCProviderConfig{
+		Name:         "mock",
+		Issuer:       m.issuer,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Scopes:       []string{"openid", "email"},
+	}, m.server.Client())
+}
+
+func (m *mockProvider) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            m.issuer,
+		"sub":            "subject",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          "user@example.com",
+		"email_verified": "true",
+	}
+}
+
+func (m *mockProvider) sign(t *testing.T, claims jwt.MapClaims, kid string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return signed
+}
+
+func TestProviderDiscovery(t *testing.T) {
+	tests := []struct {
+		name      string
+		discovery func(issuer string) Discovery
+		wantErr   bool
+	}{
+		{
+			name: "valid document",
+		},
+		{
+			name: "issuer mismatch",
+			discovery: func(issuer string) Discovery {
+				return Discovery{Issuer: "https://attacker.example.com", AuthorizationEndpoint: issuer + "/authorize", TokenEndpoint: issuer + "/token", JWKSURI: issuer + "/jwks"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing jwks endpoint",
+			discovery: func(issuer string) Discovery {
+				return Discovery{Issuer: issuer, AuthorizationEndpoint: issuer + "/authorize", TokenEndpoint: issuer + "/token"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockProvider(t)
+			if tt.discovery != nil {
+				mock.discovery = tt.discovery
+			}
+
+			discovery, err := mock.provider().Discovery(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Discovery() = %+v, want error", discovery)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Discovery() error = %v", err)
+			}
+			if discovery.TokenEndpoint != mock.issuer+"/token" || discovery.JWKSURI != mock.issuer+"/jwks" {
+				t.Errorf("Discovery() = %+v", discovery)
+			}
+		})
+	}
+}
+
+func TestProviderVerifyIDToken(t *testing.T) {
+	mock := newMockProvider(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		nonce   string
+		wantErr bool
+	}{
+		{
+			name:  "valid token",
+			token: func(t *testing.T) string { return mock.sign(t, mock.claims(), testKeyID) },
+			nonce: testNonce,
+		},
+		{
+			name:  "single key without kid",
+			token: func(t *testing.T) string { return mock.sign(t, mock.claims(), "") },
+			nonce: testNonce,
+		},
+		{
+			name:    "nonce mismatch",
+			token:   func(t *testing.T) string { return mock.sign(t, mock.claims(), testKeyID) },
+			nonce:   "other-nonce",
+			wantErr: true,
+		},
+		{
+			name: "audience of another client",
+			token: func(t *testing.T) string {
+				claims := mock.claims()
+				claims["aud"] = "other-client"
+				return mock.sign(t, claims, testKeyID)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "issued by another issuer",
+			token: func(t *testing.T) string {
+				claims := mock.claims()
+				claims["iss"] = "https://attacker.example.com"
+				return mock.sign(t, claims, testKeyID)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "authorized party of another client",
+			token: func(t *testing.T) string {
+				claims := mock.claims()
+				claims["azp"] = "other-client"
+				return mock.sign(t, claims, testKeyID)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T) string {
+				claims := mock.claims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return mock.sign(t, claims, testKeyID)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "missing subject",
+			token: func(t *testing.T) string {
+				claims := mock.claims()
+				delete(claims, "sub")
+				return mock.sign(t, claims, testKeyID)
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "signed by a key outside the jwks",
+			token: func(t *testing.T) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, mock.claims())
+				token.Header["kid"] = testKeyID
+				signed, err := token.SignedString(otherKey)
+				if err != nil {
+					t.Fatalf("SignedString() error = %v", err)
+				}
+				return signed
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name:    "unknown kid",
+			token:   func(t *testing.T) string { return mock.sign(t, mock.claims(), "unknown-key") },
+			nonce:   testNonce,
+			wantErr: true,
+		},
+		{
+			name: "hmac signed with the client secret",
+			token: func(t *testing.T) string {
+				signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, mock.claims()).SignedString([]byte(testClientSecret))
+				if err != nil {
+					t.Fatalf("SignedString() error = %v", err)
+				}
+				return signed
+			},
+			nonce:   testNonce,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := mock.provider().VerifyIDToken(context.Background(), tt.token(t), tt.nonce)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("VerifyIDToken() error = %v, want %v", err, ErrInvalidIDToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if claims.Subject != "subject" || claims.Email != "user@example.com" || !bool(claims.EmailVerified) {
+				t.Errorf("VerifyIDToken() = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestProviderExchange(t *testing.T) {
+	mock := newMockProvider(t)
+	mock.idToken = mock.sign(t, mock.claims(), testKeyID)
+	provider := mock.provider()
+
+	token, err := provider.Exchange(context.Background(), "code", "verifier", "http://localhost/callback")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), token.IDToken, testNonce); err != nil {
+		t.Errorf("VerifyIDToken() error = %v", err)
+	}
+
+	if _, err := provider.Exchange(context.Background(), "code", "wrong-verifier", "http://localhost/callback"); err == nil {
+		t.Errorf("Exchange() with a wrong code verifier, want error")
+	}
+}
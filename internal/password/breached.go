@@ -0,0 +1,92 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// rangePrefixLength mengikuti format range API Have I Been Pwned: 5 karakter pertama hash
+	// dipakai sebagai bucket, pencarian hanya membandingkan sisa hash di dalam bucket tersebut
+	rangePrefixLength = 5
+	sha1HexLength     = 40
+)
+
+// BreachedList adalah daftar hash SHA-1 password yang pernah bocor, disimpan per prefix
+// (k-anonymity) sehingga pencarian lokal memakai struktur yang sama dengan range API online.
+type BreachedList struct {
+	ranges map[string][]string
+	size   int
+}
+
+// LoadBreachedList membaca file berisi satu hash SHA-1 per baris, dengan atau tanpa ":COUNT"
+// (format file unduhan Have I Been Pwned). Baris kosong dan baris diawali "#" diabaikan.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachedList{ranges: make(map[string][]string)}
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(strings.TrimSpace(hash))
+		if len(hash) != sha1HexLength {
+			return nil, fmt.Errorf("%s:%d: expected a SHA-1 hash", path, lineNumber)
+		}
+		if _, err := hex.DecodeString(hash); err != nil {
+			return nil, fmt.Errorf("%s:%d: expected a SHA-1 hash", path, lineNumber)
+		}
+
+		prefix := hash[:rangePrefixLength]
+		list.ranges[prefix] = append(list.ranges[prefix], hash[rangePrefixLength:])
+		list.size++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for prefix := range list.ranges {
+		sort.Strings(list.ranges[prefix])
+	}
+
+	return list, nil
+}
+
+// Range mengembalikan sisa hash (tanpa prefix) yang berada di bucket prefix tersebut
+func (b *BreachedList) Range(prefix string) []string {
+	return b.ranges[strings.ToUpper(prefix)]
+}
+
+// Contains bernilai true jika hash SHA-1 password ada di daftar
+func (b *BreachedList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes := b.Range(hash[:rangePrefixLength])
+	suffix := hash[rangePrefixLength:]
+
+	i := sort.SearchStrings(suffixes, suffix)
+	return i < len(suffixes) && suffixes[i] == suffix
+}
+
+// Len mengembalikan jumlah hash di daftar
+func (b *BreachedList) Len() int {
+	return b.size
+}
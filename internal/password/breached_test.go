@@ -0,0 +1,137 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeBreachedList(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "hashes with counts",
+			content: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\n" + sha1Hex("123456") + ":37359195\n",
+			wantLen: 2,
+		},
+		{
+			name:    "hashes without counts, comments and blank lines",
+			content: "# daftar lokal\n\n" + strings.ToLower(sha1Hex("password")) + "\n  " + sha1Hex("letmein") + "  \n",
+			wantLen: 2,
+		},
+		{
+			name:    "empty file",
+			content: "",
+			wantLen: 0,
+		},
+		{
+			name:    "hash too short",
+			content: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68F\n",
+			wantErr: true,
+		},
+		{
+			name:    "not hex",
+			content: "ZZAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n",
+			wantErr: true,
+		},
+		{
+			name:    "plain password",
+			content: "password\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := LoadBreachedList(writeBreachedList(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadBreachedList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if list.Len() != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", list.Len(), tt.wantLen)
+			}
+		})
+	}
+
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("LoadBreachedList() error = nil, want missing file")
+	}
+}
+
+func TestBreachedListContains(t *testing.T) {
+	// Hash "12345" ditulis dengan huruf kecil, pencarian tetap cocok
+	list, err := LoadBreachedList(writeBreachedList(t, strings.Join([]string{
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004",
+		strings.ToLower(sha1Hex("12345")),
+		sha1Hex("qwerty") + ":3912816",
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("LoadBreachedList() error = %v", err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "password", want: true},
+		{password: "12345", want: true},
+		{password: "qwerty", want: true},
+		{password: "Password", want: false},
+		{password: "passwore", want: false},
+		{password: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if got := list.Contains(tt.password); got != tt.want {
+				t.Errorf("Contains(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreachedListRange(t *testing.T) {
+	// Dua hash dengan prefix yang sama masuk ke bucket yang sama dan diurutkan
+	list, err := LoadBreachedList(writeBreachedList(t, strings.Join([]string{
+		"5BAA6FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF",
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8",
+		"00000AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("LoadBreachedList() error = %v", err)
+	}
+
+	got := list.Range("5baa6")
+	want := []string{"1E4C9B93F3F0682250B6CF8331B7EE68FD8", "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Range() = %v, want %v", got, want)
+	}
+	if got := list.Range("FFFFF"); len(got) != 0 {
+		t.Errorf("Range() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,174 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+)
+
+// minPersonalInfoLength mencegah potongan nama yang terlalu pendek (misalnya "al") menolak terlalu banyak password
+const minPersonalInfoLength = 3
+
+// Policy memvalidasi password baru sesuai PasswordPolicyConfig
+type Policy struct {
+	config   configs.PasswordPolicyConfig
+	breached *BreachedList
+}
+
+// NewPolicy membuat policy dan memuat daftar password bocor jika PASSWORD_BREACHED_LIST diisi
+func NewPolicy(config configs.PasswordPolicyConfig) (*Policy, error) {
+	policy := &Policy{config: config}
+
+	if config.BreachedListPath != "" {
+		breached, err := LoadBreachedList(config.BreachedListPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load breached password list: %w", err)
+		}
+		policy.breached = breached
+	}
+
+	return policy, nil
+}
+
+// Validate mengembalikan daftar aturan yang dilanggar, kosong jika password memenuhi policy.
+// personalInfo (email, nama) dipakai untuk menolak password yang mengandung data pribadi user.
+func (p *Policy) Validate(password string, personalInfo ...string) []string {
+	var violations []string
+
+	if utf8.RuneCountInString(password) < p.config.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.config.MinLength))
+	}
+	if len(password) > p.config.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", p.config.MaxLength))
+	}
+
+	lower, upper, digit, symbol := characterClasses(password)
+	if p.config.RequireLowercase && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.config.RequireUppercase && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.config.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.config.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+	if classes := countTrue(lower, upper, digit, symbol); classes < p.config.MinCharacterClasses {
+		violations = append(violations, fmt.Sprintf("must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.config.MinCharacterClasses))
+	}
+
+	if !p.config.AllowPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, "must not contain your name or email address")
+	}
+
+	if p.breached != nil && p.breached.Contains(password) {
+		violations = append(violations, "has appeared in a data breach, choose a different password")
+	}
+
+	return violations
+}
+
+// IsReused bernilai true jika password sama dengan password saat ini atau salah satu password di history
+func (p *Policy) IsReused(password string, current string, history []string) bool {
+	if p.config.History <= 0 {
+		return false
+	}
+
+	if current != "" && helper.VerifyPassword(current, []byte(password)) {
+		return true
+	}
+	for i, hash := range history {
+		if i >= p.config.History-1 {
+			break
+		}
+		if helper.VerifyPassword(hash, []byte(password)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextHistory menyimpan hash password saat ini ke history ketika password diganti.
+// PASSWORD_HISTORY menghitung password saat ini, sehingga history menyimpan N-1 hash sebelumnya.
+func (p *Policy) NextHistory(current string, history []string) []string {
+	limit := p.config.History - 1
+	if limit <= 0 || current == "" {
+		return []string{}
+	}
+
+	next := append([]string{current}, history...)
+	if len(next) > limit {
+		next = next[:limit]
+	}
+	return next
+}
+
+// IsExpired bernilai true jika password sudah melewati PASSWORD_MAX_AGE
+func (p *Policy) IsExpired(changedAt time.Time) bool {
+	if p.config.MaxAge <= 0 {
+		return false
+	}
+	return time.Since(changedAt) > time.Duration(p.config.MaxAge)*24*time.Hour
+}
+
+func characterClasses(password string) (lower bool, upper bool, digit bool, symbol bool) {
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	return
+}
+
+func countTrue(values ...bool) int {
+	count := 0
+	for _, v := range values {
+		if v {
+			count++
+		}
+	}
+	return count
+}
+
+// containsPersonalInfo memeriksa setiap nilai secara utuh, bagian lokal email dan setiap katanya
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	normalized := strings.ToLower(password)
+
+	var candidates []string
+	for _, value := range personalInfo {
+		value = strings.ToLower(strings.TrimSpace(value))
+		local, _, _ := strings.Cut(value, "@")
+		candidates = append(candidates, value, local)
+		candidates = append(candidates, splitWords(local)...)
+	}
+
+	for _, candidate := range candidates {
+		if utf8.RuneCountInString(candidate) < minPersonalInfoLength {
+			continue
+		}
+		if strings.Contains(normalized, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitWords(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,253 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestPolicy(t *testing.T, config configs.PasswordPolicyConfig) *Policy {
+	t.Helper()
+
+	if config.MaxLength == 0 {
+		config.MaxLength = 72
+	}
+	policy, err := NewPolicy(config)
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	return policy
+}
+
+// hash memakai cost minimum agar test riwayat password tidak lambat
+func hash(t *testing.T, password string) string {
+	t.Helper()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	return string(hashed)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         configs.PasswordPolicyConfig
+		password       string
+		personalInfo   []string
+		wantViolations []string
+	}{
+		{
+			name:     "valid password",
+			config:   configs.PasswordPolicyConfig{MinLength: 12},
+			password: "correct horse battery",
+		},
+		{
+			name:           "too short",
+			config:         configs.PasswordPolicyConfig{MinLength: 12},
+			password:       "short",
+			wantViolations: []string{"must be at least 12 characters"},
+		},
+		{
+			// Panjang minimum dihitung per karakter, bukan per byte
+			name:     "multibyte characters count once",
+			config:   configs.PasswordPolicyConfig{MinLength: 4},
+			password: "ééé",
+			wantViolations: []string{
+				"must be at least 4 characters",
+			},
+		},
+		{
+			// Batas maksimum dihitung per byte karena bcrypt hanya memakai 72 byte pertama
+			name:           "too long in bytes",
+			config:         configs.PasswordPolicyConfig{MaxLength: 10},
+			password:       "éééééé",
+			wantViolations: []string{"must be at most 10 bytes"},
+		},
+		{
+			name:     "required character classes",
+			config:   configs.PasswordPolicyConfig{RequireLowercase: true, RequireUppercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "password",
+			wantViolations: []string{
+				"must contain an uppercase letter",
+				"must contain a digit",
+				"must contain a symbol",
+			},
+		},
+		{
+			name:     "every required class present",
+			config:   configs.PasswordPolicyConfig{RequireLowercase: true, RequireUppercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "Pa55 word",
+		},
+		{
+			name:           "minimum character classes",
+			config:         configs.PasswordPolicyConfig{MinCharacterClasses: 3},
+			password:       "password123",
+			wantViolations: []string{"must contain at least 3 of: lowercase letters, uppercase letters, digits, symbols"},
+		},
+		{
+			name:     "minimum character classes met",
+			config:   configs.PasswordPolicyConfig{MinCharacterClasses: 3},
+			password: "Password123",
+		},
+		{
+			name:           "contains personal info",
+			config:         configs.PasswordPolicyConfig{},
+			password:       "janedoe2024",
+			personalInfo:   []string{"jane.doe@example.com", "Jane Doe"},
+			wantViolations: []string{"must not contain your name or email address"},
+		},
+		{
+			name:         "personal info allowed",
+			config:       configs.PasswordPolicyConfig{AllowPersonalInfo: true},
+			password:     "janedoe2024",
+			personalInfo: []string{"jane.doe@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newTestPolicy(t, tt.config)
+
+			got := policy.Validate(tt.password, tt.personalInfo...)
+			if strings.Join(got, "; ") != strings.Join(tt.wantViolations, "; ") {
+				t.Errorf("Validate() = %v, want %v", got, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestPolicyValidateBreached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	policy := newTestPolicy(t, configs.PasswordPolicyConfig{BreachedListPath: path})
+
+	if got := policy.Validate("password"); len(got) != 1 || !strings.Contains(got[0], "data breach") {
+		t.Errorf("Validate() = %v, want breached violation", got)
+	}
+	if got := policy.Validate("Password"); len(got) != 0 {
+		t.Errorf("Validate() = %v, want no violations", got)
+	}
+
+	if _, err := NewPolicy(configs.PasswordPolicyConfig{BreachedListPath: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Errorf("NewPolicy() error = nil, want missing breached list")
+	}
+}
+
+func TestContainsPersonalInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		personalInfo []string
+		want         bool
+	}{
+		{name: "full email", password: "x-jane.doe@example.com-x", personalInfo: []string{"jane.doe@example.com"}, want: true},
+		{name: "email local part", password: "Jane.Doe!2024", personalInfo: []string{"jane.doe@example.com"}, want: true},
+		{name: "word of the email", password: "doe-family-2024", personalInfo: []string{"jane.doe@example.com"}, want: true},
+		{name: "word of the name is case insensitive", password: "ILoveJANE", personalInfo: []string{"Jane Doe"}, want: true},
+		{name: "email domain is ignored", password: "example-rocks", personalInfo: []string{"jane.doe@example.com"}, want: false},
+		{name: "short words are ignored", password: "al-gorithm", personalInfo: []string{"Al Li"}, want: false},
+		{name: "three letters are checked", password: "xxleexx", personalInfo: []string{"Lee"}, want: true},
+		{name: "empty values are ignored", password: "anything", personalInfo: []string{"", "  "}, want: false},
+		{name: "unrelated password", password: "correct horse battery", personalInfo: []string{"jane.doe@example.com", "Jane Doe"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsPersonalInfo(tt.password, tt.personalInfo); got != tt.want {
+				t.Errorf("containsPersonalInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyPasswordHistory(t *testing.T) {
+	passwords := []string{"first-password", "second-password", "third-password", "fourth-password"}
+
+	tests := []struct {
+		name    string
+		history int
+		// wantReused adalah hasil IsReused untuk setiap password setelah seluruh password dipakai berurutan
+		wantReused []bool
+		wantLen    int
+	}{
+		{name: "history disabled", history: 0, wantReused: []bool{false, false, false, false}, wantLen: 0},
+		{name: "only the current password", history: 1, wantReused: []bool{false, false, false, true}, wantLen: 0},
+		{name: "current and one previous", history: 2, wantReused: []bool{false, false, true, true}, wantLen: 1},
+		{name: "current and two previous", history: 3, wantReused: []bool{false, true, true, true}, wantLen: 2},
+		{name: "longer than the passwords used", history: 10, wantReused: []bool{true, true, true, true}, wantLen: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newTestPolicy(t, configs.PasswordPolicyConfig{History: tt.history})
+
+			// Meniru UserService.Update: hash lama masuk history setiap kali password diganti
+			current := hash(t, passwords[0])
+			history := []string{}
+			for _, password := range passwords[1:] {
+				history = policy.NextHistory(current, history)
+				current = hash(t, password)
+			}
+
+			if len(history) != tt.wantLen {
+				t.Errorf("NextHistory() length = %d, want %d", len(history), tt.wantLen)
+			}
+			for i, password := range passwords {
+				if got := policy.IsReused(password, current, history); got != tt.wantReused[i] {
+					t.Errorf("IsReused(%q) = %v, want %v", password, got, tt.wantReused[i])
+				}
+			}
+			if policy.IsReused("never-used-password", current, history) {
+				t.Errorf("IsReused() = true for a new password")
+			}
+		})
+	}
+}
+
+func TestPolicyIsReusedIgnoresEntriesBeyondHistory(t *testing.T) {
+	// History yang tersimpan sebelum PASSWORD_HISTORY diturunkan tidak ikut diperiksa
+	policy := newTestPolicy(t, configs.PasswordPolicyConfig{History: 2})
+	history := []string{hash(t, "previous-password"), hash(t, "older-password")}
+
+	if !policy.IsReused("previous-password", hash(t, "current-password"), history) {
+		t.Errorf("IsReused() = false for the previous password")
+	}
+	if policy.IsReused("older-password", hash(t, "current-password"), history) {
+		t.Errorf("IsReused() = true for a password beyond PASSWORD_HISTORY")
+	}
+	if got := policy.NextHistory(hash(t, "current-password"), history); len(got) != 1 {
+		t.Errorf("NextHistory() length = %d, want 1", len(got))
+	}
+}
+
+func TestPolicyIsExpired(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxAge    int
+		changedAt time.Time
+		want      bool
+	}{
+		{name: "max age disabled", maxAge: 0, changedAt: time.Now().AddDate(-5, 0, 0), want: false},
+		{name: "recently changed", maxAge: 90, changedAt: time.Now().AddDate(0, 0, -89), want: false},
+		{name: "older than max age", maxAge: 90, changedAt: time.Now().AddDate(0, 0, -91), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := newTestPolicy(t, configs.PasswordPolicyConfig{MaxAge: tt.maxAge})
+			if got := policy.IsExpired(tt.changedAt); got != tt.want {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
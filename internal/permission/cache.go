@@ -0,0 +1,133 @@
+package permission
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type (
+	// Cache menyimpan permission efektif per user dengan batas jumlah entry (LRU) dan TTL.
+	// Entry hanya valid untuk kombinasi role yang sama dengan saat disimpan (rolesKey),
+	// sehingga perubahan role user langsung membuat entry lama tidak terpakai.
+	// Epoch naik setiap Remove/Purge agar hasil resolve yang dibaca sebelum invalidasi tidak tersimpan.
+	Cache struct {
+		mu    sync.Mutex
+		size  int
+		ttl   time.Duration
+		epoch uint64
+		order *list.List
+		items map[string]*list.Element
+	}
+
+	cacheEntry struct {
+		userID    string
+		rolesKey  string
+		set       Set
+		expiresAt time.Time
+	}
+)
+
+func NewCache(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get mengembalikan permission user jika masih ada, belum kedaluwarsa dan rolesKey sama.
+// Set yang dikembalikan dipakai bersama, jangan diubah.
+func (c *Cache) Get(userID string, rolesKey string) (Set, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[userID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if entry.rolesKey != rolesKey || time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.set, true
+}
+
+// Epoch mengembalikan generasi cache saat ini, diambil sebelum membaca role dari database
+func (c *Cache) Epoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.epoch
+}
+
+// Add menyimpan permission user dan membuang entry yang paling lama tidak dipakai jika cache penuh.
+// Entry tidak disimpan jika cache sudah diinvalidasi sejak epoch diambil, karena datanya mungkin basi.
+func (c *Cache) Add(userID string, rolesKey string, set Set, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[userID]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.rolesKey = rolesKey
+		entry.set = set
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[userID] = c.order.PushFront(&cacheEntry{
+		userID:    userID,
+		rolesKey:  rolesKey,
+		set:       set,
+		expiresAt: expiresAt,
+	})
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Remove menghapus cache milik satu user
+func (c *Cache) Remove(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	if elem, ok := c.items[userID]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Purge menghapus seluruh isi cache
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Len mengembalikan jumlah entry di cache
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*cacheEntry).userID)
+}
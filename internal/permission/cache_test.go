@@ -0,0 +1,173 @@
+package permission
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		ttl     time.Duration
+		prepare func(c *Cache)
+		userID  string
+		roles   string
+		wantHit bool
+		wantLen int
+	}{
+		{
+			name: "stored entry",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "role-a", newSet("users:read"), c.Epoch())
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantHit: true,
+			wantLen: 1,
+		},
+		{
+			name: "roles changed",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "role-a", newSet("users:read"), c.Epoch())
+			},
+			userID:  "user-1",
+			roles:   "role-a,role-b",
+			wantLen: 0,
+		},
+		{
+			name: "expired entry",
+			size: 10,
+			ttl:  -time.Second,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "role-a", newSet("users:read"), c.Epoch())
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantLen: 0,
+		},
+		{
+			name: "least recently used entry is evicted",
+			size: 2,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "", newSet(), c.Epoch())
+				c.Add("user-2", "", newSet(), c.Epoch())
+				c.Get("user-1", "")
+				c.Add("user-3", "", newSet(), c.Epoch())
+			},
+			userID:  "user-2",
+			wantLen: 2,
+		},
+		{
+			name: "recently used entry is kept",
+			size: 2,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "", newSet(), c.Epoch())
+				c.Add("user-2", "", newSet(), c.Epoch())
+				c.Get("user-1", "")
+				c.Add("user-3", "", newSet(), c.Epoch())
+			},
+			userID:  "user-1",
+			wantHit: true,
+			wantLen: 2,
+		},
+		{
+			name: "removed user",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "", newSet(), c.Epoch())
+				c.Add("user-2", "", newSet(), c.Epoch())
+				c.Remove("user-1")
+			},
+			userID:  "user-1",
+			wantLen: 1,
+		},
+		{
+			name: "purged cache",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Add("user-1", "", newSet(), c.Epoch())
+				c.Add("user-2", "", newSet(), c.Epoch())
+				c.Purge()
+			},
+			userID:  "user-2",
+			wantLen: 0,
+		},
+		{
+			// Remove terjadi setelah epoch diambil tetapi sebelum hasil resolve disimpan
+			name: "invalidation of the user between resolve and store",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				epoch := c.Epoch()
+				c.Remove("user-1")
+				c.Add("user-1", "role-a", newSet("users:read"), epoch)
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantLen: 0,
+		},
+		{
+			name: "invalidation of another user between resolve and store",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				epoch := c.Epoch()
+				c.Remove("user-2")
+				c.Add("user-1", "role-a", newSet("users:read"), epoch)
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantLen: 0,
+		},
+		{
+			name: "purge between resolve and store",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				epoch := c.Epoch()
+				c.Purge()
+				c.Add("user-1", "role-a", newSet("users:read"), epoch)
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantLen: 0,
+		},
+		{
+			name: "resolve after an invalidation is stored",
+			size: 10,
+			ttl:  time.Minute,
+			prepare: func(c *Cache) {
+				c.Remove("user-1")
+				c.Add("user-1", "role-a", newSet("users:read"), c.Epoch())
+			},
+			userID:  "user-1",
+			roles:   "role-a",
+			wantHit: true,
+			wantLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewCache(tt.size, tt.ttl)
+			tt.prepare(cache)
+
+			_, hit := cache.Get(tt.userID, tt.roles)
+			if hit != tt.wantHit {
+				t.Errorf("Get() hit = %v, want %v", hit, tt.wantHit)
+			}
+			if got := cache.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}
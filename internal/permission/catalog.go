@@ -0,0 +1,195 @@
+package permission
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Wildcard cocok dengan semua resource atau action
+	Wildcard = "*"
+	// ManageSystem adalah permission administrator sistem, dipakai juga untuk impersonation
+	ManageSystem = "manage:system"
+)
+
+type (
+	Permission struct {
+		Name        string   `yaml:"name" json:"name"`
+		Description string   `yaml:"description" json:"description"`
+		Implies     []string `yaml:"implies" json:"implies,omitempty"`
+	}
+
+	Group struct {
+		Name        string       `yaml:"name" json:"name"`
+		Description string       `yaml:"description" json:"description"`
+		Permissions []Permission `yaml:"permissions" json:"permissions"`
+	}
+
+	// Catalog berisi seluruh permission yang dikenal aplikasi beserta permission default
+	Catalog struct {
+		groups   []Group
+		byName   map[string]Permission
+		names    []string
+		defaults []string
+	}
+
+	catalogFile struct {
+		Groups   []Group  `yaml:"groups"`
+		Defaults []string `yaml:"default_permission"`
+	}
+)
+
+// LoadCatalog membaca katalog permission dari file YAML
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file catalogFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	return NewCatalog(file.Groups, file.Defaults)
+}
+
+// NewCatalog membuat katalog dan memastikan setiap nama, implies dan default permission valid
+func NewCatalog(groups []Group, defaults []string) (*Catalog, error) {
+	c := &Catalog{
+		groups: groups,
+		byName: make(map[string]Permission),
+	}
+
+	for _, group := range groups {
+		for _, p := range group.Permissions {
+			if isPattern(p.Name) || !strings.Contains(p.Name, ":") {
+				return nil, fmt.Errorf("invalid permission name %q, expected <resource>:<action>", p.Name)
+			}
+			if _, ok := c.byName[p.Name]; ok {
+				return nil, fmt.Errorf("duplicate permission %q", p.Name)
+			}
+			c.byName[p.Name] = p
+			c.names = append(c.names, p.Name)
+		}
+	}
+
+	for _, p := range c.byName {
+		if invalid := c.Validate(p.Implies); len(invalid) > 0 {
+			return nil, fmt.Errorf("permission %s implies unknown permissions: %v", p.Name, invalid)
+		}
+	}
+
+	if invalid := c.Validate(defaults); len(invalid) > 0 {
+		return nil, fmt.Errorf("unknown default permissions: %v", invalid)
+	}
+	c.defaults = defaults
+
+	return c, nil
+}
+
+// Groups mengembalikan katalog per grup, dipakai untuk menampilkan checkbox permission
+func (c *Catalog) Groups() []Group {
+	return c.groups
+}
+
+// Defaults mengembalikan permission yang dimiliki setiap user yang login
+func (c *Catalog) Defaults() []string {
+	return c.defaults
+}
+
+// Lookup mencari permission katalog berdasarkan nama
+func (c *Catalog) Lookup(name string) (Permission, bool) {
+	p, ok := c.byName[name]
+	return p, ok
+}
+
+// Validate mengembalikan grant yang tidak dikenal. Grant valid jika ada di katalog
+// atau merupakan wildcard yang cocok dengan minimal satu permission.
+func (c *Catalog) Validate(grants []string) []string {
+	var invalid []string
+	for _, grant := range grants {
+		if _, ok := c.byName[grant]; ok {
+			continue
+		}
+
+		if isPattern(grant) && c.matchesAny(grant) {
+			continue
+		}
+
+		invalid = append(invalid, grant)
+	}
+	return invalid
+}
+
+// Resolve menghitung permission efektif dari grant milik user, termasuk permission default
+func (c *Catalog) Resolve(grants []string) Set {
+	return c.Expand(append(append([]string{}, c.defaults...), grants...))
+}
+
+// Expand mengekspansi wildcard ke permission katalog yang cocok dan mengikuti implies secara transitif
+func (c *Catalog) Expand(grants []string) Set {
+	set := make(Set)
+
+	queue := append([]string{}, grants...)
+	for len(queue) > 0 {
+		grant := queue[0]
+		queue = queue[1:]
+
+		if _, ok := set[grant]; ok {
+			continue
+		}
+		set[grant] = struct{}{}
+
+		if isPattern(grant) {
+			for _, name := range c.names {
+				if Match(grant, name) {
+					queue = append(queue, name)
+				}
+			}
+			continue
+		}
+
+		if p, ok := c.byName[grant]; ok {
+			queue = append(queue, p.Implies...)
+		}
+	}
+
+	return set
+}
+
+func (c *Catalog) matchesAny(pattern string) bool {
+	for _, name := range c.names {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match mencocokkan pola <resource>:<action> dengan permission, setiap segmen boleh berupa "*".
+// Pola "*" saja setara dengan "*:*".
+func Match(pattern string, permission string) bool {
+	if pattern == Wildcard {
+		return true
+	}
+
+	patternResource, patternAction, ok := strings.Cut(pattern, ":")
+	if !ok {
+		return false
+	}
+	resource, action, ok := strings.Cut(permission, ":")
+	if !ok {
+		return false
+	}
+
+	return (patternResource == Wildcard || patternResource == resource) &&
+		(patternAction == Wildcard || patternAction == action)
+}
+
+func isPattern(grant string) bool {
+	return strings.Contains(grant, Wildcard)
+}
@@ -0,0 +1,202 @@
+package permission
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+
+	catalog, err := NewCatalog([]Group{
+		{
+			Name: "users",
+			Permissions: []Permission{
+				{Name: "users:read"},
+				{Name: "users:update", Implies: []string{"users:read"}},
+				{Name: "users:delete", Implies: []string{"users:update"}},
+			},
+		},
+		{
+			Name: "roles",
+			Permissions: []Permission{
+				{Name: "roles:read"},
+				{Name: "roles:update", Implies: []string{"roles:read", "users:read"}},
+			},
+		},
+		{
+			Name: "system",
+			Permissions: []Permission{
+				{Name: ManageSystem, Implies: []string{Wildcard}},
+			},
+		},
+	}, []string{"users:read"})
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	return catalog
+}
+
+func TestNewCatalog(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   []Group
+		defaults []string
+		wantErr  bool
+	}{
+		{
+			name:   "valid catalog",
+			groups: []Group{{Name: "users", Permissions: []Permission{{Name: "users:read"}, {Name: "users:update", Implies: []string{"users:*"}}}}},
+		},
+		{
+			name:    "name without action",
+			groups:  []Group{{Name: "users", Permissions: []Permission{{Name: "users"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "wildcard name",
+			groups:  []Group{{Name: "users", Permissions: []Permission{{Name: "users:*"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			groups:  []Group{{Name: "users", Permissions: []Permission{{Name: "users:read"}}}, {Name: "other", Permissions: []Permission{{Name: "users:read"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown implies",
+			groups:  []Group{{Name: "users", Permissions: []Permission{{Name: "users:update", Implies: []string{"users:read"}}}}},
+			wantErr: true,
+		},
+		{
+			name:    "implies wildcard without match",
+			groups:  []Group{{Name: "users", Permissions: []Permission{{Name: "users:update", Implies: []string{"roles:*"}}}}},
+			wantErr: true,
+		},
+		{
+			name:     "unknown default",
+			groups:   []Group{{Name: "users", Permissions: []Permission{{Name: "users:read"}}}},
+			defaults: []string{"roles:read"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCatalog(tt.groups, tt.defaults)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewCatalog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	got := catalog.Validate([]string{"users:read", "users:*", "*:read", "*", "reports:*", "users:export", "users"})
+	want := []string{"reports:*", "users:export", "users"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+}
+
+func TestCatalogExpand(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	tests := []struct {
+		name   string
+		grants []string
+		want   []string
+	}{
+		{
+			name:   "direct implies",
+			grants: []string{"users:update"},
+			want:   []string{"users:read", "users:update"},
+		},
+		{
+			name:   "transitive implies",
+			grants: []string{"users:delete"},
+			want:   []string{"users:delete", "users:read", "users:update"},
+		},
+		{
+			name:   "implies across resources",
+			grants: []string{"roles:update"},
+			want:   []string{"roles:read", "roles:update", "users:read"},
+		},
+		{
+			name:   "wildcard expands to catalog permissions",
+			grants: []string{"*:read"},
+			want:   []string{"*:read", "roles:read", "users:read"},
+		},
+		{
+			name:   "manage system implies everything",
+			grants: []string{ManageSystem},
+			want:   []string{"*", ManageSystem, "roles:read", "roles:update", "users:delete", "users:read", "users:update"},
+		},
+		{
+			name:   "unknown grant is kept without implies",
+			grants: []string{"reports:read"},
+			want:   []string{"reports:read"},
+		},
+		{
+			name: "no grants",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := catalog.Expand(tt.grants).List()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogResolveIncludesDefaults(t *testing.T) {
+	catalog := newTestCatalog(t)
+
+	got := catalog.Resolve([]string{"roles:read"}).List()
+	want := []string{"roles:read", "users:read"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+
+	// Resolve tidak boleh mengubah slice default milik katalog
+	if strings.Join(catalog.Defaults(), ",") != "users:read" {
+		t.Errorf("Defaults() = %v, want [users:read]", catalog.Defaults())
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	// Katalog bawaan aplikasi harus selalu valid dan manage:system harus mencakup seluruh permission
+	catalog, err := LoadCatalog(filepath.Join("..", "constant", "data.yaml"))
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+
+	system := catalog.Expand([]string{ManageSystem})
+	for _, group := range catalog.Groups() {
+		for _, p := range group.Permissions {
+			if !system.Has(p.Name) {
+				t.Errorf("manage:system does not cover %s", p.Name)
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	if err := os.WriteFile(path, []byte("groups:\n  - name: users\n    permissions:\n      - name: users\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadCatalog(path); err == nil {
+		t.Errorf("LoadCatalog() error = nil, want invalid permission name")
+	}
+
+	if _, err := LoadCatalog(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("LoadCatalog() error = nil, want missing file")
+	}
+}
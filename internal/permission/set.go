@@ -0,0 +1,67 @@
+package permission
+
+import "sort"
+
+// Set adalah kumpulan permission efektif milik user, hasil dari Catalog.Resolve
+type Set map[string]struct{}
+
+// Has bernilai true jika permission dimiliki secara langsung atau lewat wildcard
+func (s Set) Has(permission string) bool {
+	if _, ok := s[permission]; ok {
+		return true
+	}
+
+	for grant := range s {
+		if isPattern(grant) && Match(grant, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny bernilai true jika salah satu permission dimiliki
+func (s Set) HasAny(permissions ...string) bool {
+	for _, permission := range permissions {
+		if s.Has(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAll bernilai true jika seluruh permission dimiliki
+func (s Set) HasAll(permissions ...string) bool {
+	for _, permission := range permissions {
+		if !s.Has(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect mengembalikan permission yang dimiliki kedua set. Wildcard hanya ikut
+// jika seluruh cakupannya juga dimiliki set lainnya.
+func Intersect(a Set, b Set) Set {
+	set := make(Set)
+	for permission := range a {
+		if b.Has(permission) {
+			set[permission] = struct{}{}
+		}
+	}
+	for permission := range b {
+		if a.Has(permission) {
+			set[permission] = struct{}{}
+		}
+	}
+	return set
+}
+
+// List mengembalikan permission dalam urutan alfabet
+func (s Set) List() []string {
+	list := make([]string, 0, len(s))
+	for permission := range s {
+		list = append(list, permission)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,155 @@
+package permission
+
+import (
+	"strings"
+	"testing"
+)
+
+func newSet(permissions ...string) Set {
+	set := make(Set)
+	for _, permission := range permissions {
+		set[permission] = struct{}{}
+	}
+	return set
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		permission string
+		want       bool
+	}{
+		{pattern: "*", permission: "users:read", want: true},
+		{pattern: "*:*", permission: "users:read", want: true},
+		{pattern: "users:*", permission: "users:read", want: true},
+		{pattern: "users:*", permission: "roles:read", want: false},
+		{pattern: "*:read", permission: "roles:read", want: true},
+		{pattern: "*:read", permission: "roles:update", want: false},
+		{pattern: "users:read", permission: "users:read", want: true},
+		{pattern: "users:read", permission: "users:update", want: false},
+		{pattern: "users", permission: "users:read", want: false},
+		{pattern: "users:*", permission: "users", want: false},
+		// Wildcard hanya berlaku per segmen, bukan prefix
+		{pattern: "user*:read", permission: "users:read", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.permission, func(t *testing.T) {
+			if got := Match(tt.pattern, tt.permission); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetHas(t *testing.T) {
+	tests := []struct {
+		name       string
+		set        Set
+		permission string
+		want       bool
+	}{
+		{name: "exact", set: newSet("users:read"), permission: "users:read", want: true},
+		{name: "missing", set: newSet("users:read"), permission: "users:update", want: false},
+		{name: "resource wildcard", set: newSet("users:*"), permission: "users:delete", want: true},
+		{name: "action wildcard", set: newSet("*:read"), permission: "roles:read", want: true},
+		{name: "action wildcard other action", set: newSet("*:read"), permission: "roles:update", want: false},
+		{name: "full wildcard", set: newSet("*"), permission: "manage:system", want: true},
+		{name: "wildcard is held exactly", set: newSet("users:*"), permission: "users:*", want: true},
+		{name: "narrower grant does not cover a wildcard", set: newSet("users:read"), permission: "users:*", want: false},
+		{name: "wider wildcard covers a narrower wildcard", set: newSet("*"), permission: "users:*", want: true},
+		{name: "empty set", set: newSet(), permission: "users:read", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.set.Has(tt.permission); got != tt.want {
+				t.Errorf("Has(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetHasAnyHasAll(t *testing.T) {
+	set := newSet("users:*", "roles:read")
+
+	if !set.HasAny("reports:read", "roles:read") {
+		t.Errorf("HasAny() = false, want true")
+	}
+	if set.HasAny("reports:read", "roles:update") {
+		t.Errorf("HasAny() = true, want false")
+	}
+	if !set.HasAll("users:read", "users:delete", "roles:read") {
+		t.Errorf("HasAll() = false, want true")
+	}
+	if set.HasAll("users:read", "roles:update") {
+		t.Errorf("HasAll() = true, want false")
+	}
+	if !set.HasAll() || set.HasAny() {
+		t.Errorf("HasAll() and HasAny() without permissions = %v, %v, want true, false", set.HasAll(), set.HasAny())
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Set
+		b    Set
+		want []string
+	}{
+		{
+			name: "common permissions",
+			a:    newSet("users:read", "users:update"),
+			b:    newSet("users:read", "roles:read"),
+			want: []string{"users:read"},
+		},
+		{
+			name: "wildcard narrowed by the other set",
+			a:    newSet("users:*"),
+			b:    newSet("users:read", "roles:read"),
+			want: []string{"users:read"},
+		},
+		{
+			// users:* tidak ikut karena cakupannya lebih luas dari users:read
+			name: "wildcard wider than the other set is dropped",
+			a:    newSet("users:*"),
+			b:    newSet("*:read"),
+			want: []string{},
+		},
+		{
+			name: "full wildcard keeps the other set",
+			a:    newSet("*"),
+			b:    newSet("users:*", "roles:read"),
+			want: []string{"roles:read", "users:*"},
+		},
+		{
+			name: "empty set",
+			a:    newSet(),
+			b:    newSet("*"),
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Intersect(tt.a, tt.b).List()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Intersect() = %v, want %v", got, tt.want)
+			}
+
+			// Urutan argumen tidak berpengaruh
+			reversed := Intersect(tt.b, tt.a).List()
+			if strings.Join(reversed, ",") != strings.Join(got, ",") {
+				t.Errorf("Intersect() reversed = %v, want %v", reversed, got)
+			}
+		})
+	}
+}
+
+func TestSetList(t *testing.T) {
+	got := newSet("users:read", "*", "roles:update", "roles:read").List()
+	want := []string{"*", "roles:read", "roles:update", "users:read"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type APIKeyRepository struct {
+	coll *mongo.Collection
+}
+
+func NewAPIKeyRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *APIKeyRepository {
+	return &APIKeyRepository{
+		coll: mongoDB.Collection("api_keys"),
+	}
+}
+
+// EnsureIndexes membuat unique index prefix yang dipakai saat autentikasi dan index owner untuk daftar key
+func (a *APIKeyRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := a.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "prefix", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (a *APIKeyRepository) Create(ctx context.Context, key *account.APIKey) error {
+	_, err := a.coll.InsertOne(ctx, key)
+	if err != nil {
+		return errs.Internal("failed to create data", err)
+	}
+	return nil
+}
+
+func (a *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*account.APIKey, error) {
+	var key account.APIKey
+
+	err := a.coll.FindOne(ctx, bson.M{"prefix": prefix}).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &account.APIKey{}, errs.NotFound("api key not found", err)
+		}
+
+		return &account.APIKey{}, errs.Internal("failed to find api key", err)
+	}
+
+	return &key, nil
+}
+
+// FindByOwner mengambil seluruh key milik user, termasuk yang sudah dicabut, dari yang terbaru
+func (a *APIKeyRepository) FindByOwner(ctx context.Context, ownerID string) (*[]account.APIKey, error) {
+	keys := []account.APIKey{}
+
+	objectID, err := bson.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return &keys, errs.BadRequest("invalid ID format", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := a.coll.Find(ctx, bson.M{"owner_id": objectID}, opts)
+	if err != nil {
+		return &keys, errs.Internal("failed to fetch data", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &keys); err != nil {
+		return &keys, errs.Internal("failed to decode api keys", err)
+	}
+
+	return &keys, nil
+}
+
+// Revoke mencabut key milik owner, false jika key tidak ditemukan atau sudah dicabut
+func (a *APIKeyRepository) Revoke(ctx context.Context, ownerID string, id string) (bool, error) {
+	objectOwnerID, err := bson.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{
+		"_id":        objectID,
+		"owner_id":   objectOwnerID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	result, err := a.coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	})
+	if err != nil {
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (a *APIKeyRepository) UpdateLastUsed(ctx context.Context, id bson.ObjectID, usedAt time.Time, ip string) error {
+	_, err := a.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"last_used_at": usedAt,
+			"last_used_ip": ip,
+		}})
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+	return nil
+}
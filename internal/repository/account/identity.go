@@ -0,0 +1,109 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type IdentityRepository struct {
+	coll *mongo.Collection
+}
+
+func NewIdentityRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *IdentityRepository {
+	return &IdentityRepository{
+		coll: mongoDB.Collection("user_identities"),
+	}
+}
+
+// EnsureIndexes membuat unique index issuer + subject agar satu akun provider hanya bisa tertaut ke satu user
+func (i *IdentityRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := i.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "issuer", Value: 1}, {Key: "subject", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	return err
+}
+
+// Create menyimpan identity, mengembalikan Conflict jika issuer + subject sudah tertaut
+func (i *IdentityRepository) Create(ctx context.Context, identity *account.UserIdentity) error {
+	_, err := i.coll.InsertOne(ctx, identity)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errs.Conflict("identity already linked", err)
+		}
+		return errs.Internal("failed to create data", err)
+	}
+	return nil
+}
+
+func (i *IdentityRepository) FindBySubject(ctx context.Context, issuer string, subject string) (*account.UserIdentity, error) {
+	var identity account.UserIdentity
+
+	err := i.coll.FindOne(ctx, bson.M{"issuer": issuer, "subject": subject}).Decode(&identity)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &account.UserIdentity{}, errs.NotFound("identity not found", err)
+		}
+
+		return &account.UserIdentity{}, errs.Internal("failed to find identity", err)
+	}
+
+	return &identity, nil
+}
+
+func (i *IdentityRepository) FindByUser(ctx context.Context, userID string) (*[]account.UserIdentity, error) {
+	identities := []account.UserIdentity{}
+
+	objectID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &identities, errs.BadRequest("invalid ID format", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := i.coll.Find(ctx, bson.M{"user_id": objectID}, opts)
+	if err != nil {
+		return &identities, errs.Internal("failed to fetch data", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &identities); err != nil {
+		return &identities, errs.Internal("failed to decode identities", err)
+	}
+
+	return &identities, nil
+}
+
+func (i *IdentityRepository) UpdateLastLogin(ctx context.Context, id bson.ObjectID, at time.Time) error {
+	_, err := i.coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_login_at": at}})
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+	return nil
+}
+
+// Delete menghapus identity milik user, false jika tidak ditemukan
+func (i *IdentityRepository) Delete(ctx context.Context, userID string, id string) (bool, error) {
+	objectUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	result, err := i.coll.DeleteOne(ctx, bson.M{"_id": objectID, "user_id": objectUserID})
+	if err != nil {
+		return false, errs.Internal("failed to delete data", err)
+	}
+
+	return result.DeletedCount == 1, nil
+}
@@ -0,0 +1,182 @@
+package account
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type InvitationRepository struct {
+	coll *mongo.Collection
+}
+
+func NewInvitationRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *InvitationRepository {
+	return &InvitationRepository{
+		coll: mongoDB.Collection("invitations"),
+	}
+}
+
+// EnsureIndexes membuat unique index hash token yang dipakai saat accept dan index pencarian undangan pending per email
+func (i *InvitationRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := i.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "status", Value: 1}}},
+	})
+	return err
+}
+
+func (i *InvitationRepository) Create(ctx context.Context, invitation *account.Invitation) error {
+	_, err := i.coll.InsertOne(ctx, invitation)
+	if err != nil {
+		return errs.Internal("failed to create data", err)
+	}
+	return nil
+}
+
+func (i *InvitationRepository) FindById(ctx context.Context, id string) (*account.Invitation, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return &account.Invitation{}, errs.BadRequest("invalid ID format", err)
+	}
+
+	return i.findOne(ctx, bson.M{"_id": objectID})
+}
+
+// FindPendingByEmail mencari undangan yang masih pending dan belum kedaluwarsa untuk email tertentu
+func (i *InvitationRepository) FindPendingByEmail(ctx context.Context, email string) (*account.Invitation, error) {
+	return i.findOne(ctx, bson.M{
+		"email":      email,
+		"status":     account.InvitationPending,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+}
+
+// FindPendingByTokenHash mencari undangan yang masih pending dan belum kedaluwarsa berdasarkan hash token
+func (i *InvitationRepository) FindPendingByTokenHash(ctx context.Context, tokenHash string) (*account.Invitation, error) {
+	return i.findOne(ctx, bson.M{
+		"token_hash": tokenHash,
+		"status":     account.InvitationPending,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+}
+
+func (i *InvitationRepository) FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Invitation, int, error) {
+	var invitations []account.Invitation
+
+	opts := options.Find().
+		SetSkip(int64((filter.Page - 1) * filter.Limit)).
+		SetLimit(int64(filter.Limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	query := bson.M{}
+	if filter.Search != "" {
+		query["email"] = bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
+	}
+
+	cursor, err := i.coll.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, errs.Internal("failed to fetch data", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, 0, errs.Internal("failed to decode invitations", err)
+	}
+
+	totalItems, err := i.coll.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, errs.Internal("failed to count invitations", err)
+	}
+
+	return &invitations, int(totalItems), nil
+}
+
+// UpdateToken mengganti token undangan yang masih pending sehingga link lama tidak berlaku lagi
+func (i *InvitationRepository) UpdateToken(ctx context.Context, id string, tokenHash string, expiresAt time.Time) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{"_id": objectID, "status": account.InvitationPending}
+	result, err := i.coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"token_hash": tokenHash,
+			"expires_at": expiresAt,
+			"updated_at": time.Now(),
+		}})
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+	if result.MatchedCount == 0 {
+		return errs.BadRequest("invitation is no longer pending", nil)
+	}
+
+	return nil
+}
+
+// UpdateStatus memindahkan undangan dari pending ke status lain, false jika undangan sudah tidak pending
+func (i *InvitationRepository) UpdateStatus(ctx context.Context, id string, status string) (bool, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	set := bson.M{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+	if status == account.InvitationAccepted {
+		set["accepted_at"] = time.Now()
+	}
+
+	filter := bson.M{"_id": objectID, "status": account.InvitationPending}
+	result, err := i.coll.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// Reopen mengembalikan undangan yang sudah diklaim ke pending, dipakai ketika pembuatan user setelah klaim gagal
+func (i *InvitationRepository) Reopen(ctx context.Context, id string) error {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{"_id": objectID, "status": account.InvitationAccepted}
+	_, err = i.coll.UpdateOne(ctx, filter, bson.M{
+		"$set":   bson.M{"status": account.InvitationPending, "updated_at": time.Now()},
+		"$unset": bson.M{"accepted_at": ""},
+	})
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+
+	return nil
+}
+
+func (i *InvitationRepository) findOne(ctx context.Context, filter bson.M) (*account.Invitation, error) {
+	var invitation account.Invitation
+
+	err := i.coll.FindOne(ctx, filter).Decode(&invitation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &account.Invitation{}, errs.NotFound("invitation not found", err)
+		}
+
+		return &account.Invitation{}, errs.Internal("failed to find invitation", err)
+	}
+
+	return &invitation, nil
+}
@@ -0,0 +1,72 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	permissionInvalidationChannel = "permission:invalidate"
+
+	// InvalidateAllPermissions dikirim sebagai pesan ketika seluruh cache permission harus dibuang
+	InvalidateAllPermissions = "*"
+)
+
+// PermissionInvalidationRepository menyebarkan invalidasi cache permission ke seluruh instance lewat Redis pub/sub
+type PermissionInvalidationRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewPermissionInvalidationRepository(redisClient *redis.Client, logger *zerolog.Logger) *PermissionInvalidationRepository {
+	return &PermissionInvalidationRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+// Publish mengirim ID user (atau InvalidateAllPermissions) yang cache permission-nya harus dibuang
+func (p *PermissionInvalidationRepository) Publish(ctx context.Context, userID string) error {
+	if err := p.redis.Publish(ctx, permissionInvalidationChannel, userID).Err(); err != nil {
+		return errs.Internal("failed to publish permission invalidation", err)
+	}
+	return nil
+}
+
+// Subscribe memanggil handler untuk setiap pesan invalidasi sampai ctx dibatalkan.
+// Pesan yang terlewat saat koneksi terputus tidak bisa diambil ulang, sehingga setiap kali
+// subscription (kembali) aktif handler dipanggil dengan InvalidateAllPermissions.
+func (p *PermissionInvalidationRepository) Subscribe(ctx context.Context, handler func(userID string)) {
+	pubsub := p.redis.Subscribe(ctx, permissionInvalidationChannel)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			p.logger.Warn().Err(err).Msg("permission invalidation subscription interrupted")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				handler(InvalidateAllPermissions)
+			}
+		case *redis.Message:
+			handler(m.Payload)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"github.com/HasanNugroho/golang-starter/internal/model"
 	"github.com/HasanNugroho/golang-starter/internal/model/account"
@@ -9,18 +10,42 @@ import (
 )
 
 type (
+	// IIndexedRepository diimplementasikan repository MongoDB yang membutuhkan index, dipanggil sekali saat startup
+	IIndexedRepository interface {
+		EnsureIndexes(ctx context.Context) error
+	}
+
 	IUserRepository interface {
 		Create(ctx context.Context, user *account.User) error
 		FindByEmail(ctx context.Context, email string) (*account.User, error)
 		FindById(ctx context.Context, id string) (*account.User, error)
 		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.User, int, error)
 		Update(ctx context.Context, id string, user *account.User) error
+		ConfirmEmail(ctx context.Context, id string, email string) (bool, error)
+		UpdateLock(ctx context.Context, id string, lockedUntil *time.Time) error
+		UpdateDisabled(ctx context.Context, id string, disabled bool) error
+		UpdateMFA(ctx context.Context, id string, secret string, enabled bool) error
+		UpdateRecoveryCodes(ctx context.Context, id string, hashes []string) error
+		UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+		ConsumeRecoveryCode(ctx context.Context, id string, hash string) (bool, error)
 		Delete(ctx context.Context, id string) error
 	}
 
+	IInvitationRepository interface {
+		Create(ctx context.Context, invitation *account.Invitation) error
+		FindById(ctx context.Context, id string) (*account.Invitation, error)
+		FindPendingByEmail(ctx context.Context, email string) (*account.Invitation, error)
+		FindPendingByTokenHash(ctx context.Context, tokenHash string) (*account.Invitation, error)
+		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Invitation, int, error)
+		UpdateToken(ctx context.Context, id string, tokenHash string, expiresAt time.Time) error
+		UpdateStatus(ctx context.Context, id string, status string) (bool, error)
+		Reopen(ctx context.Context, id string) error
+	}
+
 	IRoleRepository interface {
 		Create(ctx context.Context, role *account.Role) error
 		FindById(ctx context.Context, id string) (*account.Role, error)
+		FindByName(ctx context.Context, name string) (*account.Role, error)
 		FindManyByID(ctx context.Context, ids []bson.ObjectID) (*[]account.Role, error)
 		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Role, int, error)
 		Update(ctx context.Context, id string, role *account.Role) error
@@ -28,4 +53,25 @@ type (
 		AssignUser(ctx context.Context, userId string, roleId string) error
 		UnassignUser(ctx context.Context, userId string, roleId string) error
 	}
+
+	IAPIKeyRepository interface {
+		Create(ctx context.Context, key *account.APIKey) error
+		FindByPrefix(ctx context.Context, prefix string) (*account.APIKey, error)
+		FindByOwner(ctx context.Context, ownerID string) (*[]account.APIKey, error)
+		Revoke(ctx context.Context, ownerID string, id string) (bool, error)
+		UpdateLastUsed(ctx context.Context, id bson.ObjectID, usedAt time.Time, ip string) error
+	}
+
+	IIdentityRepository interface {
+		Create(ctx context.Context, identity *account.UserIdentity) error
+		FindBySubject(ctx context.Context, issuer string, subject string) (*account.UserIdentity, error)
+		FindByUser(ctx context.Context, userID string) (*[]account.UserIdentity, error)
+		UpdateLastLogin(ctx context.Context, id bson.ObjectID, at time.Time) error
+		Delete(ctx context.Context, userID string, id string) (bool, error)
+	}
+
+	IPermissionInvalidationRepository interface {
+		Publish(ctx context.Context, userID string) error
+		Subscribe(ctx context.Context, handler func(userID string))
+	}
 )
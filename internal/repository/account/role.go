@@ -51,6 +51,22 @@ func (r *RoleRepository) FindById(ctx context.Context, id string) (*account.Role
 	return &role, nil
 }
 
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*account.Role, error) {
+	var role account.Role
+
+	filter := bson.M{"name": name}
+	err := r.coll.FindOne(ctx, filter).Decode(&role)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &account.Role{}, errs.NotFound("data not found", err)
+		}
+
+		return &account.Role{}, errs.Internal("failed to find data", err)
+	}
+
+	return &role, nil
+}
+
 func (r *RoleRepository) FindManyByID(ctx context.Context, ids []bson.ObjectID) (*[]account.Role, error) {
 	var roles []account.Role
 
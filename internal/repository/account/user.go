@@ -25,8 +25,20 @@ func NewUserRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *UserRep
 	}
 }
 
+// EnsureIndexes membuat unique index email, pengecekan email di service saja tidak cukup untuk request yang berjalan bersamaan
+func (u *UserRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := u.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
 func (u *UserRepository) Create(ctx context.Context, user *account.User) error {
 	_, err := u.coll.InsertOne(ctx, &user)
+	if mongo.IsDuplicateKeyError(err) {
+		return errs.BadRequest("email exist", err)
+	}
 	return err
 }
 
@@ -101,10 +113,139 @@ func (u *UserRepository) Update(ctx context.Context, id string, user *account.Us
 	filter := bson.M{"_id": objectId}
 	err = u.coll.FindOneAndUpdate(ctx, filter, bson.M{
 		"$set": bson.M{
-			"name":       user.Name,
-			"email":      user.Email,
-			"password":   user.Password,
-			"updated_at": time.Now(),
+			"name":                user.Name,
+			"email":               user.Email,
+			"pending_email":       user.PendingEmail,
+			"password":            user.Password,
+			"password_history":    user.PasswordHistory,
+			"password_changed_at": user.PasswordChangedAt,
+			"updated_at":          time.Now(),
+		}}).Err()
+
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errs.BadRequest("email exist", err)
+		}
+		return errs.Internal("failed to update data", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmail menandai email terverifikasi, baik email utama yang belum diverifikasi maupun pending email.
+// Mengembalikan false jika email tersebut tidak sedang menunggu verifikasi.
+func (u *UserRepository) ConfirmEmail(ctx context.Context, id string, email string) (bool, error) {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{
+		"_id": objectId,
+		"$or": bson.A{
+			bson.M{"pending_email": email},
+			bson.M{"email": email, "email_verified": bson.M{"$ne": true}},
+		},
+	}
+	result, err := u.coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"email":          email,
+			"email_verified": true,
+			"verified_at":    time.Now(),
+			"updated_at":     time.Now(),
+		},
+		"$unset": bson.M{"pending_email": ""},
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, errs.BadRequest("email exist", err)
+		}
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// UpdateDisabled menonaktifkan atau mengaktifkan kembali akun
+func (u *UserRepository) UpdateDisabled(ctx context.Context, id string, disabled bool) error {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	result, err := u.coll.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{
+		"$set": bson.M{"disabled": disabled, "updated_at": time.Now()},
+	})
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+	if result.MatchedCount == 0 {
+		return errs.NotFound("not found", nil)
+	}
+
+	return nil
+}
+
+// UpdateLock mengunci akun sampai lockedUntil, atau membuka kunci jika lockedUntil nil
+func (u *UserRepository) UpdateLock(ctx context.Context, id string, lockedUntil *time.Time) error {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"locked_until": lockedUntil, "updated_at": time.Now()},
+	}
+	if lockedUntil == nil {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": time.Now()},
+			"$unset": bson.M{"locked_until": ""},
+		}
+	}
+
+	result, err := u.coll.UpdateOne(ctx, bson.M{"_id": objectId}, update)
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+	if result.MatchedCount == 0 {
+		return errs.NotFound("not found", nil)
+	}
+
+	return nil
+}
+
+func (u *UserRepository) UpdateMFA(ctx context.Context, id string, secret string, enabled bool) error {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{"_id": objectId}
+	err = u.coll.FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{
+			"totp_secret":  secret,
+			"totp_enabled": enabled,
+			"updated_at":   time.Now(),
+		}}).Err()
+
+	if err != nil {
+		return errs.Internal("failed to update data", err)
+	}
+
+	return nil
+}
+
+func (u *UserRepository) UpdateRecoveryCodes(ctx context.Context, id string, hashes []string) error {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{"_id": objectId}
+	err = u.coll.FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{
+			"recovery_codes": hashes,
+			"updated_at":     time.Now(),
 		}}).Err()
 
 	if err != nil {
@@ -114,6 +255,49 @@ func (u *UserRepository) Update(ctx context.Context, id string, user *account.Us
 	return nil
 }
 
+// UseTOTPStep menyimpan time step TOTP yang diterima secara atomik, false jika step tersebut atau yang lebih baru sudah dipakai
+func (u *UserRepository) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{
+		"_id": objectId,
+		"$or": bson.A{
+			bson.M{"totp_last_step": bson.M{"$exists": false}},
+			bson.M{"totp_last_step": bson.M{"$lt": step}},
+		},
+	}
+	result, err := u.coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"totp_last_step": step},
+	})
+	if err != nil {
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// ConsumeRecoveryCode menghapus hash kode pemulihan secara atomik, false jika kode sudah terpakai
+func (u *UserRepository) ConsumeRecoveryCode(ctx context.Context, id string, hash string) (bool, error) {
+	objectId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	filter := bson.M{"_id": objectId, "recovery_codes": hash}
+	result, err := u.coll.UpdateOne(ctx, filter, bson.M{
+		"$pull": bson.M{"recovery_codes": hash},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+	if err != nil {
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
 func (u *UserRepository) Delete(ctx context.Context, id string) error {
 	objectId, err := bson.ObjectIDFromHex(id)
 	if err != nil {
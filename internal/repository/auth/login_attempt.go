@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const loginAttemptPrefix = "loginattempt:"
+
+// LoginAttemptRepository mencatat login gagal dalam sliding window memakai sorted set Redis,
+// score setiap member adalah waktu percobaan dalam milidetik
+type LoginAttemptRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewLoginAttemptRepository(redisClient *redis.Client, logger *zerolog.Logger) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+// RecordFailure menambah satu percobaan gagal dan mengembalikan jumlah percobaan di dalam window
+func (l *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	redisKey := loginAttemptPrefix + key
+	now := time.Now()
+
+	suffix, err := helper.GenerateRandomString(4)
+	if err != nil {
+		return 0, errs.Internal("failed to record login attempt", err)
+	}
+	member := strconv.FormatInt(now.UnixNano(), 10) + "-" + suffix
+
+	pipe := l.redis.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", strconv.FormatInt(now.Add(-window).UnixMilli(), 10))
+	pipe.ZAdd(ctx, redisKey, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	count := pipe.ZCard(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, errs.Internal("failed to record login attempt", err)
+	}
+
+	return count.Val(), nil
+}
+
+// CountFailures menghitung percobaan gagal di dalam window
+func (l *LoginAttemptRepository) CountFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	min := strconv.FormatInt(time.Now().Add(-window).UnixMilli(), 10)
+
+	count, err := l.redis.ZCount(ctx, loginAttemptPrefix+key, "("+min, "+inf").Result()
+	if err != nil {
+		return 0, errs.Internal("failed to count login attempts", err)
+	}
+
+	return count, nil
+}
+
+func (l *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	if err := l.redis.Del(ctx, loginAttemptPrefix+key).Err(); err != nil {
+		return errs.Internal("failed to reset login attempts", err)
+	}
+	return nil
+}
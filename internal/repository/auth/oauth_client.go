@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OAuthClientRepository struct {
+	coll *mongo.Collection
+}
+
+func NewOAuthClientRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		coll: mongoDB.Collection("oauth_clients"),
+	}
+}
+
+func (o *OAuthClientRepository) Create(ctx context.Context, client *auth.OAuthClient) error {
+	_, err := o.coll.InsertOne(ctx, client)
+	if err != nil {
+		return errs.Internal("failed to create data", err)
+	}
+	return nil
+}
+
+func (o *OAuthClientRepository) FindByClientID(ctx context.Context, clientID string) (*auth.OAuthClient, error) {
+	var client auth.OAuthClient
+
+	err := o.coll.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&client)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &auth.OAuthClient{}, errs.NotFound("oauth client not found", err)
+		}
+
+		return &auth.OAuthClient{}, errs.Internal("failed to find oauth client", err)
+	}
+
+	return &client, nil
+}
+
+// FindAll mengambil seluruh client, termasuk yang sudah dicabut, dari yang terbaru
+func (o *OAuthClientRepository) FindAll(ctx context.Context) (*[]auth.OAuthClient, error) {
+	clients := []auth.OAuthClient{}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := o.coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return &clients, errs.Internal("failed to fetch data", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &clients); err != nil {
+		return &clients, errs.Internal("failed to decode oauth clients", err)
+	}
+
+	return &clients, nil
+}
+
+// Revoke mencabut client berdasarkan client_id, false jika client tidak ditemukan atau sudah dicabut
+func (o *OAuthClientRepository) Revoke(ctx context.Context, clientID string) (bool, error) {
+	filter := bson.M{
+		"client_id":  clientID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	result, err := o.coll.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{"revoked_at": time.Now()},
+	})
+	if err != nil {
+		return false, errs.Internal("failed to update data", err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
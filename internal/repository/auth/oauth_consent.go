@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type OAuthConsentRepository struct {
+	coll *mongo.Collection
+}
+
+func NewOAuthConsentRepository(mongoDB *mongo.Database, logger *zerolog.Logger) *OAuthConsentRepository {
+	return &OAuthConsentRepository{
+		coll: mongoDB.Collection("oauth_consents"),
+	}
+}
+
+func (o *OAuthConsentRepository) Find(ctx context.Context, userID string, clientID string) (*auth.OAuthConsent, error) {
+	var consent auth.OAuthConsent
+
+	objectUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &auth.OAuthConsent{}, errs.BadRequest("invalid ID format", err)
+	}
+
+	err = o.coll.FindOne(ctx, bson.M{"user_id": objectUserID, "client_id": clientID}).Decode(&consent)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &auth.OAuthConsent{}, errs.NotFound("consent not found", err)
+		}
+
+		return &auth.OAuthConsent{}, errs.Internal("failed to find consent", err)
+	}
+
+	return &consent, nil
+}
+
+func (o *OAuthConsentRepository) FindByUser(ctx context.Context, userID string) (*[]auth.OAuthConsent, error) {
+	consents := []auth.OAuthConsent{}
+
+	objectUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return &consents, errs.BadRequest("invalid ID format", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+	cursor, err := o.coll.Find(ctx, bson.M{"user_id": objectUserID}, opts)
+	if err != nil {
+		return &consents, errs.Internal("failed to fetch data", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &consents); err != nil {
+		return &consents, errs.Internal("failed to decode consents", err)
+	}
+
+	return &consents, nil
+}
+
+// Grant menambahkan scope ke consent user untuk client, consent dibuat jika belum ada
+func (o *OAuthConsentRepository) Grant(ctx context.Context, userID string, clientID string, scopes []string) error {
+	objectUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return errs.BadRequest("invalid ID format", err)
+	}
+
+	now := time.Now()
+	_, err = o.coll.UpdateOne(ctx,
+		bson.M{"user_id": objectUserID, "client_id": clientID},
+		bson.M{
+			"$addToSet":    bson.M{"scopes": bson.M{"$each": scopes}},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"_id": bson.NewObjectID(), "created_at": now},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return errs.Internal("failed to save consent", err)
+	}
+	return nil
+}
+
+// Delete menghapus consent, false jika consent tidak ditemukan
+func (o *OAuthConsentRepository) Delete(ctx context.Context, userID string, clientID string) (bool, error) {
+	objectUserID, err := bson.ObjectIDFromHex(userID)
+	if err != nil {
+		return false, errs.BadRequest("invalid ID format", err)
+	}
+
+	result, err := o.coll.DeleteOne(ctx, bson.M{"user_id": objectUserID, "client_id": clientID})
+	if err != nil {
+		return false, errs.Internal("failed to delete data", err)
+	}
+
+	return result.DeletedCount == 1, nil
+}
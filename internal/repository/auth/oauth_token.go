@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	oauthCodePrefix        = "oauth:code:"
+	oauthTokenPrefix       = "oauth:token:"
+	oauthUsedRefreshPrefix = "oauth:usedrefresh:"
+	oauthGrantPrefix       = "oauth:grant:"
+	oauthClientGrantPrefix = "oauth:grants:client:"
+	oauthUserGrantPrefix   = "oauth:grants:user:"
+)
+
+// consumeRefreshTokenScript menghapus refresh token sekaligus mencatatnya sebagai token yang sudah dipakai,
+// sehingga request yang mengirim ulang token tersebut selalu terdeteksi sebagai reuse
+var consumeRefreshTokenScript = redis.NewScript(`
+if redis.call('DEL', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// OAuthTokenRepository menyimpan authorization code, token opaque dan grant OAuth2 di Redis.
+// Code dan token disimpan dengan key hash-nya, grant diindeks per client dan per user+client
+// agar seluruh token bisa dicabut ketika client dicabut atau consent ditarik.
+type OAuthTokenRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewOAuthTokenRepository(redisClient *redis.Client, logger *zerolog.Logger) *OAuthTokenRepository {
+	return &OAuthTokenRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+func (o *OAuthTokenRepository) SaveCode(ctx context.Context, code string, data *auth.OAuthAuthorizationCode, ttl time.Duration) error {
+	return o.setJSON(ctx, oauthCodePrefix+helper.HashToken(code), data, ttl, "failed to store authorization code")
+}
+
+func (o *OAuthTokenRepository) FindCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	payload, err := o.redis.Get(ctx, oauthCodePrefix+helper.HashToken(code)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.NotFound("authorization code not found", err)
+		}
+		return nil, errs.Internal("failed to find authorization code", err)
+	}
+
+	var data auth.OAuthAuthorizationCode
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, errs.Internal("failed to decode authorization code", err)
+	}
+	return &data, nil
+}
+
+// ConsumeCode mengambil sekaligus menghapus code sehingga code hanya bisa ditukar sekali
+func (o *OAuthTokenRepository) ConsumeCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error) {
+	var data auth.OAuthAuthorizationCode
+	if err := o.getDelJSON(ctx, oauthCodePrefix+helper.HashToken(code), &data, "authorization code"); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func (o *OAuthTokenRepository) SaveToken(ctx context.Context, token string, data *auth.OAuthToken, ttl time.Duration) error {
+	return o.setJSON(ctx, oauthTokenPrefix+helper.HashToken(token), data, ttl, "failed to store token")
+}
+
+func (o *OAuthTokenRepository) FindToken(ctx context.Context, token string) (*auth.OAuthToken, error) {
+	payload, err := o.redis.Get(ctx, oauthTokenPrefix+helper.HashToken(token)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.NotFound("token not found", err)
+		}
+		return nil, errs.Internal("failed to find token", err)
+	}
+
+	var data auth.OAuthToken
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, errs.Internal("failed to decode token", err)
+	}
+	return &data, nil
+}
+
+// ConsumeRefreshToken menghapus refresh token dan mencatatnya beserta grant-nya untuk deteksi reuse secara atomik.
+// Mengembalikan false jika token sudah dipakai oleh request lain.
+func (o *OAuthTokenRepository) ConsumeRefreshToken(ctx context.Context, token string, grantID string, ttl time.Duration) (bool, error) {
+	hash := helper.HashToken(token)
+	consumed, err := consumeRefreshTokenScript.Run(ctx, o.redis, []string{oauthTokenPrefix + hash, oauthUsedRefreshPrefix + hash}, grantID, max(ttl.Milliseconds(), 1)).Int()
+	if err != nil {
+		return false, errs.Internal("failed to consume refresh token", err)
+	}
+	return consumed == 1, nil
+}
+
+func (o *OAuthTokenRepository) DeleteToken(ctx context.Context, token string) error {
+	if err := o.redis.Del(ctx, oauthTokenPrefix+helper.HashToken(token)).Err(); err != nil {
+		return errs.Internal("failed to revoke token", err)
+	}
+	return nil
+}
+
+// FindUsedRefreshToken mengembalikan grant dari refresh token yang sudah pernah dirotasi
+func (o *OAuthTokenRepository) FindUsedRefreshToken(ctx context.Context, token string) (string, error) {
+	grantID, err := o.redis.Get(ctx, oauthUsedRefreshPrefix+helper.HashToken(token)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", errs.NotFound("token not found", err)
+		}
+		return "", errs.Internal("failed to find token", err)
+	}
+	return grantID, nil
+}
+
+func (o *OAuthTokenRepository) SaveGrant(ctx context.Context, grant *auth.OAuthGrant, ttl time.Duration) error {
+	payload, err := json.Marshal(grant)
+	if err != nil {
+		return errs.Internal("failed to store grant", err)
+	}
+
+	clientKey := oauthClientGrantPrefix + grant.ClientID
+	userKey := userGrantKey(grant.UserID, grant.ClientID)
+
+	pipe := o.redis.TxPipeline()
+	pipe.Set(ctx, oauthGrantPrefix+grant.ID, payload, ttl)
+	pipe.SAdd(ctx, clientKey, grant.ID)
+	pipe.Expire(ctx, clientKey, ttl)
+	pipe.SAdd(ctx, userKey, grant.ID)
+	pipe.Expire(ctx, userKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errs.Internal("failed to store grant", err)
+	}
+	return nil
+}
+
+func (o *OAuthTokenRepository) FindGrant(ctx context.Context, id string) (*auth.OAuthGrant, error) {
+	payload, err := o.redis.Get(ctx, oauthGrantPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.NotFound("grant not found", err)
+		}
+		return nil, errs.Internal("failed to find grant", err)
+	}
+
+	var grant auth.OAuthGrant
+	if err := json.Unmarshal(payload, &grant); err != nil {
+		return nil, errs.Internal("failed to decode grant", err)
+	}
+	return &grant, nil
+}
+
+func (o *OAuthTokenRepository) DeleteGrant(ctx context.Context, id string) error {
+	if err := o.redis.Del(ctx, oauthGrantPrefix+id).Err(); err != nil {
+		return errs.Internal("failed to revoke grant", err)
+	}
+	return nil
+}
+
+// DeleteGrantsByClient mencabut seluruh grant milik client
+func (o *OAuthTokenRepository) DeleteGrantsByClient(ctx context.Context, clientID string) error {
+	return o.deleteGrantIndex(ctx, oauthClientGrantPrefix+clientID)
+}
+
+// DeleteGrantsByUser mencabut seluruh grant yang diberikan user kepada client
+func (o *OAuthTokenRepository) DeleteGrantsByUser(ctx context.Context, userID string, clientID string) error {
+	return o.deleteGrantIndex(ctx, userGrantKey(userID, clientID))
+}
+
+func (o *OAuthTokenRepository) deleteGrantIndex(ctx context.Context, indexKey string) error {
+	ids, err := o.redis.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return errs.Internal("failed to find grants", err)
+	}
+
+	keys := []string{indexKey}
+	for _, id := range ids {
+		keys = append(keys, oauthGrantPrefix+id)
+	}
+
+	if err := o.redis.Del(ctx, keys...).Err(); err != nil {
+		return errs.Internal("failed to revoke grants", err)
+	}
+	return nil
+}
+
+func (o *OAuthTokenRepository) setJSON(ctx context.Context, key string, data interface{}, ttl time.Duration, message string) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errs.Internal(message, err)
+	}
+
+	if err := o.redis.Set(ctx, key, payload, ttl).Err(); err != nil {
+		return errs.Internal(message, err)
+	}
+	return nil
+}
+
+func (o *OAuthTokenRepository) getDelJSON(ctx context.Context, key string, out interface{}, name string) error {
+	payload, err := o.redis.GetDel(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return errs.NotFound(name+" not found", err)
+		}
+		return errs.Internal("failed to find "+name, err)
+	}
+
+	if err := json.Unmarshal(payload, out); err != nil {
+		return errs.Internal("failed to decode "+name, err)
+	}
+	return nil
+}
+
+func userGrantKey(userID string, clientID string) string {
+	return oauthUserGrantPrefix + userID + ":" + clientID
+}
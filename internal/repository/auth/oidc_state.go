@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const oidcStatePrefix = "oidcstate:"
+
+// OIDCStateRepository menyimpan state, nonce dan code verifier PKCE selama alur login OIDC
+type OIDCStateRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewOIDCStateRepository(redisClient *redis.Client, logger *zerolog.Logger) *OIDCStateRepository {
+	return &OIDCStateRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+func (o *OIDCStateRepository) Save(ctx context.Context, state string, data *auth.OIDCState, ttl time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errs.Internal("failed to store login state", err)
+	}
+
+	if err := o.redis.Set(ctx, oidcStatePrefix+helper.HashToken(state), payload, ttl).Err(); err != nil {
+		return errs.Internal("failed to store login state", err)
+	}
+	return nil
+}
+
+// Consume mengambil sekaligus menghapus state sehingga callback tidak bisa diulang
+func (o *OIDCStateRepository) Consume(ctx context.Context, state string) (*auth.OIDCState, error) {
+	payload, err := o.redis.GetDel(ctx, oidcStatePrefix+helper.HashToken(state)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, errs.BadRequest("invalid or expired login state", err)
+		}
+		return nil, errs.Internal("failed to find login state", err)
+	}
+
+	var data auth.OIDCState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, errs.Internal("failed to decode login state", err)
+	}
+	return &data, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	passwordResetTokenPrefix = "passwordreset:token:"
+	passwordResetUserPrefix  = "passwordreset:user:"
+)
+
+type PasswordResetRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewPasswordResetRepository(redisClient *redis.Client, logger *zerolog.Logger) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+// Create menyimpan hash token reset, token sebelumnya milik user yang sama otomatis tidak berlaku
+func (p *PasswordResetRepository) Create(ctx context.Context, userID string, tokenHash string, ttl time.Duration) error {
+	userKey := passwordResetUserPrefix + userID
+
+	previous, err := p.redis.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return errs.Internal("failed to store reset token", err)
+	}
+
+	pipe := p.redis.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, passwordResetTokenPrefix+previous)
+	}
+	pipe.Set(ctx, passwordResetTokenPrefix+tokenHash, userID, ttl)
+	pipe.Set(ctx, userKey, tokenHash, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errs.Internal("failed to store reset token", err)
+	}
+
+	return nil
+}
+
+// Find mengambil user pemilik token tanpa menghapusnya
+func (p *PasswordResetRepository) Find(ctx context.Context, tokenHash string) (string, error) {
+	userID, err := p.redis.Get(ctx, passwordResetTokenPrefix+tokenHash).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", errs.BadRequest("invalid or expired reset token", err)
+		}
+		return "", errs.Internal("failed to find reset token", err)
+	}
+
+	return userID, nil
+}
+
+// Consume mengambil sekaligus menghapus token sehingga hanya bisa dipakai sekali
+func (p *PasswordResetRepository) Consume(ctx context.Context, tokenHash string) (string, error) {
+	userID, err := p.redis.GetDel(ctx, passwordResetTokenPrefix+tokenHash).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", errs.BadRequest("invalid or expired reset token", err)
+		}
+		return "", errs.Internal("failed to find reset token", err)
+	}
+
+	p.redis.Del(ctx, passwordResetUserPrefix+userID)
+	return userID, nil
+}
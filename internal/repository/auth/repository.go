@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+)
+
+type (
+	ISessionRepository interface {
+		Create(ctx context.Context, session *auth.Session) error
+		FindById(ctx context.Context, id string) (*auth.Session, error)
+		FindByUser(ctx context.Context, userID string) (*[]auth.Session, error)
+		Update(ctx context.Context, session *auth.Session) error
+		Delete(ctx context.Context, userID string, id string) error
+		DeleteByUser(ctx context.Context, userID string) error
+	}
+
+	ITokenRepository interface {
+		Revoke(ctx context.Context, tokenType string, token string, ttl time.Duration) error
+		IsRevoked(ctx context.Context, tokenType string, token string) (bool, error)
+		SaveFamily(ctx context.Context, family *auth.TokenFamily, ttl time.Duration) error
+		FindFamily(ctx context.Context, id string) (*auth.TokenFamily, error)
+		RotateFamily(ctx context.Context, id string, currentJTI string, nextJTI string, ttl time.Duration) (bool, error)
+		DeleteFamily(ctx context.Context, id string) error
+	}
+
+	ILoginAttemptRepository interface {
+		RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+		CountFailures(ctx context.Context, key string, window time.Duration) (int64, error)
+		Reset(ctx context.Context, key string) error
+	}
+
+	IOIDCStateRepository interface {
+		Save(ctx context.Context, state string, data *auth.OIDCState, ttl time.Duration) error
+		Consume(ctx context.Context, state string) (*auth.OIDCState, error)
+	}
+
+	IOAuthClientRepository interface {
+		Create(ctx context.Context, client *auth.OAuthClient) error
+		FindByClientID(ctx context.Context, clientID string) (*auth.OAuthClient, error)
+		FindAll(ctx context.Context) (*[]auth.OAuthClient, error)
+		Revoke(ctx context.Context, clientID string) (bool, error)
+	}
+
+	IOAuthConsentRepository interface {
+		Find(ctx context.Context, userID string, clientID string) (*auth.OAuthConsent, error)
+		FindByUser(ctx context.Context, userID string) (*[]auth.OAuthConsent, error)
+		Grant(ctx context.Context, userID string, clientID string, scopes []string) error
+		Delete(ctx context.Context, userID string, clientID string) (bool, error)
+	}
+
+	IOAuthTokenRepository interface {
+		SaveCode(ctx context.Context, code string, data *auth.OAuthAuthorizationCode, ttl time.Duration) error
+		FindCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error)
+		ConsumeCode(ctx context.Context, code string) (*auth.OAuthAuthorizationCode, error)
+		SaveToken(ctx context.Context, token string, data *auth.OAuthToken, ttl time.Duration) error
+		FindToken(ctx context.Context, token string) (*auth.OAuthToken, error)
+		ConsumeRefreshToken(ctx context.Context, token string, grantID string, ttl time.Duration) (bool, error)
+		DeleteToken(ctx context.Context, token string) error
+		FindUsedRefreshToken(ctx context.Context, token string) (string, error)
+		SaveGrant(ctx context.Context, grant *auth.OAuthGrant, ttl time.Duration) error
+		FindGrant(ctx context.Context, id string) (*auth.OAuthGrant, error)
+		DeleteGrant(ctx context.Context, id string) error
+		DeleteGrantsByClient(ctx context.Context, clientID string) error
+		DeleteGrantsByUser(ctx context.Context, userID string, clientID string) error
+	}
+
+	IPasswordResetRepository interface {
+		Create(ctx context.Context, userID string, tokenHash string, ttl time.Duration) error
+		Find(ctx context.Context, tokenHash string) (string, error)
+		Consume(ctx context.Context, tokenHash string) (string, error)
+	}
+)
@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	sessionKeyPrefix     = "session:"
+	userSessionKeyPrefix = "session:user:"
+)
+
+// updateSessionScript menulis session hanya jika masih ada (session yang dicabut bersamaan tidak hidup kembali)
+// lalu memperpanjang TTL daftar session user jika lebih pendek. Perbandingan TTL dilakukan di script
+// karena EXPIRE GT baru tersedia di Redis 7.
+var updateSessionScript = redis.NewScript(`
+if not redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2], 'XX') then
+	return 0
+end
+local ttl = redis.call('PTTL', KEYS[2])
+if ttl >= 0 and ttl < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+type SessionRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewSessionRepository(redisClient *redis.Client, logger *zerolog.Logger) *SessionRepository {
+	return &SessionRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+func (s *SessionRepository) Create(ctx context.Context, session *auth.Session) error {
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return errs.Internal("failed to encode session", err)
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	userKey := userSessionKeyPrefix + session.UserID
+
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, sessionKeyPrefix+session.ID, payload, ttl)
+	pipe.SAdd(ctx, userKey, session.ID)
+	pipe.Expire(ctx, userKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errs.Internal("failed to store session", err)
+	}
+
+	return nil
+}
+
+func (s *SessionRepository) FindById(ctx context.Context, id string) (*auth.Session, error) {
+	raw, err := s.redis.Get(ctx, sessionKeyPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return &auth.Session{}, errs.NotFound("session not found", err)
+		}
+		return &auth.Session{}, errs.Internal("failed to find session", err)
+	}
+
+	var session auth.Session
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return &auth.Session{}, errs.Internal("failed to decode session", err)
+	}
+
+	return &session, nil
+}
+
+func (s *SessionRepository) FindByUser(ctx context.Context, userID string) (*[]auth.Session, error) {
+	userKey := userSessionKeyPrefix + userID
+
+	ids, err := s.redis.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return &[]auth.Session{}, errs.Internal("failed to query sessions", err)
+	}
+
+	sessions := []auth.Session{}
+	if len(ids) == 0 {
+		return &sessions, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sessionKeyPrefix + id
+	}
+
+	values, err := s.redis.MGet(ctx, keys...).Result()
+	if err != nil {
+		return &[]auth.Session{}, errs.Internal("failed to query sessions", err)
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			// session sudah kedaluwarsa, hapus dari index user
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var session auth.Session
+		if err := json.Unmarshal([]byte(raw), &session); err != nil {
+			s.logger.Error().Err(err).Str("session_id", ids[i]).Msg("failed to decode session")
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(expired) > 0 {
+		s.redis.SRem(ctx, userKey, expired...)
+	}
+
+	return &sessions, nil
+}
+
+func (s *SessionRepository) Update(ctx context.Context, session *auth.Session) error {
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return errs.Internal("failed to encode session", err)
+	}
+
+	ttl := time.Until(session.ExpiresAt)
+	userKey := userSessionKeyPrefix + session.UserID
+
+	updated, err := updateSessionScript.Run(ctx, s.redis, []string{sessionKeyPrefix + session.ID, userKey},
+		payload, max(ttl.Milliseconds(), 1)).Int()
+	if err != nil {
+		return errs.Internal("failed to update session", err)
+	}
+
+	if updated == 0 {
+		return errs.NotFound("session not found", nil)
+	}
+
+	return nil
+}
+
+func (s *SessionRepository) Delete(ctx context.Context, userID string, id string) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, sessionKeyPrefix+id)
+	pipe.SRem(ctx, userSessionKeyPrefix+userID, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errs.Internal("failed to delete session", err)
+	}
+
+	return nil
+}
+
+func (s *SessionRepository) DeleteByUser(ctx context.Context, userID string) error {
+	userKey := userSessionKeyPrefix + userID
+
+	ids, err := s.redis.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return errs.Internal("failed to query sessions", err)
+	}
+
+	keys := []string{userKey}
+	for _, id := range ids {
+		keys = append(keys, sessionKeyPrefix+id)
+	}
+
+	if err := s.redis.Del(ctx, keys...).Err(); err != nil {
+		return errs.Internal("failed to delete sessions", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+const (
+	tokenBlacklistPrefix        = "token:blacklist:"
+	refreshTokenBlacklistPrefix = "refreshtoken:blacklist:"
+	refreshTokenFamilyPrefix    = "refreshtoken:family:"
+)
+
+// rotateFamilyScript mengganti jti aktif family hanya jika masih sama dengan jti yang diharapkan (compare-and-swap),
+// sehingga dua refresh bersamaan dengan token yang sama hanya satu yang berhasil.
+var rotateFamilyScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'jti') ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'jti', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+type TokenRepository struct {
+	redis  *redis.Client
+	logger *zerolog.Logger
+}
+
+func NewTokenRepository(redisClient *redis.Client, logger *zerolog.Logger) *TokenRepository {
+	return &TokenRepository{
+		redis:  redisClient,
+		logger: logger,
+	}
+}
+
+func (t *TokenRepository) Revoke(ctx context.Context, tokenType string, token string, ttl time.Duration) error {
+	if err := t.redis.Set(ctx, blacklistKey(tokenType, token), "revoked", ttl).Err(); err != nil {
+		return errs.Internal("failed to store token in blacklist", err)
+	}
+	return nil
+}
+
+func (t *TokenRepository) IsRevoked(ctx context.Context, tokenType string, token string) (bool, error) {
+	count, err := t.redis.Exists(ctx, blacklistKey(tokenType, token)).Result()
+	if err != nil {
+		return false, errs.Internal("failed to check token blacklist", err)
+	}
+	return count > 0, nil
+}
+
+func (t *TokenRepository) SaveFamily(ctx context.Context, family *auth.TokenFamily, ttl time.Duration) error {
+	familyKey := refreshTokenFamilyPrefix + family.ID
+
+	pipe := t.redis.TxPipeline()
+	pipe.HSet(ctx, familyKey, "user_id", family.UserID, "session_id", family.SessionID, "jti", family.CurrentJTI)
+	pipe.Expire(ctx, familyKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errs.Internal("failed to store refresh token family", err)
+	}
+	return nil
+}
+
+func (t *TokenRepository) FindFamily(ctx context.Context, id string) (*auth.TokenFamily, error) {
+	values, err := t.redis.HGetAll(ctx, refreshTokenFamilyPrefix+id).Result()
+	if err != nil {
+		return &auth.TokenFamily{}, errs.Internal("failed to find refresh token family", err)
+	}
+
+	if len(values) == 0 {
+		return &auth.TokenFamily{}, errs.NotFound("refresh token family not found", nil)
+	}
+
+	return &auth.TokenFamily{
+		ID:         id,
+		UserID:     values["user_id"],
+		SessionID:  values["session_id"],
+		CurrentJTI: values["jti"],
+	}, nil
+}
+
+// RotateFamily mengganti jti aktif family secara atomik, mengembalikan false jika jti aktif sudah berubah
+func (t *TokenRepository) RotateFamily(ctx context.Context, id string, currentJTI string, nextJTI string, ttl time.Duration) (bool, error) {
+	rotated, err := rotateFamilyScript.Run(ctx, t.redis, []string{refreshTokenFamilyPrefix + id}, currentJTI, nextJTI, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, errs.Internal("failed to rotate refresh token family", err)
+	}
+	return rotated == 1, nil
+}
+
+func (t *TokenRepository) DeleteFamily(ctx context.Context, id string) error {
+	if err := t.redis.Del(ctx, refreshTokenFamilyPrefix+id).Err(); err != nil {
+		return errs.Internal("failed to revoke refresh token family", err)
+	}
+	return nil
+}
+
+func blacklistKey(tokenType string, token string) string {
+	if tokenType == auth.TokenTypeRefresh {
+		return refreshTokenBlacklistPrefix + token
+	}
+	return tokenBlacklistPrefix + token
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+)
+
+type memoryEntry struct {
+	family    *auth.TokenFamily
+	expiresAt time.Time
+}
+
+// MemoryTokenRepository menyimpan blacklist dan family token di memori proses.
+// Dipakai untuk testing atau menjalankan aplikasi tanpa redis; data tidak dibagi antar instance.
+type MemoryTokenRepository struct {
+	mu        sync.Mutex
+	blacklist map[string]time.Time
+	families  map[string]memoryEntry
+}
+
+func NewMemoryTokenRepository() *MemoryTokenRepository {
+	return &MemoryTokenRepository{
+		blacklist: make(map[string]time.Time),
+		families:  make(map[string]memoryEntry),
+	}
+}
+
+func (m *MemoryTokenRepository) Revoke(ctx context.Context, tokenType string, token string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.blacklist[blacklistKey(tokenType, token)] = time.Now().Add(ttl)
+	return nil
+}
+
+func (m *MemoryTokenRepository) IsRevoked(ctx context.Context, tokenType string, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := blacklistKey(tokenType, token)
+	expiresAt, ok := m.blacklist[key]
+	if !ok {
+		return false, nil
+	}
+
+	if time.Now().After(expiresAt) {
+		delete(m.blacklist, key)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *MemoryTokenRepository) SaveFamily(ctx context.Context, family *auth.TokenFamily, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *family
+	m.families[family.ID] = memoryEntry{family: &copied, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *MemoryTokenRepository) FindFamily(ctx context.Context, id string) (*auth.TokenFamily, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.families[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(m.families, id)
+		return &auth.TokenFamily{}, errs.NotFound("refresh token family not found", nil)
+	}
+
+	copied := *entry.family
+	return &copied, nil
+}
+
+func (m *MemoryTokenRepository) RotateFamily(ctx context.Context, id string, currentJTI string, nextJTI string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.families[id]
+	if !ok || time.Now().After(entry.expiresAt) || entry.family.CurrentJTI != currentJTI {
+		return false, nil
+	}
+
+	entry.family.CurrentJTI = nextJTI
+	entry.expiresAt = time.Now().Add(ttl)
+	m.families[id] = entry
+	return true, nil
+}
+
+func (m *MemoryTokenRepository) DeleteFamily(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.families, id)
+	return nil
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+)
+
+func TestMemoryTokenRepositoryBlacklist(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		revokeType string
+		checkType  string
+		ttl        time.Duration
+		want       bool
+	}{
+		{name: "revoked access token", revokeType: auth.TokenTypeAccess, checkType: auth.TokenTypeAccess, ttl: time.Minute, want: true},
+		{name: "revoked refresh token", revokeType: auth.TokenTypeRefresh, checkType: auth.TokenTypeRefresh, ttl: time.Minute, want: true},
+		{name: "blacklist is separated per token type", revokeType: auth.TokenTypeAccess, checkType: auth.TokenTypeRefresh, ttl: time.Minute, want: false},
+		{name: "entry expires with the token", revokeType: auth.TokenTypeAccess, checkType: auth.TokenTypeAccess, ttl: -time.Second, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryTokenRepository()
+
+			if err := repo.Revoke(ctx, tt.revokeType, "token", tt.ttl); err != nil {
+				t.Fatalf("Revoke() error = %v", err)
+			}
+
+			revoked, err := repo.IsRevoked(ctx, tt.checkType, "token")
+			if err != nil {
+				t.Fatalf("IsRevoked() error = %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", revoked, tt.want)
+			}
+
+			if revoked, _ := repo.IsRevoked(ctx, tt.checkType, "other-token"); revoked {
+				t.Errorf("IsRevoked() for a token that was never revoked = true")
+			}
+		})
+	}
+}
+
+func TestMemoryTokenRepositoryFamily(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		delete   bool
+		wantJTI  string
+		notFound bool
+	}{
+		{name: "saved family can be found", ttl: time.Minute, wantJTI: "jti-1"},
+		{name: "expired family is not found", ttl: -time.Second, notFound: true},
+		{name: "deleted family is not found", ttl: time.Minute, delete: true, notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryTokenRepository()
+
+			family := &auth.TokenFamily{ID: "family", UserID: "user", SessionID: "session", CurrentJTI: "jti-1"}
+			if err := repo.SaveFamily(ctx, family, tt.ttl); err != nil {
+				t.Fatalf("SaveFamily() error = %v", err)
+			}
+			if tt.delete {
+				if err := repo.DeleteFamily(ctx, family.ID); err != nil {
+					t.Fatalf("DeleteFamily() error = %v", err)
+				}
+			}
+
+			found, err := repo.FindFamily(ctx, family.ID)
+			if tt.notFound {
+				if !errs.IsNotFound(err) {
+					t.Fatalf("FindFamily() error = %v, want not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindFamily() error = %v", err)
+			}
+			if found.CurrentJTI != tt.wantJTI || found.UserID != family.UserID || found.SessionID != family.SessionID {
+				t.Errorf("FindFamily() = %+v, want %+v", found, family)
+			}
+		})
+	}
+}
+
+func TestMemoryTokenRepositoryRotateFamily(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		saved       bool
+		currentJTI  string
+		wantRotated bool
+		wantJTI     string
+	}{
+		{name: "active jti is rotated", saved: true, currentJTI: "jti-1", wantRotated: true, wantJTI: "jti-2"},
+		{name: "stale jti is rejected", saved: true, currentJTI: "jti-0", wantRotated: false, wantJTI: "jti-1"},
+		{name: "missing family is rejected", saved: false, currentJTI: "jti-1", wantRotated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryTokenRepository()
+			if tt.saved {
+				_ = repo.SaveFamily(ctx, &auth.TokenFamily{ID: "family", CurrentJTI: "jti-1"}, time.Minute)
+			}
+
+			rotated, err := repo.RotateFamily(ctx, "family", tt.currentJTI, "jti-2", time.Minute)
+			if err != nil {
+				t.Fatalf("RotateFamily() error = %v", err)
+			}
+			if rotated != tt.wantRotated {
+				t.Errorf("RotateFamily() = %v, want %v", rotated, tt.wantRotated)
+			}
+
+			if !tt.saved {
+				return
+			}
+			found, err := repo.FindFamily(ctx, "family")
+			if err != nil {
+				t.Fatalf("FindFamily() error = %v", err)
+			}
+			if found.CurrentJTI != tt.wantJTI {
+				t.Errorf("CurrentJTI = %q, want %q", found.CurrentJTI, tt.wantJTI)
+			}
+		})
+	}
+}
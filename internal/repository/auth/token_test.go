@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+func newTestTokenRepository(t *testing.T) (*TokenRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	logger := zerolog.Nop()
+	return NewTokenRepository(client, &logger), server
+}
+
+func TestTokenRepositoryRotateFamily(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		saved       bool
+		currentJTI  string
+		wantRotated bool
+		wantJTI     string
+	}{
+		{name: "active jti is rotated", saved: true, currentJTI: "jti-1", wantRotated: true, wantJTI: "jti-2"},
+		{name: "stale jti is rejected", saved: true, currentJTI: "jti-0", wantRotated: false, wantJTI: "jti-1"},
+		{name: "missing family is rejected", saved: false, currentJTI: "jti-1", wantRotated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, server := newTestTokenRepository(t)
+			if tt.saved {
+				family := &auth.TokenFamily{ID: "family", UserID: "user", SessionID: "session", CurrentJTI: "jti-1"}
+				if err := repo.SaveFamily(ctx, family, time.Minute); err != nil {
+					t.Fatalf("SaveFamily() error = %v", err)
+				}
+			}
+
+			rotated, err := repo.RotateFamily(ctx, "family", tt.currentJTI, "jti-2", time.Hour)
+			if err != nil {
+				t.Fatalf("RotateFamily() error = %v", err)
+			}
+			if rotated != tt.wantRotated {
+				t.Errorf("RotateFamily() = %v, want %v", rotated, tt.wantRotated)
+			}
+
+			if !tt.saved {
+				// Family yang sudah dicabut tidak boleh dibuat ulang oleh rotasi
+				if server.Exists(refreshTokenFamilyPrefix + "family") {
+					t.Errorf("RotateFamily() created a missing family")
+				}
+				return
+			}
+
+			found, err := repo.FindFamily(ctx, "family")
+			if err != nil {
+				t.Fatalf("FindFamily() error = %v", err)
+			}
+			if found.CurrentJTI != tt.wantJTI || found.UserID != "user" || found.SessionID != "session" {
+				t.Errorf("FindFamily() = %+v, want jti %q", found, tt.wantJTI)
+			}
+
+			// Rotasi memperpanjang umur family sesuai umur refresh token baru
+			wantTTL := time.Minute
+			if tt.wantRotated {
+				wantTTL = time.Hour
+			}
+			if ttl := server.TTL(refreshTokenFamilyPrefix + "family"); ttl != wantTTL {
+				t.Errorf("family TTL = %v, want %v", ttl, wantTTL)
+			}
+		})
+	}
+}
+
+func TestTokenRepositoryRotateFamilyConcurrently(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newTestTokenRepository(t)
+
+	if err := repo.SaveFamily(ctx, &auth.TokenFamily{ID: "family", CurrentJTI: "jti-0"}, time.Minute); err != nil {
+		t.Fatalf("SaveFamily() error = %v", err)
+	}
+
+	// Seluruh request membawa refresh token yang sama, hanya satu yang boleh mendapat token baru
+	const requests = 50
+	var wg sync.WaitGroup
+	results := make([]bool, requests)
+	failures := make([]error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], failures[i] = repo.RotateFamily(ctx, "family", "jti-0", fmt.Sprintf("jti-%d", i+1), time.Minute)
+		}(i)
+	}
+	wg.Wait()
+
+	winner := ""
+	for i, rotated := range results {
+		if failures[i] != nil {
+			t.Fatalf("RotateFamily() error = %v", failures[i])
+		}
+		if !rotated {
+			continue
+		}
+		if winner != "" {
+			t.Fatalf("RotateFamily() succeeded for %s and jti-%d, want exactly one", winner, i+1)
+		}
+		winner = fmt.Sprintf("jti-%d", i+1)
+	}
+	if winner == "" {
+		t.Fatalf("RotateFamily() failed for every request, want exactly one success")
+	}
+
+	found, err := repo.FindFamily(ctx, "family")
+	if err != nil {
+		t.Fatalf("FindFamily() error = %v", err)
+	}
+	if found.CurrentJTI != winner {
+		t.Errorf("CurrentJTI = %q, want %q", found.CurrentJTI, winner)
+	}
+}
+
+func TestTokenRepositoryBlacklist(t *testing.T) {
+	ctx := context.Background()
+	repo, server := newTestTokenRepository(t)
+
+	if err := repo.Revoke(ctx, auth.TokenTypeRefresh, "refresh-token", time.Minute); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	tests := []struct {
+		tokenType string
+		token     string
+		want      bool
+	}{
+		{tokenType: auth.TokenTypeRefresh, token: "refresh-token", want: true},
+		// Blacklist refresh token dan access token dipisah
+		{tokenType: auth.TokenTypeAccess, token: "refresh-token", want: false},
+		{tokenType: auth.TokenTypeRefresh, token: "other-token", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tokenType+" "+tt.token, func(t *testing.T) {
+			revoked, err := repo.IsRevoked(ctx, tt.tokenType, tt.token)
+			if err != nil {
+				t.Fatalf("IsRevoked() error = %v", err)
+			}
+			if revoked != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", revoked, tt.want)
+			}
+		})
+	}
+
+	// Entry blacklist hilang bersama umur token
+	server.FastForward(time.Minute)
+	if revoked, _ := repo.IsRevoked(ctx, auth.TokenTypeRefresh, "refresh-token"); revoked {
+		t.Errorf("IsRevoked() = true after the token expired")
+	}
+}
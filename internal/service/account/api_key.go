@@ -0,0 +1,199 @@
+package account
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	"github.com/rs/zerolog"
+)
+
+// apiKeyTouchInterval membatasi penulisan last used agar tidak terjadi write di setiap request
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyService struct {
+	repo        repository.IAPIKeyRepository
+	permissions IPermissionService
+	catalog     *permission.Catalog
+	logger      *zerolog.Logger
+}
+
+func NewAPIKeyService(repo repository.IAPIKeyRepository, permissions IPermissionService, catalog *permission.Catalog, logger *zerolog.Logger) *APIKeyService {
+	return &APIKeyService{
+		repo:        repo,
+		permissions: permissions,
+		catalog:     catalog,
+		logger:      logger,
+	}
+}
+
+// Create membuat key untuk owner. Permission key harus berada di dalam permission owner saat ini
+// dan, jika dibuat oleh user lain, di dalam permission pembuatnya. Key lengkap hanya dikembalikan sekali
+// dan yang disimpan hanya hash-nya.
+func (a *APIKeyService) Create(ctx context.Context, owner *account.User, creator *account.User, request *account.CreateAPIKeyRequest) (*account.CreateAPIKeyResponse, error) {
+	if invalid := a.catalog.Validate(request.Permissions); len(invalid) > 0 {
+		return nil, errs.BadRequest("invalid permission", fmt.Errorf("invalid permissions: %v", invalid))
+	}
+
+	ownerPermissions, err := a.permissions.Resolve(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	var exceeded []string
+	for _, p := range request.Permissions {
+		if !ownerPermissions.Has(p) {
+			exceeded = append(exceeded, p)
+		}
+	}
+	if len(exceeded) > 0 {
+		return nil, errs.BadRequest("permissions exceed the owner's roles", fmt.Errorf("permissions not granted to owner: %v", exceeded))
+	}
+
+	// Key untuk service account dibuat oleh user lain, key tersebut tidak boleh memberi akses yang tidak dimiliki pembuatnya.
+	// Key tanpa permission mewarisi seluruh permission owner, sehingga seluruh permission owner harus dimiliki pembuat.
+	if creator.ID != owner.ID {
+		creatorPermissions, err := a.permissions.Resolve(ctx, creator)
+		if err != nil {
+			return nil, err
+		}
+
+		granted := ownerPermissions
+		if len(request.Permissions) > 0 {
+			granted = a.catalog.Expand(request.Permissions)
+		}
+
+		for _, p := range granted.List() {
+			if !creatorPermissions.Has(p) {
+				exceeded = append(exceeded, p)
+			}
+		}
+		if len(exceeded) > 0 {
+			return nil, errs.Forbidden("permissions exceed the creator's permissions", fmt.Errorf("permissions not granted to creator: %v", exceeded))
+		}
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return nil, errs.BadRequest("expires_at must be in the future", nil)
+	}
+
+	prefixID, err := helper.GenerateRandomString(6)
+	if err != nil {
+		return nil, errs.Internal("failed to generate api key", err)
+	}
+	secret, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return nil, errs.Internal("failed to generate api key", err)
+	}
+
+	prefix := account.APIKeyScheme + "_" + prefixID
+	rawKey := prefix + "_" + secret
+
+	permissions := request.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	key := &account.APIKey{
+		OwnerID:     owner.ID,
+		Name:        request.Name,
+		Prefix:      prefix,
+		KeyHash:     helper.HashToken(rawKey),
+		Permissions: permissions,
+		ExpiresAt:   request.ExpiresAt,
+		CreatedBy:   creator.ID,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := a.repo.Create(ctx, key); err != nil {
+		a.logger.Error().Err(err).Str("owner_id", owner.ID.Hex()).Msg("failed to create api key")
+		return nil, err
+	}
+
+	a.logger.Info().
+		Str("event", "security.api_key_created").
+		Str("owner_id", owner.ID.Hex()).
+		Str("created_by", creator.ID.Hex()).
+		Str("prefix", prefix).
+		Msg("api key created")
+
+	return &account.CreateAPIKeyResponse{Key: rawKey, APIKey: key}, nil
+}
+
+func (a *APIKeyService) FindByOwner(ctx context.Context, ownerID string) (*[]account.APIKey, error) {
+	keys, err := a.repo.FindByOwner(ctx, ownerID)
+	if err != nil {
+		a.logger.Error().Err(err).Str("owner_id", ownerID).Msg("error from repo")
+		return &[]account.APIKey{}, err
+	}
+	return keys, nil
+}
+
+func (a *APIKeyService) Revoke(ctx context.Context, ownerID string, id string) error {
+	revoked, err := a.repo.Revoke(ctx, ownerID, id)
+	if err != nil {
+		a.logger.Error().Err(err).Str("owner_id", ownerID).Str("api_key_id", id).Msg("failed to revoke api key")
+		return err
+	}
+	if !revoked {
+		return errs.NotFound("api key not found", nil)
+	}
+
+	a.logger.Info().Str("event", "security.api_key_revoked").Str("owner_id", ownerID).Str("api_key_id", id).Msg("api key revoked")
+	return nil
+}
+
+// Authenticate mencari key berdasarkan prefix lalu membandingkan hash key lengkap
+func (a *APIKeyService) Authenticate(ctx context.Context, rawKey string, ip string) (*account.APIKey, error) {
+	prefix, ok := apiKeyPrefix(rawKey)
+	if !ok {
+		return nil, errs.Unauthorized("Unauthorized", nil)
+	}
+
+	key, err := a.repo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, errs.Unauthorized("Unauthorized", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(helper.HashToken(rawKey))) != 1 {
+		return nil, errs.Unauthorized("Unauthorized", nil)
+	}
+
+	if !key.IsActive() {
+		return nil, errs.Unauthorized("api key is revoked or expired", nil)
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != ip {
+		if err := a.repo.UpdateLastUsed(ctx, key.ID, now, ip); err != nil {
+			a.logger.Warn().Err(err).Str("api_key_id", key.ID.Hex()).Msg("failed to record api key usage")
+		}
+	}
+
+	return key, nil
+}
+
+// Permissions menghitung permission efektif key: irisan permission owner saat ini dengan
+// permission key, sehingga key ikut kehilangan akses ketika role owner dikurangi
+func (a *APIKeyService) Permissions(key *account.APIKey, owner permission.Set) permission.Set {
+	if len(key.Permissions) == 0 {
+		return owner
+	}
+	return permission.Intersect(owner, a.catalog.Expand(key.Permissions))
+}
+
+// apiKeyPrefix mengambil bagian sk_<prefix> dari key berformat sk_<prefix>_<secret>
+func apiKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != account.APIKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[0] + "_" + parts[1], true
+}
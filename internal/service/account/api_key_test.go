@@ -0,0 +1,439 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeAPIKeyRepository struct {
+	keys     map[string]*account.APIKey
+	lastUsed []bson.ObjectID
+}
+
+func newFakeAPIKeyRepository() *fakeAPIKeyRepository {
+	return &fakeAPIKeyRepository{keys: make(map[string]*account.APIKey)}
+}
+
+func (f *fakeAPIKeyRepository) Create(ctx context.Context, key *account.APIKey) error {
+	if _, ok := f.keys[key.Prefix]; ok {
+		return errs.Conflict("api key exist", nil)
+	}
+	key.ID = bson.NewObjectID()
+	f.keys[key.Prefix] = key
+	return nil
+}
+
+func (f *fakeAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*account.APIKey, error) {
+	key, ok := f.keys[prefix]
+	if !ok {
+		return nil, errs.NotFound("api key not found", nil)
+	}
+	return key, nil
+}
+
+func (f *fakeAPIKeyRepository) FindByOwner(ctx context.Context, ownerID string) (*[]account.APIKey, error) {
+	keys := []account.APIKey{}
+	for _, key := range f.keys {
+		if key.OwnerID.Hex() == ownerID {
+			keys = append(keys, *key)
+		}
+	}
+	return &keys, nil
+}
+
+func (f *fakeAPIKeyRepository) Revoke(ctx context.Context, ownerID string, id string) (bool, error) {
+	for _, key := range f.keys {
+		if key.ID.Hex() == id && key.OwnerID.Hex() == ownerID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeAPIKeyRepository) UpdateLastUsed(ctx context.Context, id bson.ObjectID, usedAt time.Time, ip string) error {
+	f.lastUsed = append(f.lastUsed, id)
+	for _, key := range f.keys {
+		if key.ID == id {
+			key.LastUsedAt = &usedAt
+			key.LastUsedIP = ip
+		}
+	}
+	return nil
+}
+
+// fakePermissionService meresolve grant per user lewat katalog, sama seperti PermissionService tanpa role
+type fakePermissionService struct {
+	catalog *permission.Catalog
+	grants  map[bson.ObjectID][]string
+}
+
+func (f *fakePermissionService) Resolve(ctx context.Context, user *account.User) (permission.Set, error) {
+	grants, ok := f.grants[user.ID]
+	if !ok {
+		return nil, errs.NotFound("user not found", nil)
+	}
+	return f.catalog.Expand(grants), nil
+}
+
+func (f *fakePermissionService) InvalidateUser(ctx context.Context, userID string) {}
+
+func (f *fakePermissionService) InvalidateAll(ctx context.Context) {}
+
+func newTestCatalog(t *testing.T) *permission.Catalog {
+	t.Helper()
+
+	catalog, err := permission.NewCatalog([]permission.Group{
+		{
+			Name: "users",
+			Permissions: []permission.Permission{
+				{Name: "users:read"},
+				{Name: "users:update", Implies: []string{"users:read"}},
+				{Name: "users:delete"},
+			},
+		},
+		{
+			Name: "roles",
+			Permissions: []permission.Permission{
+				{Name: "roles:read"},
+				{Name: "roles:update", Implies: []string{"roles:read"}},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	return catalog
+}
+
+func newTestAPIKeyService(t *testing.T, grants map[bson.ObjectID][]string) (*APIKeyService, *fakeAPIKeyRepository) {
+	t.Helper()
+
+	catalog := newTestCatalog(t)
+	repo := newFakeAPIKeyRepository()
+	logger := zerolog.Nop()
+	return NewAPIKeyService(repo, &fakePermissionService{catalog: catalog, grants: grants}, catalog, &logger), repo
+}
+
+func statusCode(err error) int {
+	var customErr *errs.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.StatusCode()
+	}
+	return 0
+}
+
+func TestAPIKeyServiceCreate(t *testing.T) {
+	admin := &account.User{ID: bson.NewObjectID()}
+	operator := &account.User{ID: bson.NewObjectID()}
+	serviceAccount := &account.User{ID: bson.NewObjectID(), ServiceAccount: true}
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	grants := map[bson.ObjectID][]string{
+		admin.ID:          {"*"},
+		operator.ID:       {"users:update"},
+		serviceAccount.ID: {"users:*", "roles:read"},
+	}
+
+	tests := []struct {
+		name            string
+		owner           *account.User
+		creator         *account.User
+		request         account.CreateAPIKeyRequest
+		wantStatus      int
+		wantPermissions []string
+	}{
+		{
+			name:            "own key inherits every permission",
+			owner:           operator,
+			creator:         operator,
+			request:         account.CreateAPIKeyRequest{Name: "ci"},
+			wantPermissions: []string{},
+		},
+		{
+			name:            "own key with a subset",
+			owner:           operator,
+			creator:         operator,
+			request:         account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"users:read"}},
+			wantPermissions: []string{"users:read"},
+		},
+		{
+			name:       "unknown permission",
+			owner:      operator,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"reports:read"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "permission not granted to the owner",
+			owner:      operator,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"users:delete"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "wildcard wider than the owner",
+			owner:      operator,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"users:*"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "service account key within the creator's permissions",
+			owner:           serviceAccount,
+			creator:         admin,
+			request:         account.CreateAPIKeyRequest{Name: "ci"},
+			wantPermissions: []string{},
+		},
+		{
+			name:            "service account subset within the creator's permissions",
+			owner:           serviceAccount,
+			creator:         operator,
+			request:         account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"users:update"}},
+			wantPermissions: []string{"users:update"},
+		},
+		{
+			// Owner boleh menghapus user, tetapi pembuatnya tidak
+			name:       "service account permission exceeds the creator",
+			owner:      serviceAccount,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci", Permissions: []string{"users:delete"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			// Key tanpa permission mewarisi roles:read milik service account yang tidak dimiliki pembuatnya
+			name:       "inherited service account permissions exceed the creator",
+			owner:      serviceAccount,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "expiry in the past",
+			owner:      operator,
+			creator:    operator,
+			request:    account.CreateAPIKeyRequest{Name: "ci", ExpiresAt: &past},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "expiry in the future",
+			owner:           operator,
+			creator:         operator,
+			request:         account.CreateAPIKeyRequest{Name: "ci", ExpiresAt: &future},
+			wantPermissions: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestAPIKeyService(t, grants)
+
+			response, err := service.Create(context.Background(), tt.owner, tt.creator, &tt.request)
+			if got := statusCode(err); got != tt.wantStatus {
+				t.Fatalf("Create() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if len(repo.keys) != 0 {
+					t.Errorf("Create() stored %d keys, want none", len(repo.keys))
+				}
+				return
+			}
+
+			key := response.APIKey
+			if !strings.HasPrefix(response.Key, key.Prefix+"_") {
+				t.Errorf("Create() key %q does not start with prefix %q", response.Key, key.Prefix)
+			}
+			if key.KeyHash != helper.HashToken(response.Key) {
+				t.Errorf("Create() stored hash does not match the returned key")
+			}
+			if key.OwnerID != tt.owner.ID || key.CreatedBy != tt.creator.ID {
+				t.Errorf("Create() owner = %v, created by = %v", key.OwnerID, key.CreatedBy)
+			}
+			if strings.Join(key.Permissions, ",") != strings.Join(tt.wantPermissions, ",") || key.Permissions == nil {
+				t.Errorf("Create() permissions = %v, want %v", key.Permissions, tt.wantPermissions)
+			}
+			if _, ok := repo.keys[key.Prefix]; !ok {
+				t.Errorf("Create() key was not stored")
+			}
+		})
+	}
+}
+
+func TestAPIKeyPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawKey string
+		want   string
+		wantOk bool
+	}{
+		{name: "valid key", rawKey: "sk_abc123_secret", want: "sk_abc123", wantOk: true},
+		{name: "other scheme", rawKey: "pk_abc123_secret"},
+		{name: "missing secret", rawKey: "sk_abc123_"},
+		{name: "missing prefix", rawKey: "sk__secret"},
+		{name: "prefix only", rawKey: "sk_abc123"},
+		{name: "extra segment", rawKey: "sk_abc_123_secret"},
+		{name: "empty", rawKey: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := apiKeyPrefix(tt.rawKey)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("apiKeyPrefix() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	owner := &account.User{ID: bson.NewObjectID()}
+	grants := map[bson.ObjectID][]string{owner.ID: {"users:read"}}
+
+	tests := []struct {
+		name       string
+		prepare    func(t *testing.T, service *APIKeyService, rawKey string) string
+		wantStatus int
+	}{
+		{
+			name: "valid key",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				return rawKey
+			},
+		},
+		{
+			name: "wrong secret",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				return rawKey[:strings.LastIndex(rawKey, "_")] + "_wrong"
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown prefix",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				return "sk_unknown_" + rawKey[strings.LastIndex(rawKey, "_")+1:]
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "malformed key",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				return "Bearer " + rawKey
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "revoked key",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				prefix, _ := apiKeyPrefix(rawKey)
+				key, _ := service.repo.FindByPrefix(context.Background(), prefix)
+				if err := service.Revoke(context.Background(), owner.ID.Hex(), key.ID.Hex()); err != nil {
+					t.Fatalf("Revoke() error = %v", err)
+				}
+				return rawKey
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "expired key",
+			prepare: func(t *testing.T, service *APIKeyService, rawKey string) string {
+				prefix, _ := apiKeyPrefix(rawKey)
+				key, _ := service.repo.FindByPrefix(context.Background(), prefix)
+				expired := time.Now().Add(-time.Second)
+				key.ExpiresAt = &expired
+				return rawKey
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestAPIKeyService(t, grants)
+
+			created, err := service.Create(context.Background(), owner, owner, &account.CreateAPIKeyRequest{Name: "ci"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			key, err := service.Authenticate(context.Background(), tt.prepare(t, service, created.Key), "203.0.113.10")
+			if got := statusCode(err); got != tt.wantStatus {
+				t.Fatalf("Authenticate() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if len(repo.lastUsed) != 0 {
+					t.Errorf("Authenticate() recorded usage of a rejected key")
+				}
+				return
+			}
+
+			if key.ID != created.APIKey.ID {
+				t.Errorf("Authenticate() key = %v, want %v", key.ID, created.APIKey.ID)
+			}
+			if key.LastUsedIP != "203.0.113.10" || len(repo.lastUsed) != 1 {
+				t.Errorf("Authenticate() last used ip = %q, updates = %d", key.LastUsedIP, len(repo.lastUsed))
+			}
+
+			// Pemakaian berikutnya dari IP yang sama dalam interval tidak ditulis ulang
+			if _, err := service.Authenticate(context.Background(), created.Key, "203.0.113.10"); err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if len(repo.lastUsed) != 1 {
+				t.Errorf("Authenticate() updates = %d, want 1", len(repo.lastUsed))
+			}
+		})
+	}
+}
+
+func TestAPIKeyServicePermissions(t *testing.T) {
+	service, _ := newTestAPIKeyService(t, nil)
+
+	tests := []struct {
+		name        string
+		keyGrants   []string
+		ownerGrants []string
+		want        []string
+	}{
+		{
+			name:        "key without permissions follows the owner",
+			ownerGrants: []string{"users:update"},
+			want:        []string{"users:read", "users:update"},
+		},
+		{
+			name:        "key limited to a subset",
+			keyGrants:   []string{"users:read"},
+			ownerGrants: []string{"users:update"},
+			want:        []string{"users:read"},
+		},
+		{
+			// Role owner dikurangi setelah key dibuat, key ikut kehilangan akses
+			name:        "owner lost a permission",
+			keyGrants:   []string{"users:update", "roles:read"},
+			ownerGrants: []string{"users:read"},
+			want:        []string{"users:read"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &account.APIKey{Permissions: tt.keyGrants}
+			got := service.Permissions(key, service.catalog.Expand(tt.ownerGrants)).List()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Permissions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/notification"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	"github.com/rs/zerolog"
+)
+
+type EmailVerificationService struct {
+	repo     repository.IUserRepository
+	notifier notification.INotifier
+	logger   *zerolog.Logger
+	config   *configs.Config
+}
+
+func NewEmailVerificationService(repo repository.IUserRepository, notifier notification.INotifier, logger *zerolog.Logger, config *configs.Config) *EmailVerificationService {
+	return &EmailVerificationService{
+		repo:     repo,
+		notifier: notifier,
+		logger:   logger,
+		config:   config,
+	}
+}
+
+// SendVerification mengirim link verifikasi ke PendingEmail, atau ke Email jika belum terverifikasi
+func (e *EmailVerificationService) SendVerification(ctx context.Context, user *account.User) error {
+	email := user.PendingEmail
+	if email == "" {
+		if user.EmailVerified {
+			return nil
+		}
+		email = user.Email
+	}
+
+	ttl := e.verificationTTL()
+	expiresAt := time.Now().Add(ttl).Unix()
+	payload := strings.Join([]string{user.ID.Hex(), email, strconv.FormatInt(expiresAt, 10)}, "|")
+	token := helper.SignPayload(e.secret(), payload)
+
+	message := notification.Message{
+		To:       email,
+		Template: "email_verification",
+		Data: map[string]interface{}{
+			"Name":           user.Name,
+			"Email":          email,
+			"Link":           e.config.Security.EmailVerificationURL + "?token=" + url.QueryEscape(token),
+			"ExpiresInHours": int(ttl.Hours()),
+		},
+	}
+	if err := e.notifier.Send(ctx, message); err != nil {
+		e.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to send verification message")
+		return errs.Internal("failed to send verification message", err)
+	}
+
+	return nil
+}
+
+// Verify mengkonfirmasi email dari token verifikasi. Untuk perubahan email, Email diganti dengan PendingEmail.
+func (e *EmailVerificationService) Verify(ctx context.Context, token string) error {
+	userID, email, err := e.parseToken(token)
+	if err != nil {
+		return errs.BadRequest("invalid or expired verification link", err)
+	}
+
+	// Email bisa sudah dipakai user lain sejak link dikirim, unique index email menolak konfirmasi yang berjalan bersamaan
+	if other, err := e.repo.FindByEmail(ctx, email); err == nil && other.ID.Hex() != userID {
+		return errs.BadRequest("email exist", nil)
+	}
+
+	confirmed, err := e.repo.ConfirmEmail(ctx, userID, email)
+	if err != nil {
+		e.logger.Error().Err(err).Str("user_id", userID).Msg("failed to confirm email")
+		return err
+	}
+
+	if !confirmed {
+		user, err := e.repo.FindById(ctx, userID)
+		if err == nil && user.Email == email && user.EmailVerified {
+			return nil
+		}
+		return errs.BadRequest("invalid or expired verification link", nil)
+	}
+
+	e.logger.Info().Str("event", "account.email_verified").Str("user_id", userID).Str("email", email).Msg("email verified")
+	return nil
+}
+
+// Resend mengirim ulang link verifikasi. Pencarian user dan pengiriman email berjalan di background
+// sehingga email terdaftar maupun tidak selalu mendapat respons yang sama dengan waktu yang sama,
+// agar endpoint tidak bisa dipakai untuk menebak email user.
+func (e *EmailVerificationService) Resend(ctx context.Context, request account.ResendVerificationRequest) error {
+	go e.resend(context.WithoutCancel(ctx), request.Email)
+	return nil
+}
+
+func (e *EmailVerificationService) resend(ctx context.Context, email string) {
+	user, err := e.repo.FindByEmail(ctx, email)
+	if err != nil {
+		e.logger.Info().Str("email", email).Msg("verification resend requested for unknown email")
+		return
+	}
+
+	// Error sudah dicatat oleh SendVerification
+	_ = e.SendVerification(ctx, user)
+}
+
+func (e *EmailVerificationService) parseToken(token string) (string, string, error) {
+	payload, err := helper.VerifySignedPayload(e.secret(), token)
+	if err != nil {
+		return "", "", err
+	}
+
+	parts := strings.Split(payload, "|")
+	if len(parts) != 3 {
+		return "", "", fmt.Errorf("malformed verification token")
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("malformed verification token")
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", "", fmt.Errorf("verification token expired")
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// secret mengembalikan key HMAC link verifikasi, LoadConfig memastikan nilainya tidak kosong
+func (e *EmailVerificationService) secret() []byte {
+	return []byte(e.config.Security.EmailVerificationSecret)
+}
+
+func (e *EmailVerificationService) verificationTTL() time.Duration {
+	return time.Duration(e.config.Security.EmailVerificationExpired) * time.Hour
+}
@@ -0,0 +1,290 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/notification"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type InvitationService struct {
+	repo        repository.IInvitationRepository
+	rolerepo    repository.IRoleRepository
+	userservice IUserService
+	permissions IPermissionService
+	catalog     *permission.Catalog
+	notifier    notification.INotifier
+	logger      *zerolog.Logger
+	config      *configs.Config
+}
+
+func NewInvitationService(repo repository.IInvitationRepository, rolerepo repository.IRoleRepository, userservice IUserService, permissions IPermissionService, catalog *permission.Catalog, notifier notification.INotifier, logger *zerolog.Logger, config *configs.Config) *InvitationService {
+	return &InvitationService{
+		repo:        repo,
+		rolerepo:    rolerepo,
+		userservice: userservice,
+		permissions: permissions,
+		catalog:     catalog,
+		notifier:    notifier,
+		logger:      logger,
+		config:      config,
+	}
+}
+
+func (i *InvitationService) Create(ctx context.Context, inviter *account.User, request *account.CreateInvitationRequest) (*account.Invitation, error) {
+	if _, err := i.userservice.FindByEmail(ctx, request.Email); err == nil {
+		return nil, errs.BadRequest("email exist", nil)
+	}
+
+	if _, err := i.repo.FindPendingByEmail(ctx, request.Email); err == nil {
+		return nil, errs.BadRequest("a pending invitation already exists for this email", nil)
+	}
+
+	roles, err := i.resolveRoles(ctx, inviter, request.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := helper.GenerateRandomString(32)
+	if err != nil {
+		i.logger.Error().Err(err).Msg("failed to generate invitation token")
+		return nil, errs.Internal("failed to create invitation", err)
+	}
+
+	invitation := account.Invitation{
+		ID:        bson.NewObjectID(),
+		Email:     request.Email,
+		Roles:     roles,
+		TokenHash: helper.HashToken(token),
+		Status:    account.InvitationPending,
+		InvitedBy: inviter.ID,
+		ExpiresAt: time.Now().Add(i.invitationTTL()),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := i.repo.Create(ctx, &invitation); err != nil {
+		i.logger.Error().Err(err).Str("email", request.Email).Msg("failed to create invitation")
+		return nil, err
+	}
+
+	if err := i.send(ctx, inviter, &invitation, token); err != nil {
+		return nil, err
+	}
+
+	i.logger.Info().Str("event", "account.invitation_created").Str("invitation_id", invitation.ID.Hex()).Str("invited_by", inviter.ID.Hex()).Msg("invitation created")
+	return &invitation, nil
+}
+
+func (i *InvitationService) FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Invitation, int64, error) {
+	invitations, totalItems, err := i.repo.FindAll(ctx, filter)
+	if err != nil {
+		i.logger.Error().Err(err).
+			Str("search", filter.Search).
+			Int("page", filter.Page).
+			Int("limit", filter.Limit).
+			Msg("error from repo")
+
+		return &[]account.Invitation{}, 0, err
+	}
+
+	return invitations, int64(totalItems), nil
+}
+
+// Resend membuat token baru untuk undangan yang masih pending, link lama otomatis tidak berlaku
+func (i *InvitationService) Resend(ctx context.Context, inviter *account.User, id string) error {
+	invitation, err := i.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if invitation.Status != account.InvitationPending {
+		return errs.BadRequest("invitation is no longer pending", nil)
+	}
+
+	token, err := helper.GenerateRandomString(32)
+	if err != nil {
+		i.logger.Error().Err(err).Msg("failed to generate invitation token")
+		return errs.Internal("failed to resend invitation", err)
+	}
+
+	invitation.TokenHash = helper.HashToken(token)
+	invitation.ExpiresAt = time.Now().Add(i.invitationTTL())
+	if err := i.repo.UpdateToken(ctx, id, invitation.TokenHash, invitation.ExpiresAt); err != nil {
+		return err
+	}
+
+	return i.send(ctx, inviter, invitation, token)
+}
+
+func (i *InvitationService) Revoke(ctx context.Context, id string) error {
+	revoked, err := i.repo.UpdateStatus(ctx, id, account.InvitationRevoked)
+	if err != nil {
+		i.logger.Error().Err(err).Str("invitation_id", id).Msg("failed to revoke invitation")
+		return err
+	}
+	if !revoked {
+		return errs.BadRequest("invitation is no longer pending", nil)
+	}
+
+	return nil
+}
+
+// Accept membuat akun untuk undangan yang valid lalu memasang role yang sudah dipilih admin
+func (i *InvitationService) Accept(ctx context.Context, request *account.AcceptInvitationRequest) (*account.UserResponse, error) {
+	invitation, err := i.repo.FindPendingByTokenHash(ctx, helper.HashToken(request.Token))
+	if err != nil {
+		return nil, errs.BadRequest("invalid or expired invitation", err)
+	}
+
+	// Password dicek sebelum undangan diklaim agar password yang ditolak tidak menghanguskan undangan
+	if err := i.userservice.ValidatePassword(ctx, &account.User{Email: invitation.Email, Name: request.Name}, request.Password); err != nil {
+		return nil, err
+	}
+
+	// Undangan diklaim secara atomik (pending -> accepted) sebelum user dibuat, sehingga accept yang
+	// berjalan bersamaan hanya menghasilkan satu user
+	accepted, err := i.repo.UpdateStatus(ctx, invitation.ID.Hex(), account.InvitationAccepted)
+	if err != nil {
+		i.logger.Error().Err(err).Str("invitation_id", invitation.ID.Hex()).Msg("failed to claim invitation")
+		return nil, err
+	}
+	if !accepted {
+		return nil, errs.BadRequest("invalid or expired invitation", nil)
+	}
+
+	// Email dianggap terverifikasi karena token undangan hanya dikirim ke email tersebut
+	user, err := i.userservice.Create(ctx, &account.CreateUserRequest{
+		Email:         invitation.Email,
+		Name:          request.Name,
+		Password:      request.Password,
+		EmailVerified: true,
+	})
+	if err != nil {
+		i.logger.Error().Err(err).Str("invitation_id", invitation.ID.Hex()).Msg("failed to create user for claimed invitation")
+		i.release(ctx, invitation, nil)
+		return nil, err
+	}
+
+	for _, roleID := range invitation.Roles {
+		if err := i.rolerepo.AssignUser(ctx, user.ID.Hex(), roleID.Hex()); err != nil {
+			i.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Str("role_id", roleID.Hex()).Msg("failed to assign invited role")
+			i.release(ctx, invitation, user)
+			return nil, err
+		}
+	}
+
+	user.Roles = invitation.Roles
+	if roles, err := i.rolerepo.FindManyByID(ctx, invitation.Roles); err == nil {
+		user.RolesDetail = roles
+	}
+
+	i.logger.Info().Str("event", "account.invitation_accepted").Str("invitation_id", invitation.ID.Hex()).Str("user_id", user.ID.Hex()).Msg("invitation accepted")
+	return user.ToUserResponse(), nil
+}
+
+// release membatalkan klaim undangan yang gagal diselesaikan: user yang sudah dibuat (dengan role yang baru
+// sebagian terpasang) dihapus dan undangan dikembalikan ke pending agar link undangan bisa dipakai lagi
+func (i *InvitationService) release(ctx context.Context, invitation *account.Invitation, user *account.User) {
+	// Tetap dijalankan walaupun request sudah dibatalkan, pembatalan bisa menjadi penyebab kegagalannya
+	ctx = context.WithoutCancel(ctx)
+
+	if user != nil {
+		if err := i.userservice.Delete(ctx, user.ID.Hex()); err != nil {
+			i.logger.Error().Err(err).Str("invitation_id", invitation.ID.Hex()).Str("user_id", user.ID.Hex()).Msg("failed to remove user of failed invitation")
+			return
+		}
+	}
+
+	if err := i.repo.Reopen(ctx, invitation.ID.Hex()); err != nil {
+		i.logger.Error().Err(err).Str("invitation_id", invitation.ID.Hex()).Msg("failed to reopen invitation")
+	}
+}
+
+// resolveRoles memastikan seluruh role yang dipilih ada dan tidak melebihi hak pengundang.
+// Pengundang harus memiliki roles:assign dan seluruh permission dari role tersebut agar undangan tidak bisa
+// dipakai untuk memberi hak yang lebih tinggi dari milik pengundang.
+func (i *InvitationService) resolveRoles(ctx context.Context, inviter *account.User, ids []string) ([]bson.ObjectID, error) {
+	roles := []bson.ObjectID{}
+	seen := make(map[bson.ObjectID]struct{})
+	for _, id := range ids {
+		objectID, err := bson.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, errs.BadRequest("invalid roleID format", err)
+		}
+		if _, ok := seen[objectID]; ok {
+			continue
+		}
+		seen[objectID] = struct{}{}
+		roles = append(roles, objectID)
+	}
+
+	if len(roles) == 0 {
+		return roles, nil
+	}
+
+	found, err := i.rolerepo.FindManyByID(ctx, roles)
+	if err != nil || len(*found) != len(roles) {
+		return nil, errs.BadRequest("invalid role", fmt.Errorf("one or more roles not found: %v", ids))
+	}
+
+	inviterPermissions, err := i.permissions.Resolve(ctx, inviter)
+	if err != nil {
+		return nil, err
+	}
+	if !inviterPermissions.Has("roles:assign") {
+		return nil, errs.Forbidden("you are not allowed to assign roles", nil)
+	}
+
+	var grants []string
+	for _, role := range *found {
+		grants = append(grants, role.Permissions...)
+	}
+
+	var exceeded []string
+	for _, p := range i.catalog.Resolve(grants).List() {
+		if !inviterPermissions.Has(p) {
+			exceeded = append(exceeded, p)
+		}
+	}
+	if len(exceeded) > 0 {
+		return nil, errs.Forbidden("roles exceed the inviter's permissions", fmt.Errorf("permissions not granted to inviter: %v", exceeded))
+	}
+
+	return roles, nil
+}
+
+func (i *InvitationService) send(ctx context.Context, inviter *account.User, invitation *account.Invitation, token string) error {
+	ttl := i.invitationTTL()
+	message := notification.Message{
+		To:       invitation.Email,
+		Template: "invitation",
+		Data: map[string]interface{}{
+			"AppName":        i.config.AppName,
+			"InvitedBy":      inviter.Name,
+			"Link":           i.config.Security.InvitationURL + "?token=" + url.QueryEscape(token),
+			"ExpiresInHours": int(ttl.Hours()),
+		},
+	}
+	if err := i.notifier.Send(ctx, message); err != nil {
+		i.logger.Error().Err(err).Str("invitation_id", invitation.ID.Hex()).Msg("failed to send invitation message")
+		return errs.Internal("failed to send invitation message", err)
+	}
+
+	return nil
+}
+
+func (i *InvitationService) invitationTTL() time.Duration {
+	return time.Duration(i.config.Security.InvitationExpired) * time.Hour
+}
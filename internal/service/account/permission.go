@@ -0,0 +1,121 @@
+package account
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PermissionService menghitung permission efektif user dan menyimpannya di cache LRU per instance.
+// Perubahan role disebarkan ke instance lain lewat Redis pub/sub, TTL cache membatasi data basi
+// jika pesan invalidasi terlewat.
+type PermissionService struct {
+	rolerepo     repository.IRoleRepository
+	invalidation repository.IPermissionInvalidationRepository
+	catalog      *permission.Catalog
+	cache        *permission.Cache
+	logger       *zerolog.Logger
+	cancel       context.CancelFunc
+}
+
+func NewPermissionService(rolerepo repository.IRoleRepository, invalidation repository.IPermissionInvalidationRepository, catalog *permission.Catalog, logger *zerolog.Logger, config *configs.Config) *PermissionService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &PermissionService{
+		rolerepo:     rolerepo,
+		invalidation: invalidation,
+		catalog:      catalog,
+		cache:        permission.NewCache(config.Security.PermissionCacheSize, time.Duration(config.Security.PermissionCacheTTL)*time.Second),
+		logger:       logger,
+		cancel:       cancel,
+	}
+
+	go invalidation.Subscribe(ctx, p.evict)
+
+	return p
+}
+
+// Resolve mengembalikan permission efektif user, role hanya diambil dari database ketika cache kosong
+func (p *PermissionService) Resolve(ctx context.Context, user *account.User) (permission.Set, error) {
+	userID := user.ID.Hex()
+	rolesKey := permissionRolesKey(user.Roles)
+
+	if set, ok := p.cache.Get(userID, rolesKey); ok {
+		return set, nil
+	}
+
+	// Epoch diambil sebelum membaca role, invalidasi yang terjadi selama resolve membuat hasilnya tidak disimpan
+	epoch := p.cache.Epoch()
+
+	roles := user.RolesDetail
+	if roles == nil && len(user.Roles) > 0 {
+		found, err := p.rolerepo.FindManyByID(ctx, user.Roles)
+		if err != nil && !errs.IsNotFound(err) {
+			p.logger.Error().Err(err).Str("user_id", userID).Msg("failed to load roles for permission resolution")
+			return nil, err
+		}
+		roles = found
+	}
+
+	var grants []string
+	if roles != nil {
+		for _, role := range *roles {
+			grants = append(grants, role.Permissions...)
+		}
+	}
+
+	set := p.catalog.Resolve(grants)
+	p.cache.Add(userID, rolesKey, set, epoch)
+
+	return set, nil
+}
+
+// InvalidateUser membuang cache permission satu user di seluruh instance
+func (p *PermissionService) InvalidateUser(ctx context.Context, userID string) {
+	p.cache.Remove(userID)
+	p.publish(ctx, userID)
+}
+
+// InvalidateAll membuang seluruh cache permission, dipakai ketika isi role berubah
+func (p *PermissionService) InvalidateAll(ctx context.Context) {
+	p.cache.Purge()
+	p.publish(ctx, repository.InvalidateAllPermissions)
+}
+
+// Close menghentikan subscription invalidasi
+func (p *PermissionService) Close() {
+	p.cancel()
+}
+
+func (p *PermissionService) publish(ctx context.Context, userID string) {
+	if err := p.invalidation.Publish(ctx, userID); err != nil {
+		p.logger.Warn().Err(err).Str("user_id", userID).Msg("failed to broadcast permission invalidation, other instances rely on cache TTL")
+	}
+}
+
+func (p *PermissionService) evict(userID string) {
+	if userID == repository.InvalidateAllPermissions {
+		p.cache.Purge()
+		return
+	}
+	p.cache.Remove(userID)
+}
+
+// permissionRolesKey membentuk key dari daftar role user agar assign/unassign langsung terlihat
+func permissionRolesKey(roles []bson.ObjectID) string {
+	ids := make([]string, len(roles))
+	for i, id := range roles {
+		ids[i] = id.Hex()
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
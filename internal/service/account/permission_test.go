@@ -0,0 +1,136 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeRoleRepository hanya mengimplementasikan FindManyByID, method lain panic karena interface yang di-embed bernilai nil
+type fakeRoleRepository struct {
+	repository.IRoleRepository
+	roles map[bson.ObjectID]account.Role
+	calls int
+	// onFind dijalankan di tengah resolve, setelah role dibaca dan sebelum hasilnya disimpan ke cache
+	onFind func()
+}
+
+func (f *fakeRoleRepository) FindManyByID(ctx context.Context, ids []bson.ObjectID) (*[]account.Role, error) {
+	f.calls++
+
+	roles := []account.Role{}
+	for _, id := range ids {
+		if role, ok := f.roles[id]; ok {
+			roles = append(roles, role)
+		}
+	}
+
+	if f.onFind != nil {
+		f.onFind()
+	}
+	return &roles, nil
+}
+
+type fakePermissionInvalidation struct {
+	published []string
+}
+
+func (f *fakePermissionInvalidation) Publish(ctx context.Context, userID string) error {
+	f.published = append(f.published, userID)
+	return nil
+}
+
+func (f *fakePermissionInvalidation) Subscribe(ctx context.Context, handler func(userID string)) {}
+
+func TestPermissionServiceResolveCache(t *testing.T) {
+	roleID := bson.NewObjectID()
+	user := &account.User{ID: bson.NewObjectID(), Roles: []bson.ObjectID{roleID}}
+	other := &account.User{ID: bson.NewObjectID()}
+
+	tests := []struct {
+		name string
+		// invalidate dijalankan saat role sedang dibaca pada resolve pertama
+		invalidate func(service *PermissionService)
+		wantCalls  int
+		wantCached int
+	}{
+		{
+			name:       "resolved permissions are cached",
+			wantCalls:  1,
+			wantCached: 1,
+		},
+		{
+			name: "user invalidated during resolve",
+			invalidate: func(service *PermissionService) {
+				service.InvalidateUser(context.Background(), user.ID.Hex())
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "roles changed during resolve",
+			invalidate: func(service *PermissionService) {
+				service.InvalidateAll(context.Background())
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "invalidation from another instance during resolve",
+			invalidate: func(service *PermissionService) {
+				service.evict(repository.InvalidateAllPermissions)
+			},
+			wantCalls: 2,
+		},
+		{
+			// Epoch bersifat global, invalidasi user lain juga membatalkan penyimpanan
+			name: "other user invalidated during resolve",
+			invalidate: func(service *PermissionService) {
+				service.InvalidateUser(context.Background(), other.ID.Hex())
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := newTestCatalog(t)
+			roles := &fakeRoleRepository{roles: map[bson.ObjectID]account.Role{
+				roleID: {ID: roleID, Permissions: []string{"users:update"}},
+			}}
+			logger := zerolog.Nop()
+			config := &configs.Config{Security: configs.SecurityConfig{PermissionCacheSize: 10, PermissionCacheTTL: 60}}
+
+			service := NewPermissionService(roles, &fakePermissionInvalidation{}, catalog, &logger, config)
+			defer service.Close()
+
+			if tt.invalidate != nil {
+				roles.onFind = func() {
+					roles.onFind = nil
+					tt.invalidate(service)
+				}
+			}
+
+			set, err := service.Resolve(context.Background(), user)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if !set.Has("users:read") {
+				t.Errorf("Resolve() = %v, want users:read through users:update", set.List())
+			}
+			if got := service.cache.Len(); got != tt.wantCached {
+				t.Errorf("cache Len() = %d, want %d", got, tt.wantCached)
+			}
+
+			if _, err := service.Resolve(context.Background(), user); err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if roles.calls != tt.wantCalls {
+				t.Errorf("FindManyByID() calls = %d, want %d", roles.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -6,40 +6,31 @@ import (
 	"time"
 
 	"github.com/HasanNugroho/golang-starter/internal/errs"
-	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"github.com/HasanNugroho/golang-starter/internal/model"
 	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
 	repo "github.com/HasanNugroho/golang-starter/internal/repository/account"
 	"github.com/rs/zerolog"
 )
 
 type RoleService struct {
-	repo       repo.IRoleRepository
-	logger     *zerolog.Logger
-	permMaster map[string]struct{}
+	repo        repo.IRoleRepository
+	logger      *zerolog.Logger
+	permMaster  *permission.Catalog
+	permissions IPermissionService
 }
 
-func NewRoleService(repo repo.IRoleRepository, logger *zerolog.Logger) (*RoleService, error) {
-	perm, err := helper.LoadStringListFromYAML("./internal/constant/data.yaml", "permission")
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to load permission data")
-		return nil, err
-	}
+func NewRoleService(repo repo.IRoleRepository, logger *zerolog.Logger, catalog *permission.Catalog, permissions IPermissionService) *RoleService {
 	return &RoleService{
-		repo:       repo,
-		logger:     logger,
-		permMaster: perm,
-	}, nil
+		repo:        repo,
+		logger:      logger,
+		permMaster:  catalog,
+		permissions: permissions,
+	}
 }
 
 func (r *RoleService) Create(ctx context.Context, role *account.CreateRoleRequest) error {
-	var invalid []string
-	for _, p := range role.Permissions {
-		if _, ok := r.permMaster[p]; !ok {
-			invalid = append(invalid, p)
-		}
-	}
-	if len(invalid) > 0 {
+	if invalid := r.permMaster.Validate(role.Permissions); len(invalid) > 0 {
 		return errs.BadRequest("invalid permission", fmt.Errorf("invalid permissions: %v", invalid))
 	}
 
@@ -67,6 +58,15 @@ func (r *RoleService) FindById(ctx context.Context, id string) (*account.Role, e
 	return role, err
 }
 
+func (r *RoleService) FindByName(ctx context.Context, name string) (*account.Role, error) {
+	role, err := r.repo.FindByName(ctx, name)
+	if err != nil {
+		r.logger.Error().Err(err).Str("role", name).Msg("error from repo")
+		return &account.Role{}, err
+	}
+	return role, err
+}
+
 func (r *RoleService) FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Role, int64, error) {
 	roles, totalItems, err := r.repo.FindAll(ctx, filter)
 	if err != nil {
@@ -94,42 +94,51 @@ func (r *RoleService) Update(ctx context.Context, id string, role *account.Updat
 	}
 
 	if role.Permissions != nil {
-		var invalid []string
-		for _, p := range role.Permissions {
-			if _, ok := r.permMaster[p]; !ok {
-				invalid = append(invalid, p)
-			}
-		}
-		if len(invalid) > 0 {
+		if invalid := r.permMaster.Validate(role.Permissions); len(invalid) > 0 {
 			return errs.BadRequest("invalid permission", fmt.Errorf("invalid permissions: %v", invalid))
 		}
 
 		currentRole.Permissions = role.Permissions
 	}
 
-	return r.repo.Update(ctx, id, currentRole)
+	if err := r.repo.Update(ctx, id, currentRole); err != nil {
+		return err
+	}
+
+	// Role bisa dimiliki banyak user, seluruh cache permission dibuang
+	r.permissions.InvalidateAll(ctx)
+	return nil
 }
 
 func (r *RoleService) Delete(ctx context.Context, id string) error {
 	err := r.repo.Delete(ctx, id)
 	if err != nil {
 		r.logger.Error().Err(err).Str("role", id).Msg("failed to delete data")
+		return err
 	}
-	return err
+
+	r.permissions.InvalidateAll(ctx)
+	return nil
 }
 
 func (r *RoleService) AssignUser(ctx context.Context, payload *account.AssignRoleModel) error {
 	err := r.repo.AssignUser(ctx, payload.UserID, payload.RoleID)
 	if err != nil {
 		r.logger.Error().Err(err).Fields(payload).Msg("failed to assign user")
+		return err
 	}
-	return err
+
+	r.permissions.InvalidateUser(ctx, payload.UserID)
+	return nil
 }
 
 func (r *RoleService) UnassignUser(ctx context.Context, payload *account.AssignRoleModel) error {
 	err := r.repo.UnassignUser(ctx, payload.UserID, payload.RoleID)
 	if err != nil {
 		r.logger.Error().Err(err).Fields(payload).Msg("failed to unassign user")
+		return err
 	}
-	return err
+
+	r.permissions.InvalidateUser(ctx, payload.UserID)
+	return nil
 }
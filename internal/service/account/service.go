@@ -2,28 +2,70 @@ package account
 
 import (
 	"context"
+	"time"
 
 	"github.com/HasanNugroho/golang-starter/internal/model"
 	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
 )
 
 type (
 	IUserService interface {
-		Create(ctx context.Context, user *account.CreateUserRequest) error
+		Create(ctx context.Context, user *account.CreateUserRequest) (*account.User, error)
+		CreateServiceAccount(ctx context.Context, request *account.CreateServiceAccountRequest) (*account.User, error)
 		FindById(ctx context.Context, id string) (*account.User, error)
+		FindByIdWithoutRoles(ctx context.Context, id string) (*account.User, error)
 		FindByEmail(ctx context.Context, email string) (*account.User, error)
 		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.UserResponse, int64, error)
 		Update(ctx context.Context, id string, user *account.UpdateUserRequest) error
+		ValidatePassword(ctx context.Context, user *account.User, newPassword string) error
+		Lock(ctx context.Context, id string, until time.Time) error
+		Unlock(ctx context.Context, id string) error
+		Disable(ctx context.Context, id string) error
+		Enable(ctx context.Context, id string) error
+		UpdateMFA(ctx context.Context, id string, secret string, enabled bool) error
+		UpdateRecoveryCodes(ctx context.Context, id string, hashes []string) error
+		UseTOTPStep(ctx context.Context, id string, step int64) (bool, error)
+		ConsumeRecoveryCode(ctx context.Context, id string, hash string) (bool, error)
 		Delete(ctx context.Context, id string) error
 	}
 
+	IEmailVerificationService interface {
+		SendVerification(ctx context.Context, user *account.User) error
+		Verify(ctx context.Context, token string) error
+		Resend(ctx context.Context, request account.ResendVerificationRequest) error
+	}
+
+	IInvitationService interface {
+		Create(ctx context.Context, inviter *account.User, request *account.CreateInvitationRequest) (*account.Invitation, error)
+		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Invitation, int64, error)
+		Resend(ctx context.Context, inviter *account.User, id string) error
+		Revoke(ctx context.Context, id string) error
+		Accept(ctx context.Context, request *account.AcceptInvitationRequest) (*account.UserResponse, error)
+	}
+
 	IRoleService interface {
 		Create(ctx context.Context, user *account.CreateRoleRequest) error
 		FindById(ctx context.Context, id string) (*account.Role, error)
+		FindByName(ctx context.Context, name string) (*account.Role, error)
 		FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.Role, int64, error)
 		Update(ctx context.Context, id string, role *account.UpdateRoleRequest) error
 		Delete(ctx context.Context, id string) error
 		AssignUser(ctx context.Context, payload *account.AssignRoleModel) error
 		UnassignUser(ctx context.Context, payload *account.AssignRoleModel) error
 	}
+
+	IAPIKeyService interface {
+		Create(ctx context.Context, owner *account.User, creator *account.User, request *account.CreateAPIKeyRequest) (*account.CreateAPIKeyResponse, error)
+		FindByOwner(ctx context.Context, ownerID string) (*[]account.APIKey, error)
+		Revoke(ctx context.Context, ownerID string, id string) error
+		Authenticate(ctx context.Context, rawKey string, ip string) (*account.APIKey, error)
+		Permissions(key *account.APIKey, owner permission.Set) permission.Set
+	}
+
+	IPermissionService interface {
+		Resolve(ctx context.Context, user *account.User) (permission.Set, error)
+		InvalidateUser(ctx context.Context, userID string)
+		InvalidateAll(ctx context.Context)
+	}
 )
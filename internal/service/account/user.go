@@ -2,58 +2,118 @@ package account
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/HasanNugroho/golang-starter/internal/errs"
 	"github.com/HasanNugroho/golang-starter/internal/helper"
 	"github.com/HasanNugroho/golang-starter/internal/model"
 	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/password"
 	repository "github.com/HasanNugroho/golang-starter/internal/repository/account"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 type UserService struct {
-	repo     repository.IUserRepository
-	rolerepo repository.IRoleRepository
-	logger   *zerolog.Logger
+	repo         repository.IUserRepository
+	rolerepo     repository.IRoleRepository
+	verification IEmailVerificationService
+	policy       *password.Policy
+	logger       *zerolog.Logger
 }
 
-func NewUserService(repo repository.IUserRepository, rolerepo repository.IRoleRepository, logger *zerolog.Logger) *UserService {
+func NewUserService(repo repository.IUserRepository, rolerepo repository.IRoleRepository, verification IEmailVerificationService, policy *password.Policy, logger *zerolog.Logger) *UserService {
 	return &UserService{
-		repo:     repo,
-		rolerepo: rolerepo,
-		logger:   logger,
+		repo:         repo,
+		rolerepo:     rolerepo,
+		verification: verification,
+		policy:       policy,
+		logger:       logger,
 	}
 }
 
-func (u *UserService) Create(ctx context.Context, user *account.CreateUserRequest) error {
+func (u *UserService) Create(ctx context.Context, user *account.CreateUserRequest) (*account.User, error) {
 	_, err := u.repo.FindByEmail(ctx, user.Email)
 	if err == nil {
-		return errs.BadRequest("email exist", err)
+		return nil, errs.BadRequest("email exist", err)
 	}
 
-	password, err := helper.HashPassword([]byte(user.Password))
+	if !user.RandomPassword {
+		if violations := u.policy.Validate(user.Password, user.Email, user.Name); len(violations) > 0 {
+			return nil, passwordPolicyError(violations)
+		}
+	}
+
+	hashedPassword, err := helper.HashPassword([]byte(user.Password))
 	if err != nil {
 		u.logger.Error().Err(err).Msg("failed to hash password")
-		return err
+		return nil, err
 	}
 
+	now := time.Now()
 	payload := account.User{
-		Email:     user.Email,
-		Name:      user.Name,
-		Roles:     []bson.ObjectID{},
-		Password:  password,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:                bson.NewObjectID(),
+		Email:             user.Email,
+		Name:              user.Name,
+		Roles:             []bson.ObjectID{},
+		Password:          hashedPassword,
+		PasswordChangedAt: &now,
+		EmailVerified:     user.EmailVerified,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if user.EmailVerified {
+		payload.VerifiedAt = &payload.CreatedAt
 	}
 
 	if err = u.repo.Create(ctx, &payload); err != nil {
 		u.logger.Error().Err(err).Fields(payload).Msg("failed to create data")
-		return err
+		return nil, err
 	}
 
-	return nil
+	// User tetap dibuat walaupun email gagal terkirim, link bisa dikirim ulang
+	if err := u.verification.SendVerification(ctx, &payload); err != nil {
+		u.logger.Warn().Err(err).Str("user_id", payload.ID.Hex()).Msg("failed to send verification email")
+	}
+
+	return &payload, nil
+}
+
+// CreateServiceAccount membuat user untuk otomasi. Email memakai domain .invalid yang tidak bisa
+// menerima email dan password diisi acak sehingga akun tidak bisa dipakai login.
+func (u *UserService) CreateServiceAccount(ctx context.Context, request *account.CreateServiceAccountRequest) (*account.User, error) {
+	secret, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return nil, errs.Internal("failed to create service account", err)
+	}
+
+	hashedPassword, err := helper.HashPassword([]byte(secret))
+	if err != nil {
+		u.logger.Error().Err(err).Msg("failed to hash password")
+		return nil, err
+	}
+
+	now := time.Now()
+	payload := account.User{
+		ID:             bson.NewObjectID(),
+		Name:           request.Name,
+		Roles:          []bson.ObjectID{},
+		Password:       hashedPassword,
+		EmailVerified:  true,
+		VerifiedAt:     &now,
+		ServiceAccount: true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	payload.Email = payload.ID.Hex() + "@service-account.invalid"
+
+	if err := u.repo.Create(ctx, &payload); err != nil {
+		u.logger.Error().Err(err).Fields(payload).Msg("failed to create service account")
+		return nil, err
+	}
+
+	return &payload, nil
 }
 
 func (u *UserService) FindByEmail(ctx context.Context, email string) (*account.User, error) {
@@ -86,6 +146,18 @@ func (u *UserService) FindById(ctx context.Context, id string) (*account.User, e
 	return user, nil
 }
 
+// FindByIdWithoutRoles mengambil user tanpa detail role, dipakai di jalur request yang
+// permission-nya sudah diambil dari cache
+func (u *UserService) FindByIdWithoutRoles(ctx context.Context, id string) (*account.User, error) {
+	user, err := u.repo.FindById(ctx, id)
+	if err != nil {
+		u.logger.Error().Err(err).Str("userID", id).Msg("error from repo")
+		return &account.User{}, err
+	}
+
+	return user, nil
+}
+
 func (u *UserService) FindAll(ctx context.Context, filter *model.PaginationFilter) (*[]account.UserResponse, int64, error) {
 	users, totalItems, err := u.repo.FindAll(ctx, filter)
 	if err != nil {
@@ -101,11 +173,18 @@ func (u *UserService) FindAll(ctx context.Context, filter *model.PaginationFilte
 	var usersResponse []account.UserResponse
 	for _, user := range *users {
 		usersResponse = append(usersResponse, account.UserResponse{
-			ID:        user.ID.Hex(),
-			Email:     user.Email,
-			Name:      user.Name,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
+			ID:             user.ID.Hex(),
+			Email:          user.Email,
+			Name:           user.Name,
+			EmailVerified:  user.EmailVerified,
+			VerifiedAt:     user.VerifiedAt,
+			PendingEmail:   user.PendingEmail,
+			LockedUntil:    user.LockedUntil,
+			Disabled:       user.Disabled,
+			TOTPEnabled:    user.TOTPEnabled,
+			ServiceAccount: user.ServiceAccount,
+			CreatedAt:      user.CreatedAt,
+			UpdatedAt:      user.UpdatedAt,
 		})
 	}
 	return &usersResponse, int64(totalItems), nil
@@ -118,8 +197,17 @@ func (u *UserService) Update(ctx context.Context, id string, user *account.Updat
 		return err
 	}
 
-	if user.Email != "" {
-		existingUser.Email = user.Email
+	// Email baru disimpan sebagai pending, email lama tetap dipakai login sampai email baru diverifikasi
+	emailChanged := false
+	if user.Email != "" && user.Email != existingUser.Email && user.Email != existingUser.PendingEmail {
+		if _, err := u.repo.FindByEmail(ctx, user.Email); err == nil {
+			return errs.BadRequest("email exist", nil)
+		}
+		existingUser.PendingEmail = user.Email
+		emailChanged = true
+	} else if user.Email == existingUser.Email {
+		// Mengisi email lama membatalkan perubahan email yang belum diverifikasi
+		existingUser.PendingEmail = ""
 	}
 
 	if user.Name != "" {
@@ -127,12 +215,21 @@ func (u *UserService) Update(ctx context.Context, id string, user *account.Updat
 	}
 
 	if user.Password != "" {
+		// Policy dicek terhadap nama dan email setelah perubahan agar password tidak memuat data pribadi yang baru
+		if err := u.ValidatePassword(ctx, existingUser, user.Password); err != nil {
+			return err
+		}
+
 		hashedPassword, err := helper.HashPassword([]byte(user.Password))
 		if err != nil {
 			u.logger.Error().Err(err).Msg("failed to hash password")
 			return err
 		}
+
+		now := time.Now()
+		existingUser.PasswordHistory = u.policy.NextHistory(existingUser.Password, existingUser.PasswordHistory)
 		existingUser.Password = hashedPassword
+		existingUser.PasswordChangedAt = &now
 	}
 
 	if err := u.repo.Update(ctx, id, existingUser); err != nil {
@@ -140,9 +237,106 @@ func (u *UserService) Update(ctx context.Context, id string, user *account.Updat
 		return err
 	}
 
+	if emailChanged {
+		if err := u.verification.SendVerification(ctx, existingUser); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidatePassword memeriksa password baru terhadap policy dan riwayat password user
+func (u *UserService) ValidatePassword(ctx context.Context, user *account.User, newPassword string) error {
+	if violations := u.policy.Validate(newPassword, user.Email, user.PendingEmail, user.Name); len(violations) > 0 {
+		return passwordPolicyError(violations)
+	}
+
+	if u.policy.IsReused(newPassword, user.Password, user.PasswordHistory) {
+		return errs.BadRequest("password must not match a recently used password", nil)
+	}
+
+	return nil
+}
+
+func (u *UserService) Lock(ctx context.Context, id string, until time.Time) error {
+	if err := u.repo.UpdateLock(ctx, id, &until); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to lock user")
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserService) Unlock(ctx context.Context, id string) error {
+	if err := u.repo.UpdateLock(ctx, id, nil); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to unlock user")
+		return err
+	}
+
+	u.logger.Info().Str("event", "security.account_unlocked").Str("user_id", id).Msg("account unlocked")
+	return nil
+}
+
+// Disable menonaktifkan akun, seluruh session, API key dan token OAuth akun tersebut langsung ditolak
+func (u *UserService) Disable(ctx context.Context, id string) error {
+	if err := u.repo.UpdateDisabled(ctx, id, true); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to disable user")
+		return err
+	}
+
+	u.logger.Info().Str("event", "security.account_disabled").Str("user_id", id).Msg("account disabled")
+	return nil
+}
+
+func (u *UserService) Enable(ctx context.Context, id string) error {
+	if err := u.repo.UpdateDisabled(ctx, id, false); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to enable user")
+		return err
+	}
+
+	u.logger.Info().Str("event", "security.account_enabled").Str("user_id", id).Msg("account enabled")
 	return nil
 }
 
+func (u *UserService) UpdateMFA(ctx context.Context, id string, secret string, enabled bool) error {
+	if err := u.repo.UpdateMFA(ctx, id, secret, enabled); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to update mfa data")
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserService) UpdateRecoveryCodes(ctx context.Context, id string, hashes []string) error {
+	if err := u.repo.UpdateRecoveryCodes(ctx, id, hashes); err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to update recovery codes")
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserService) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	used, err := u.repo.UseTOTPStep(ctx, id, step)
+	if err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to store totp step")
+		return false, err
+	}
+
+	return used, nil
+}
+
+func (u *UserService) ConsumeRecoveryCode(ctx context.Context, id string, hash string) (bool, error) {
+	consumed, err := u.repo.ConsumeRecoveryCode(ctx, id, hash)
+	if err != nil {
+		u.logger.Error().Err(err).Str("user", id).Msg("failed to consume recovery code")
+		return false, err
+	}
+
+	return consumed, nil
+}
+
 func (u *UserService) Delete(ctx context.Context, id string) error {
 	err := u.repo.Delete(ctx, id)
 	if err != nil {
@@ -150,3 +344,7 @@ func (u *UserService) Delete(ctx context.Context, id string) error {
 	}
 	return err
 }
+
+func passwordPolicyError(violations []string) error {
+	return errs.BadRequest("password "+strings.Join(violations, "; "), nil)
+}
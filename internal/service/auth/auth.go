@@ -3,48 +3,195 @@ package auth
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/HasanNugroho/golang-starter/internal/configs"
 	"github.com/HasanNugroho/golang-starter/internal/errs"
-	"github.com/HasanNugroho/golang-starter/internal/helper"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
 	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/password"
 	"github.com/HasanNugroho/golang-starter/internal/service/account"
 	"github.com/rs/zerolog"
 )
 
 type AuthService struct {
-	userservice account.IUserService
-	logger      *zerolog.Logger
-	config      *configs.Config
+	userservice    account.IUserService
+	sessionservice ISessionService
+	tokenservice   ITokenService
+	loginguard     ILoginGuardService
+	policy         *password.Policy
+	logger         *zerolog.Logger
+	config         *configs.Config
 }
 
-func NewAuthService(userservice account.IUserService, logger *zerolog.Logger, config *configs.Config) *AuthService {
+func NewAuthService(userservice account.IUserService, sessionservice ISessionService, tokenservice ITokenService, loginguard ILoginGuardService, policy *password.Policy, logger *zerolog.Logger, config *configs.Config) *AuthService {
 	return &AuthService{
-		userservice: userservice,
-		logger:      logger,
-		config:      config,
+		userservice:    userservice,
+		sessionservice: sessionservice,
+		tokenservice:   tokenservice,
+		loginguard:     loginguard,
+		policy:         policy,
+		logger:         logger,
+		config:         config,
 	}
 }
 
-func (a *AuthService) Login(ctx context.Context, request auth.LoginRequest) (auth.AuthResponse, error) {
+func (a *AuthService) Login(ctx context.Context, request auth.LoginRequest, client auth.ClientInfo) (auth.AuthResponse, error) {
+	if err := a.loginguard.Check(ctx, request.Email, client.IPAddress); err != nil {
+		return auth.AuthResponse{}, err
+	}
+
 	user, err := a.userservice.FindByEmail(ctx, request.Email)
 	if err != nil {
-		fmt.Println(err)
+		a.loginguard.Fail(ctx, request.Email, client.IPAddress, "")
 		return auth.AuthResponse{}, errs.Unauthorized("Incorrect email or password", err)
 	}
 
+	// Service account hanya boleh memakai API key
+	if user.ServiceAccount {
+		a.loginguard.Fail(ctx, request.Email, client.IPAddress, "")
+		return auth.AuthResponse{}, errs.Unauthorized("Incorrect email or password", nil)
+	}
+
 	if !user.VerifyPassword(request.Password) {
+		a.loginguard.Fail(ctx, request.Email, client.IPAddress, user.ID.Hex())
 		return auth.AuthResponse{}, errs.Unauthorized("Incorrect email or password", errors.New("incorrect email or password"))
+	}
+
+	// Status akun baru diberitahukan setelah password benar agar tidak bocor ke pihak yang tidak tahu password
+	if user.Disabled {
+		return auth.AuthResponse{}, errs.Forbidden("account is disabled", nil)
+	}
+
+	if user.IsLocked() {
+		return auth.AuthResponse{}, errs.Forbidden("account is temporarily locked, try again later", nil)
+	}
+
+	if a.config.Security.RequireEmailVerification && !user.EmailVerified {
+		return auth.AuthResponse{}, errs.Forbidden("email address has not been verified", nil)
+	}
+
+	// Password yang melewati PASSWORD_MAX_AGE harus diganti lewat reset password sebelum bisa login lagi
+	if a.policy.IsExpired(user.PasswordLastChanged()) {
+		return auth.AuthResponse{}, errs.Forbidden("password has expired, reset your password to continue", nil)
+	}
+
+	return a.completeLogin(ctx, user, client)
+}
+
+// LoginWithUser melanjutkan login untuk user yang sudah diautentikasi pihak lain, misalnya identity provider OIDC.
+// Lock karena login password gagal tidak berlaku, akun yang dinonaktifkan dan 2FA tetap berlaku.
+func (a *AuthService) LoginWithUser(ctx context.Context, user *accountmodel.User, client auth.ClientInfo) (auth.AuthResponse, error) {
+	if user.ServiceAccount {
+		return auth.AuthResponse{}, errs.Forbidden("service accounts cannot log in", nil)
+	}
+
+	if user.Disabled {
+		return auth.AuthResponse{}, errs.Forbidden("account is disabled", nil)
+	}
+
+	return a.completeLogin(ctx, user, client)
+}
+
+// completeLogin meminta kode 2FA jika aktif, selain itu langsung membuat session
+func (a *AuthService) completeLogin(ctx context.Context, user *accountmodel.User, client auth.ClientInfo) (auth.AuthResponse, error) {
+	// Login belum selesai sampai kode 2FA diverifikasi
+	if user.TOTPEnabled {
+		mfaToken, err := a.tokenservice.GenerateMFAToken(user.ID.Hex())
+		if err != nil {
+			return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
+		}
+
+		return auth.AuthResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return a.startSession(ctx, user, client)
+}
 
+func (a *AuthService) VerifyMFA(ctx context.Context, request auth.MFAVerifyRequest, client auth.ClientInfo) (auth.AuthResponse, error) {
+	claims, err := a.tokenservice.ParseMFAToken(ctx, request.MFAToken)
+	if err != nil {
+		return auth.AuthResponse{}, errs.Unauthorized("Unauthorized", err)
+	}
+
+	user, err := a.userservice.FindById(ctx, claims.UserID())
+	if err != nil {
+		return auth.AuthResponse{}, errs.Unauthorized("User not found", err)
+	}
+
+	if user.Disabled {
+		return auth.AuthResponse{}, errs.Forbidden("account is disabled", nil)
+	}
+
+	// Verifikasi 2FA adalah bagian dari login password, sehingga lock tetap berlaku
+	if user.IsLocked() {
+		return auth.AuthResponse{}, errs.Forbidden("account is temporarily locked, try again later", nil)
+	}
+
+	// Kode 2FA yang salah dihitung sebagai login gagal agar kode 6 digit tidak bisa ditebak
+	if !user.TOTPEnabled || !a.verifySecondFactor(ctx, user, request, client) {
+		a.logger.Warn().Str("event", "security.mfa_failed").Str("user_id", user.ID.Hex()).Str("ip_address", client.IPAddress).Msg("invalid two-factor code")
+		a.loginguard.Fail(ctx, user.Email, client.IPAddress, user.ID.Hex())
+		return auth.AuthResponse{}, errs.Unauthorized("invalid verification code", nil)
+	}
+
+	// Token mfa hanya boleh dipakai sekali
+	if err := a.tokenservice.Revoke(ctx, auth.TokenTypeMFA, request.MFAToken); err != nil {
+		a.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to revoke mfa token")
+		return auth.AuthResponse{}, errs.Internal("failed to revoke mfa token", err)
 	}
 
-	accessToken, err := helper.GenerateToken(user.ID.Hex())
+	return a.startSession(ctx, user, client)
+}
+
+// verifySecondFactor memeriksa kode TOTP, atau memakai satu kode pemulihan jika diberikan
+func (a *AuthService) verifySecondFactor(ctx context.Context, user *accountmodel.User, request auth.MFAVerifyRequest, client auth.ClientInfo) bool {
+	if request.RecoveryCode == "" {
+		return verifyTOTP(ctx, a.userservice, user, request.Code)
+	}
+
+	hash, ok := user.MatchRecoveryCode(request.RecoveryCode)
+	if !ok {
+		return false
+	}
+
+	consumed, err := a.userservice.ConsumeRecoveryCode(ctx, user.ID.Hex(), hash)
+	if err != nil || !consumed {
+		return false
+	}
+
+	a.logger.Warn().
+		Str("event", "security.mfa_recovery_code_used").
+		Str("user_id", user.ID.Hex()).
+		Str("ip_address", client.IPAddress).
+		Int("remaining", len(user.RecoveryCodes)-1).
+		Msg("login with recovery code")
+	return true
+}
+
+// startSession membuat session baru beserta pasangan access dan refresh token.
+// Catatan login gagal baru direset di sini, setelah seluruh faktor login terverifikasi.
+func (a *AuthService) startSession(ctx context.Context, user *accountmodel.User, client auth.ClientInfo) (auth.AuthResponse, error) {
+	a.loginguard.Succeed(ctx, user.Email)
+
+	session, err := a.sessionservice.Create(ctx, user.ID.Hex(), client)
+	if err != nil {
+		return auth.AuthResponse{}, err
+	}
+
+	accessToken, err := a.tokenservice.GenerateAccessToken(user.ID.Hex(), session.ID)
+	if err != nil {
+		return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
+	}
+
+	familyID, err := a.tokenservice.NewTokenFamily()
 	if err != nil {
 		return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
 	}
 
-	refreshToken, err := helper.GenerateRefreshToken(user.ID.Hex())
+	refreshToken, err := a.tokenservice.GenerateRefreshToken(ctx, user.ID.Hex(), session.ID, familyID)
 	if err != nil {
 		return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
 	}
@@ -59,17 +206,24 @@ func (a *AuthService) Login(ctx context.Context, request auth.LoginRequest) (aut
 	}, nil
 }
 
-func (a *AuthService) RefreshToken(ctx context.Context, request auth.RenewalTokenRequest) (auth.AuthResponse, error) {
-	claims, err := helper.ParseToken(request.RefreshToken)
+func (a *AuthService) RefreshToken(ctx context.Context, request auth.RenewalTokenRequest, client auth.ClientInfo) (auth.AuthResponse, error) {
+	claims, err := a.tokenservice.ParseRefreshToken(ctx, request.RefreshToken)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		a.revokeTokenFamily(ctx, claims, client)
+		return auth.AuthResponse{}, errs.Unauthorized("Unauthorized", err)
+	}
 	if err != nil {
 		a.logger.Error().Err(err).Msg("invalid or expired refresh token")
 		return auth.AuthResponse{}, errs.Unauthorized("Unauthorized", err)
 	}
 
-	userID, ok := claims["user_id"].(string)
-	if !ok {
-		a.logger.Error().Msg("invalid token payload")
-		return auth.AuthResponse{}, errs.Unauthorized("Unauthorized", nil)
+	userID := claims.UserID()
+	sessionID := claims.SessionID
+
+	// Cek session belum dicabut
+	session, err := a.sessionservice.Validate(ctx, userID, sessionID, client)
+	if err != nil {
+		return auth.AuthResponse{}, err
 	}
 
 	// Cek user masih ada
@@ -78,15 +232,29 @@ func (a *AuthService) RefreshToken(ctx context.Context, request auth.RenewalToke
 		return auth.AuthResponse{}, errs.Unauthorized("User not found", err)
 	}
 
-	// Blacklist refresh token lama
-	_ = helper.RevokeRequestToken(request.RefreshToken)
+	if user.Disabled {
+		return auth.AuthResponse{}, errs.Forbidden("account is disabled", nil)
+	}
 
-	accessToken, err := helper.GenerateToken(userID)
+	// Rotasi dilakukan sebelum token baru diberikan, request bersamaan dengan refresh token yang sama
+	// hanya satu yang lolos dan sisanya dianggap reuse
+	refreshToken, err := a.tokenservice.RotateRefreshToken(ctx, claims)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		a.revokeTokenFamily(ctx, claims, client)
+		return auth.AuthResponse{}, errs.Unauthorized("Unauthorized", err)
+	}
 	if err != nil {
 		return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
 	}
 
-	refreshToken, err := helper.GenerateRefreshToken(userID)
+	// Blacklist refresh token lama
+	_ = a.tokenservice.Revoke(ctx, auth.TokenTypeRefresh, request.RefreshToken)
+
+	if err := a.sessionservice.Extend(ctx, session, client); err != nil {
+		return auth.AuthResponse{}, err
+	}
+
+	accessToken, err := a.tokenservice.GenerateAccessToken(userID, session.ID)
 	if err != nil {
 		return auth.AuthResponse{}, errs.Unauthorized("failed to generate token", err)
 	}
@@ -100,3 +268,85 @@ func (a *AuthService) RefreshToken(ctx context.Context, request auth.RenewalToke
 		},
 	}, nil
 }
+
+func (a *AuthService) Logout(ctx context.Context, request auth.LogoutRequest) error {
+	userID, sessionID, err := a.revokeTokens(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	if err := a.sessionservice.Revoke(ctx, userID, sessionID); err != nil {
+		a.logger.Error().Err(err).Str("user_id", userID).Str("session_id", sessionID).Msg("failed to revoke session on logout")
+		return err
+	}
+
+	return nil
+}
+
+func (a *AuthService) LogoutAll(ctx context.Context, request auth.LogoutRequest) error {
+	userID, _, err := a.revokeTokens(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	return a.sessionservice.RevokeAll(ctx, userID)
+}
+
+// revokeTokens memasukkan access token dan refresh token milik caller ke blacklist
+func (a *AuthService) revokeTokens(ctx context.Context, request auth.LogoutRequest) (string, string, error) {
+	claims, err := a.tokenservice.ParseAccessToken(ctx, request.AccessToken)
+	if err != nil {
+		return "", "", errs.Unauthorized("Unauthorized", err)
+	}
+
+	userID := claims.UserID()
+	sessionID := claims.SessionID
+
+	if request.RefreshToken != "" {
+		refreshClaims, err := a.tokenservice.ParseRefreshTokenUnverified(request.RefreshToken)
+		if err != nil {
+			return "", "", errs.BadRequest("invalid refresh token", err)
+		}
+
+		if refreshClaims.UserID() != userID || refreshClaims.SessionID != sessionID {
+			return "", "", errs.BadRequest("refresh token does not belong to the current session", nil)
+		}
+
+		if err := a.tokenservice.Revoke(ctx, auth.TokenTypeRefresh, request.RefreshToken); err != nil {
+			a.logger.Error().Err(err).Str("user_id", userID).Msg("failed to revoke refresh token")
+			return "", "", errs.Internal("failed to revoke refresh token", err)
+		}
+
+		if err := a.tokenservice.RevokeTokenFamily(ctx, refreshClaims.FamilyID); err != nil {
+			a.logger.Error().Err(err).Str("user_id", userID).Msg("failed to revoke refresh token family")
+		}
+	}
+
+	if err := a.tokenservice.Revoke(ctx, auth.TokenTypeAccess, request.AccessToken); err != nil {
+		a.logger.Error().Err(err).Str("user_id", userID).Msg("failed to revoke access token")
+		return "", "", errs.Internal("failed to revoke access token", err)
+	}
+
+	return userID, sessionID, nil
+}
+
+// revokeTokenFamily dipanggil ketika refresh token yang sudah dirotasi dipakai ulang.
+// Seluruh family dan session terkait dicabut karena token kemungkinan besar telah dicuri.
+func (a *AuthService) revokeTokenFamily(ctx context.Context, claims *auth.RefreshClaims, client auth.ClientInfo) {
+	a.logger.Warn().
+		Str("event", "security.refresh_token_reuse").
+		Str("user_id", claims.UserID()).
+		Str("session_id", claims.SessionID).
+		Str("family_id", claims.FamilyID).
+		Str("ip_address", client.IPAddress).
+		Str("device", client.Device).
+		Msg("refresh token reuse detected, revoking token family")
+
+	if err := a.tokenservice.RevokeTokenFamily(ctx, claims.FamilyID); err != nil {
+		a.logger.Error().Err(err).Str("family_id", claims.FamilyID).Msg("failed to revoke refresh token family")
+	}
+
+	if err := a.sessionservice.Revoke(ctx, claims.UserID(), claims.SessionID); err != nil {
+		a.logger.Error().Err(err).Str("session_id", claims.SessionID).Msg("failed to revoke session of reused refresh token")
+	}
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/password"
+	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeUserService hanya mengimplementasikan method yang dipakai test, method lain panic karena interface yang di-embed bernilai nil
+type fakeUserService struct {
+	account.IUserService
+	users map[string]*accountmodel.User
+}
+
+func newFakeUserService(users ...*accountmodel.User) *fakeUserService {
+	f := &fakeUserService{users: make(map[string]*accountmodel.User)}
+	for _, user := range users {
+		f.users[user.ID.Hex()] = user
+	}
+	return f
+}
+
+// FindById mengembalikan salinan user seperti data yang dibaca dari database,
+// sehingga perubahan berikutnya tidak ikut terlihat pada salinan yang sudah dibaca
+func (f *fakeUserService) FindById(ctx context.Context, id string) (*accountmodel.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return &accountmodel.User{}, errs.NotFound("user not found", nil)
+	}
+	found := *user
+	return &found, nil
+}
+
+func (f *fakeUserService) FindByEmail(ctx context.Context, email string) (*accountmodel.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return &accountmodel.User{}, errs.NotFound("user not found", nil)
+}
+
+func (f *fakeUserService) UseTOTPStep(ctx context.Context, id string, step int64) (bool, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return false, nil
+	}
+	if user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+// ConsumeRecoveryCode menghapus hash dengan slice baru, salinan user yang sudah dibaca tetap memegang daftar lama
+func (f *fakeUserService) ConsumeRecoveryCode(ctx context.Context, id string, hash string) (bool, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return false, nil
+	}
+
+	remaining := []string{}
+	for _, code := range user.RecoveryCodes {
+		if code != hash {
+			remaining = append(remaining, code)
+		}
+	}
+	if len(remaining) == len(user.RecoveryCodes) {
+		return false, nil
+	}
+	user.RecoveryCodes = remaining
+	return true, nil
+}
+
+func (f *fakeUserService) UpdateRecoveryCodes(ctx context.Context, id string, hashes []string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return errs.NotFound("user not found", nil)
+	}
+	user.RecoveryCodes = hashes
+	return nil
+}
+
+type fakeLoginGuard struct {
+	failures int
+}
+
+func (f *fakeLoginGuard) Check(ctx context.Context, email string, ip string) error {
+	return nil
+}
+
+func (f *fakeLoginGuard) Fail(ctx context.Context, email string, ip string, userID string) {
+	f.failures++
+}
+
+func (f *fakeLoginGuard) Succeed(ctx context.Context, email string) {}
+
+func newTestPolicy(t *testing.T) *password.Policy {
+	t.Helper()
+
+	policy, err := password.NewPolicy(configs.PasswordPolicyConfig{MinLength: 8})
+	if err != nil {
+		t.Fatalf("NewPolicy() error = %v", err)
+	}
+	return policy
+}
+
+func hashPassword(t *testing.T, plain string) string {
+	t.Helper()
+
+	hashed, err := helper.HashPassword([]byte(plain))
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	return hashed
+}
+
+func statusCode(err error) int {
+	var customErr *errs.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.StatusCode()
+	}
+	return 0
+}
+
+func TestAuthServiceLoginRejectsServiceAccounts(t *testing.T) {
+	serviceAccount := &accountmodel.User{
+		ID:             bson.NewObjectID(),
+		Email:          "ci@service-account.invalid",
+		Password:       hashPassword(t, "s3cret-password"),
+		EmailVerified:  true,
+		ServiceAccount: true,
+	}
+
+	tests := []struct {
+		name         string
+		login        func(service *AuthService) (auth.AuthResponse, error)
+		wantStatus   int
+		wantFailures int
+	}{
+		{
+			// Ditolak dengan pesan yang sama seperti password salah dan dihitung sebagai login gagal
+			name: "password login",
+			login: func(service *AuthService) (auth.AuthResponse, error) {
+				return service.Login(context.Background(), auth.LoginRequest{Email: serviceAccount.Email, Password: "s3cret-password"}, auth.ClientInfo{})
+			},
+			wantStatus:   http.StatusUnauthorized,
+			wantFailures: 1,
+		},
+		{
+			name: "login through an identity provider",
+			login: func(service *AuthService) (auth.AuthResponse, error) {
+				return service.LoginWithUser(context.Background(), serviceAccount, auth.ClientInfo{})
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := &fakeLoginGuard{}
+			logger := zerolog.Nop()
+			service := NewAuthService(newFakeUserService(serviceAccount), nil, nil, guard, newTestPolicy(t), &logger, &configs.Config{})
+
+			response, err := tt.login(service)
+			if got := statusCode(err); got != tt.wantStatus {
+				t.Fatalf("login error = %v, want status %d", err, tt.wantStatus)
+			}
+			if response.Token != "" || response.MFAToken != "" {
+				t.Errorf("login returned tokens for a service account")
+			}
+			if guard.failures != tt.wantFailures {
+				t.Errorf("login failures = %d, want %d", guard.failures, tt.wantFailures)
+			}
+		})
+	}
+}
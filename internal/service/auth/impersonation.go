@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+// ImpersonationService menerbitkan token agar staff dengan manage:system bisa melihat aplikasi
+// persis seperti user tertentu tanpa meminta password user tersebut
+type ImpersonationService struct {
+	userservice  account.IUserService
+	permissions  account.IPermissionService
+	tokenservice ITokenService
+	logger       *zerolog.Logger
+}
+
+func NewImpersonationService(userservice account.IUserService, permissions account.IPermissionService, tokenservice ITokenService, logger *zerolog.Logger) *ImpersonationService {
+	return &ImpersonationService{
+		userservice:  userservice,
+		permissions:  permissions,
+		tokenservice: tokenservice,
+		logger:       logger,
+	}
+}
+
+// Start menerbitkan token impersonation. Administrator lain dan service account tidak bisa ditiru.
+func (i *ImpersonationService) Start(ctx context.Context, actor *accountmodel.User, sessionID string, request auth.ImpersonationRequest, client auth.ClientInfo) (auth.ImpersonationResponse, error) {
+	if request.UserID == actor.ID.Hex() {
+		return auth.ImpersonationResponse{}, errs.BadRequest("you cannot impersonate yourself", nil)
+	}
+
+	user, err := i.userservice.FindById(ctx, request.UserID)
+	if err != nil {
+		return auth.ImpersonationResponse{}, err
+	}
+
+	if user.ServiceAccount {
+		return auth.ImpersonationResponse{}, errs.Forbidden("service accounts cannot be impersonated", nil)
+	}
+
+	permissions, err := i.permissions.Resolve(ctx, user)
+	if err != nil {
+		return auth.ImpersonationResponse{}, err
+	}
+	if permissions.Has(permission.ManageSystem) {
+		i.logger.Warn().
+			Str("event", "audit.impersonation_denied").
+			Str("actor_id", actor.ID.Hex()).
+			Str("user_id", user.ID.Hex()).
+			Str("ip_address", client.IPAddress).
+			Msg("attempt to impersonate an administrator")
+		return auth.ImpersonationResponse{}, errs.Forbidden("administrators cannot be impersonated", nil)
+	}
+
+	token, expiry, err := i.tokenservice.GenerateImpersonationToken(user.ID.Hex(), actor.ID.Hex(), sessionID)
+	if err != nil {
+		return auth.ImpersonationResponse{}, errs.Internal("failed to generate token", err)
+	}
+
+	i.logger.Info().
+		Str("event", "audit.impersonation_started").
+		Str("actor_id", actor.ID.Hex()).
+		Str("user_id", user.ID.Hex()).
+		Str("session_id", sessionID).
+		Str("reason", request.Reason).
+		Str("ip_address", client.IPAddress).
+		Str("device", client.Device).
+		Msg("impersonation started")
+
+	return auth.ImpersonationResponse{
+		Token:     token,
+		ExpiresIn: int(expiry.Seconds()),
+		User:      user.ToUserResponse(),
+	}, nil
+}
+
+// Stop mencabut token impersonation yang sedang dipakai
+func (i *ImpersonationService) Stop(ctx context.Context, actor *accountmodel.User, user *accountmodel.User, token string, client auth.ClientInfo) error {
+	if err := i.tokenservice.Revoke(ctx, auth.TokenTypeAccess, token); err != nil {
+		i.logger.Error().Err(err).Str("actor_id", actor.ID.Hex()).Str("user_id", user.ID.Hex()).Msg("failed to revoke impersonation token")
+		return errs.Internal("failed to stop impersonation", err)
+	}
+
+	i.logger.Info().
+		Str("event", "audit.impersonation_stopped").
+		Str("actor_id", actor.ID.Hex()).
+		Str("user_id", user.ID.Hex()).
+		Str("ip_address", client.IPAddress).
+		Msg("impersonation stopped")
+	return nil
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakePermissionService meresolve grant per user lewat katalog tanpa role dan cache
+type fakePermissionService struct {
+	catalog *permission.Catalog
+	grants  map[bson.ObjectID][]string
+}
+
+func (f *fakePermissionService) Resolve(ctx context.Context, user *accountmodel.User) (permission.Set, error) {
+	grants, ok := f.grants[user.ID]
+	if !ok {
+		return nil, errs.NotFound("user not found", nil)
+	}
+	return f.catalog.Expand(grants), nil
+}
+
+func (f *fakePermissionService) InvalidateUser(ctx context.Context, userID string) {}
+
+func (f *fakePermissionService) InvalidateAll(ctx context.Context) {}
+
+func newTestCatalog(t *testing.T) *permission.Catalog {
+	t.Helper()
+
+	catalog, err := permission.NewCatalog([]permission.Group{
+		{
+			Name: "users",
+			Permissions: []permission.Permission{
+				{Name: "users:read"},
+				{Name: "users:update", Implies: []string{"users:read"}},
+			},
+		},
+		{
+			Name: "system",
+			Permissions: []permission.Permission{
+				{Name: permission.ManageSystem, Implies: []string{permission.Wildcard}},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	return catalog
+}
+
+func TestImpersonationServiceStart(t *testing.T) {
+	actor := &accountmodel.User{ID: bson.NewObjectID(), Email: "admin@example.com"}
+	member := &accountmodel.User{ID: bson.NewObjectID(), Email: "jane@example.com"}
+	admin := &accountmodel.User{ID: bson.NewObjectID(), Email: "root@example.com"}
+	superuser := &accountmodel.User{ID: bson.NewObjectID(), Email: "super@example.com"}
+	serviceAccount := &accountmodel.User{ID: bson.NewObjectID(), Email: "ci@service-account.invalid", ServiceAccount: true}
+
+	grants := map[bson.ObjectID][]string{
+		actor.ID:          {permission.ManageSystem},
+		member.ID:         {"users:update"},
+		admin.ID:          {permission.ManageSystem},
+		superuser.ID:      {permission.Wildcard},
+		serviceAccount.ID: {"users:read"},
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{name: "regular user", userID: member.ID.Hex()},
+		{name: "yourself", userID: actor.ID.Hex(), wantStatus: http.StatusBadRequest},
+		{name: "administrator with the same privileges", userID: admin.ID.Hex(), wantStatus: http.StatusForbidden},
+		{name: "user holding every permission", userID: superuser.ID.Hex(), wantStatus: http.StatusForbidden},
+		{name: "service account", userID: serviceAccount.ID.Hex(), wantStatus: http.StatusForbidden},
+		{name: "unknown user", userID: bson.NewObjectID().Hex(), wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := newTestTokenService(t)
+			permissions := &fakePermissionService{catalog: newTestCatalog(t), grants: grants}
+			logger := zerolog.Nop()
+			service := NewImpersonationService(newFakeUserService(actor, member, admin, superuser, serviceAccount), permissions, tokens, &logger)
+
+			request := auth.ImpersonationRequest{UserID: tt.userID, Reason: "support ticket"}
+			response, err := service.Start(context.Background(), actor, "actor-session", request, auth.ClientInfo{})
+			if got := statusCode(err); got != tt.wantStatus {
+				t.Fatalf("Start() error = %v, want status %d", err, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				if response.Token != "" {
+					t.Errorf("Start() returned a token for a rejected target")
+				}
+				return
+			}
+
+			claims, err := tokens.ParseAccessToken(context.Background(), response.Token)
+			if err != nil {
+				t.Fatalf("ParseAccessToken() error = %v", err)
+			}
+			if !claims.IsImpersonation() || claims.Actor.Subject != actor.ID.Hex() {
+				t.Errorf("token act = %+v, want sub %s", claims.Actor, actor.ID.Hex())
+			}
+			if claims.UserID() != tt.userID {
+				t.Errorf("token sub = %s, want %s", claims.UserID(), tt.userID)
+			}
+			// Token terikat ke session staff, bukan session user yang ditiru
+			if claims.SessionID != "actor-session" {
+				t.Errorf("token sid = %s, want actor-session", claims.SessionID)
+			}
+			if response.ExpiresIn != 15*60 {
+				t.Errorf("Start() expires in = %d, want %d", response.ExpiresIn, 15*60)
+			}
+		})
+	}
+}
+
+func TestTokenServiceAccessTokenWithoutActor(t *testing.T) {
+	tokens := newTestTokenService(t)
+	tokens.expiry = tokens.impersonationExpiry
+
+	token, err := tokens.GenerateAccessToken("user", "session")
+	if err != nil {
+		t.Fatalf("GenerateAccessToken() error = %v", err)
+	}
+	claims, err := tokens.ParseAccessToken(context.Background(), token)
+	if err != nil {
+		t.Fatalf("ParseAccessToken() error = %v", err)
+	}
+	if claims.IsImpersonation() || claims.Actor != nil {
+		t.Errorf("regular access token act = %+v, want none", claims.Actor)
+	}
+}
+
+func TestImpersonationServiceStop(t *testing.T) {
+	actor := &accountmodel.User{ID: bson.NewObjectID()}
+	member := &accountmodel.User{ID: bson.NewObjectID()}
+
+	tokens := newTestTokenService(t)
+	logger := zerolog.Nop()
+	service := NewImpersonationService(newFakeUserService(actor, member), nil, tokens, &logger)
+
+	token, _, err := tokens.GenerateImpersonationToken(member.ID.Hex(), actor.ID.Hex(), "actor-session")
+	if err != nil {
+		t.Fatalf("GenerateImpersonationToken() error = %v", err)
+	}
+
+	if err := service.Stop(context.Background(), actor, member, token, auth.ClientInfo{}); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, err := tokens.ParseAccessToken(context.Background(), token); err == nil {
+		t.Errorf("ParseAccessToken() accepted a stopped impersonation token")
+	}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+// LoginGuardService melindungi login dari brute-force: menghitung percobaan gagal per email dan per IP,
+// menambahkan jeda progresif dan mengunci akun sementara setelah melewati batas
+type LoginGuardService struct {
+	repo        repository.ILoginAttemptRepository
+	userservice accountservice.IUserService
+	logger      *zerolog.Logger
+	config      *configs.Config
+}
+
+func NewLoginGuardService(repo repository.ILoginAttemptRepository, userservice accountservice.IUserService, logger *zerolog.Logger, config *configs.Config) *LoginGuardService {
+	return &LoginGuardService{
+		repo:        repo,
+		userservice: userservice,
+		logger:      logger,
+		config:      config,
+	}
+}
+
+// Check menolak IP yang sudah melewati batas dan menahan request sesuai jumlah kegagalan sebelumnya
+func (l *LoginGuardService) Check(ctx context.Context, email string, ip string) error {
+	window := l.window()
+
+	if ip != "" {
+		ipFailures, err := l.repo.CountFailures(ctx, ipKey(ip), window)
+		if err != nil {
+			l.logger.Error().Err(err).Str("ip_address", ip).Msg("failed to count login attempts")
+		} else if ipFailures >= int64(l.config.Security.LoginMaxAttemptsPerIP) {
+			l.logger.Warn().Str("event", "security.login_ip_blocked").Str("ip_address", ip).Int64("failures", ipFailures).Msg("too many failed logins from ip")
+			return errs.TooManyRequests("too many login attempts, try again later", nil)
+		}
+	}
+
+	failures, err := l.repo.CountFailures(ctx, emailKey(email), window)
+	if err != nil {
+		l.logger.Error().Err(err).Str("email", email).Msg("failed to count login attempts")
+		return nil
+	}
+
+	return l.delay(ctx, failures)
+}
+
+// Fail mencatat login gagal. Jika userID diisi dan batas percobaan email terlampaui, akun dikunci sementara.
+func (l *LoginGuardService) Fail(ctx context.Context, email string, ip string, userID string) {
+	window := l.window()
+
+	if ip != "" {
+		if _, err := l.repo.RecordFailure(ctx, ipKey(ip), window); err != nil {
+			l.logger.Error().Err(err).Str("ip_address", ip).Msg("failed to record login attempt")
+		}
+	}
+
+	failures, err := l.repo.RecordFailure(ctx, emailKey(email), window)
+	if err != nil {
+		l.logger.Error().Err(err).Str("email", email).Msg("failed to record login attempt")
+		return
+	}
+
+	l.logger.Warn().Str("event", "security.login_failed").Str("email", email).Str("ip_address", ip).Int64("failures", failures).Msg("failed login attempt")
+
+	if userID == "" || failures < int64(l.config.Security.LoginMaxAttempts) {
+		return
+	}
+
+	lockedUntil := time.Now().Add(time.Duration(l.config.Security.LoginLockoutDuration) * time.Minute)
+	if err := l.userservice.Lock(ctx, userID, lockedUntil); err != nil {
+		return
+	}
+
+	// Counter direset agar setelah lock berakhir user mendapat jatah percobaan baru
+	_ = l.repo.Reset(ctx, emailKey(email))
+
+	l.logger.Warn().
+		Str("event", "security.account_locked").
+		Str("user_id", userID).
+		Str("ip_address", ip).
+		Time("locked_until", lockedUntil).
+		Msg("account locked after too many failed logins")
+}
+
+// Succeed menghapus catatan kegagalan email setelah login berhasil
+func (l *LoginGuardService) Succeed(ctx context.Context, email string) {
+	if err := l.repo.Reset(ctx, emailKey(email)); err != nil {
+		l.logger.Error().Err(err).Str("email", email).Msg("failed to reset login attempts")
+	}
+}
+
+// delay menunggu LOGIN_DELAY_BASE * 2^(failures-1), dibatasi LOGIN_DELAY_MAX
+func (l *LoginGuardService) delay(ctx context.Context, failures int64) error {
+	if failures <= 0 || l.config.Security.LoginDelayBase <= 0 {
+		return nil
+	}
+
+	base := time.Duration(l.config.Security.LoginDelayBase) * time.Millisecond
+	max := time.Duration(l.config.Security.LoginDelayMax) * time.Millisecond
+	wait := time.Duration(float64(base) * math.Pow(2, float64(failures-1)))
+	if wait > max || wait <= 0 {
+		wait = max
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *LoginGuardService) window() time.Duration {
+	return time.Duration(l.config.Security.LoginAttemptWindow) * time.Minute
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
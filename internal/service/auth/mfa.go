@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+// recoveryCodeCount adalah jumlah kode pemulihan yang dibuat setiap kali generate
+const recoveryCodeCount = 10
+
+type MFAService struct {
+	userservice accountservice.IUserService
+	loginguard  ILoginGuardService
+	logger      *zerolog.Logger
+	config      *configs.Config
+}
+
+func NewMFAService(userservice accountservice.IUserService, loginguard ILoginGuardService, logger *zerolog.Logger, config *configs.Config) *MFAService {
+	return &MFAService{
+		userservice: userservice,
+		loginguard:  loginguard,
+		logger:      logger,
+		config:      config,
+	}
+}
+
+// Enroll membuat secret TOTP baru yang belum aktif sampai diverifikasi lewat Activate
+func (m *MFAService) Enroll(ctx context.Context, user *account.User) (auth.MFAEnrollResponse, error) {
+	if user.TOTPEnabled {
+		return auth.MFAEnrollResponse{}, errs.BadRequest("two-factor authentication already enabled", nil)
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to generate totp secret")
+		return auth.MFAEnrollResponse{}, errs.Internal("failed to generate secret", err)
+	}
+
+	if err := m.userservice.UpdateMFA(ctx, user.ID.Hex(), secret, false); err != nil {
+		return auth.MFAEnrollResponse{}, err
+	}
+
+	return auth.MFAEnrollResponse{
+		Secret: secret,
+		URI:    helper.TOTPURI(m.config.AppName, user.Email, secret),
+	}, nil
+}
+
+// Activate mengaktifkan 2FA dan mengembalikan kode pemulihan yang hanya ditampilkan sekali
+func (m *MFAService) Activate(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) (auth.MFARecoveryCodesResponse, error) {
+	if user.TOTPEnabled {
+		return auth.MFARecoveryCodesResponse{}, errs.BadRequest("two-factor authentication already enabled", nil)
+	}
+
+	if user.TOTPSecret == "" {
+		return auth.MFARecoveryCodesResponse{}, errs.BadRequest("two-factor authentication not enrolled", nil)
+	}
+
+	if err := m.verifyCode(ctx, user, request.Code, client); err != nil {
+		return auth.MFARecoveryCodesResponse{}, err
+	}
+
+	codes, err := m.resetRecoveryCodes(ctx, user)
+	if err != nil {
+		return auth.MFARecoveryCodesResponse{}, err
+	}
+
+	if err := m.userservice.UpdateMFA(ctx, user.ID.Hex(), user.TOTPSecret, true); err != nil {
+		return auth.MFARecoveryCodesResponse{}, err
+	}
+
+	m.logger.Info().Str("event", "security.mfa_enabled").Str("user_id", user.ID.Hex()).Msg("two-factor authentication enabled")
+	return auth.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes membuat kode pemulihan baru dan membatalkan seluruh kode lama
+func (m *MFAService) RegenerateRecoveryCodes(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) (auth.MFARecoveryCodesResponse, error) {
+	if !user.TOTPEnabled {
+		return auth.MFARecoveryCodesResponse{}, errs.BadRequest("two-factor authentication not enabled", nil)
+	}
+
+	if err := m.verifyCode(ctx, user, request.Code, client); err != nil {
+		return auth.MFARecoveryCodesResponse{}, err
+	}
+
+	codes, err := m.resetRecoveryCodes(ctx, user)
+	if err != nil {
+		return auth.MFARecoveryCodesResponse{}, err
+	}
+
+	m.logger.Info().Str("event", "security.mfa_recovery_codes_regenerated").Str("user_id", user.ID.Hex()).Msg("recovery codes regenerated")
+	return auth.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (m *MFAService) Disable(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) error {
+	if !user.TOTPEnabled {
+		return errs.BadRequest("two-factor authentication not enabled", nil)
+	}
+
+	if err := m.verifyCode(ctx, user, request.Code, client); err != nil {
+		return err
+	}
+
+	if err := m.userservice.UpdateMFA(ctx, user.ID.Hex(), "", false); err != nil {
+		return err
+	}
+
+	if err := m.userservice.UpdateRecoveryCodes(ctx, user.ID.Hex(), []string{}); err != nil {
+		return err
+	}
+
+	m.logger.Info().Str("event", "security.mfa_disabled").Str("user_id", user.ID.Hex()).Msg("two-factor authentication disabled")
+	return nil
+}
+
+// verifyCode memeriksa kode TOTP untuk aksi pengelolaan 2FA. Kode yang salah dihitung sebagai login gagal
+// agar kode 6 digit tidak bisa ditebak lewat endpoint ini.
+func (m *MFAService) verifyCode(ctx context.Context, user *account.User, code string, client auth.ClientInfo) error {
+	if err := m.loginguard.Check(ctx, user.Email, client.IPAddress); err != nil {
+		return err
+	}
+
+	if !verifyTOTP(ctx, m.userservice, user, code) {
+		m.logger.Warn().Str("event", "security.mfa_failed").Str("user_id", user.ID.Hex()).Str("ip_address", client.IPAddress).Msg("invalid two-factor code")
+		m.loginguard.Fail(ctx, user.Email, client.IPAddress, user.ID.Hex())
+		return errs.BadRequest("invalid verification code", nil)
+	}
+
+	return nil
+}
+
+// verifyTOTP memeriksa kode TOTP lalu menandai time step-nya sudah dipakai secara atomik,
+// sehingga kode yang sama tidak bisa dipakai ulang selama masih berada di jendela toleransi waktu
+func verifyTOTP(ctx context.Context, userservice accountservice.IUserService, user *account.User, code string) bool {
+	step, ok := helper.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return false
+	}
+
+	used, err := userservice.UseTOTPStep(ctx, user.ID.Hex(), step)
+	return err == nil && used
+}
+
+// resetRecoveryCodes membuat kode pemulihan baru, hanya hash bcrypt-nya yang disimpan
+func (m *MFAService) resetRecoveryCodes(ctx context.Context, user *account.User) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		raw, err := helper.GenerateRandomString(5)
+		if err != nil {
+			m.logger.Error().Err(err).Msg("failed to generate recovery code")
+			return nil, errs.Internal("failed to generate recovery codes", err)
+		}
+
+		hash, err := helper.HashPassword([]byte(raw))
+		if err != nil {
+			m.logger.Error().Err(err).Msg("failed to hash recovery code")
+			return nil, errs.Internal("failed to generate recovery codes", err)
+		}
+
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hash
+	}
+
+	if err := m.userservice.UpdateRecoveryCodes(ctx, user.ID.Hex(), hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+type fakeSessionService struct {
+	ISessionService
+	created int
+}
+
+func (f *fakeSessionService) Create(ctx context.Context, userID string, client auth.ClientInfo) (*auth.Session, error) {
+	f.created++
+	return &auth.Session{ID: fmt.Sprintf("session-%d", f.created), UserID: userID}, nil
+}
+
+// totpCode menghitung kode TOTP untuk waktu tertentu seperti aplikasi authenticator
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func newTestMFAUser(t *testing.T) *accountmodel.User {
+	t.Helper()
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	return &accountmodel.User{
+		ID:            bson.NewObjectID(),
+		Email:         "jane@example.com",
+		EmailVerified: true,
+		TOTPEnabled:   true,
+		TOTPSecret:    secret,
+	}
+}
+
+func newTestAuthService(t *testing.T, users *fakeUserService) (*AuthService, *fakeLoginGuard) {
+	t.Helper()
+
+	guard := &fakeLoginGuard{}
+	logger := zerolog.Nop()
+	service := NewAuthService(users, &fakeSessionService{}, newTestTokenService(t), guard, newTestPolicy(t), &logger, &configs.Config{})
+	return service, guard
+}
+
+// verifyMFA memulai login 2FA baru lalu memverifikasinya dengan request yang diberikan
+func verifyMFA(t *testing.T, service *AuthService, user *accountmodel.User, request auth.MFAVerifyRequest) (auth.AuthResponse, error) {
+	t.Helper()
+
+	mfaToken, err := service.tokenservice.GenerateMFAToken(user.ID.Hex())
+	if err != nil {
+		t.Fatalf("GenerateMFAToken() error = %v", err)
+	}
+	request.MFAToken = mfaToken
+
+	return service.VerifyMFA(context.Background(), request, auth.ClientInfo{IPAddress: "203.0.113.10"})
+}
+
+func TestVerifyTOTPReplay(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare mengembalikan kode yang dikirim dan salinan user yang dibaca request tersebut
+		prepare func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User)
+		want    bool
+	}{
+		{
+			name: "fresh code",
+			prepare: func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User) {
+				return totpCode(t, user.TOTPSecret, time.Now()), user
+			},
+			want: true,
+		},
+		{
+			name: "wrong code",
+			prepare: func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User) {
+				code := totpCode(t, user.TOTPSecret, time.Now())
+				return code[:5] + string('0'+(code[5]-'0'+1)%10), user
+			},
+			want: false,
+		},
+		{
+			// Request kedua membaca user setelah request pertama selesai
+			name: "code replayed after it was used",
+			prepare: func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User) {
+				code := totpCode(t, user.TOTPSecret, time.Now())
+				if !verifyTOTP(context.Background(), users, user, code) {
+					t.Fatalf("verifyTOTP() first use = false, want true")
+				}
+				replayed, _ := users.FindById(context.Background(), user.ID.Hex())
+				return code, replayed
+			},
+			want: false,
+		},
+		{
+			// Dua request membaca user bersamaan, hanya penanda step atomik yang mencegah kode dipakai dua kali
+			name: "code replayed concurrently",
+			prepare: func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User) {
+				stale, _ := users.FindById(context.Background(), user.ID.Hex())
+				code := totpCode(t, user.TOTPSecret, time.Now())
+				if !verifyTOTP(context.Background(), users, user, code) {
+					t.Fatalf("verifyTOTP() first use = false, want true")
+				}
+				return code, stale
+			},
+			want: false,
+		},
+		{
+			name: "older code after a newer one was used",
+			prepare: func(t *testing.T, users *fakeUserService, user *accountmodel.User) (string, *accountmodel.User) {
+				stale, _ := users.FindById(context.Background(), user.ID.Hex())
+				if !verifyTOTP(context.Background(), users, user, totpCode(t, user.TOTPSecret, time.Now().Add(30*time.Second))) {
+					t.Fatalf("verifyTOTP() newer code = false, want true")
+				}
+				return totpCode(t, user.TOTPSecret, time.Now()), stale
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestMFAUser(t)
+			users := newFakeUserService(user)
+
+			code, reader := tt.prepare(t, users, user)
+			if got := verifyTOTP(context.Background(), users, reader, code); got != tt.want {
+				t.Errorf("verifyTOTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthServiceVerifyMFAWithTOTP(t *testing.T) {
+	user := newTestMFAUser(t)
+	service, guard := newTestAuthService(t, newFakeUserService(user))
+
+	code := totpCode(t, user.TOTPSecret, time.Now())
+
+	response, err := verifyMFA(t, service, user, auth.MFAVerifyRequest{Code: code})
+	if err != nil {
+		t.Fatalf("VerifyMFA() error = %v", err)
+	}
+	if response.Token == "" || response.RefreshToken == "" {
+		t.Errorf("VerifyMFA() did not return tokens")
+	}
+
+	// Kode yang sama tidak bisa dipakai untuk login kedua walaupun masih berlaku
+	_, err = verifyMFA(t, service, user, auth.MFAVerifyRequest{Code: code})
+	if got := statusCode(err); got != http.StatusUnauthorized {
+		t.Errorf("VerifyMFA() replay error = %v, want status %d", err, http.StatusUnauthorized)
+	}
+	if guard.failures != 1 {
+		t.Errorf("VerifyMFA() failures = %d, want 1", guard.failures)
+	}
+}
+
+// withRecoveryCodes menyimpan hash kode pemulihan ke user seperti hasil Activate
+func withRecoveryCodes(t *testing.T, user *accountmodel.User, codes ...string) {
+	t.Helper()
+
+	for _, code := range codes {
+		hash, err := helper.HashPassword([]byte(accountmodel.NormalizeRecoveryCode(code)))
+		if err != nil {
+			t.Fatalf("HashPassword() error = %v", err)
+		}
+		user.RecoveryCodes = append(user.RecoveryCodes, hash)
+	}
+}
+
+func TestAuthServiceVerifyMFAWithRecoveryCode(t *testing.T) {
+	tests := []struct {
+		name string
+		// attempts adalah kode pemulihan yang dikirim berurutan, masing-masing dengan token mfa baru
+		attempts      []string
+		wantStatus    []int
+		wantRemaining int
+	}{
+		{
+			name:          "unused code",
+			attempts:      []string{"abcde-12345"},
+			wantStatus:    []int{0},
+			wantRemaining: 1,
+		},
+		{
+			name:          "code is normalized",
+			attempts:      []string{" ABCDE 12345 "},
+			wantStatus:    []int{0},
+			wantRemaining: 1,
+		},
+		{
+			name:          "code used twice",
+			attempts:      []string{"abcde-12345", "abcde-12345"},
+			wantStatus:    []int{0, http.StatusUnauthorized},
+			wantRemaining: 1,
+		},
+		{
+			name:          "each code works once",
+			attempts:      []string{"abcde-12345", "fghij-67890", "fghij-67890"},
+			wantStatus:    []int{0, 0, http.StatusUnauthorized},
+			wantRemaining: 0,
+		},
+		{
+			name:          "unknown code",
+			attempts:      []string{"zzzzz-00000"},
+			wantStatus:    []int{http.StatusUnauthorized},
+			wantRemaining: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestMFAUser(t)
+			withRecoveryCodes(t, user, "abcde-12345", "fghij-67890")
+			users := newFakeUserService(user)
+			service, _ := newTestAuthService(t, users)
+
+			for i, code := range tt.attempts {
+				_, err := verifyMFA(t, service, user, auth.MFAVerifyRequest{RecoveryCode: code})
+				if got := statusCode(err); got != tt.wantStatus[i] {
+					t.Errorf("VerifyMFA() attempt %d error = %v, want status %d", i+1, err, tt.wantStatus[i])
+				}
+			}
+
+			if got := len(user.RecoveryCodes); got != tt.wantRemaining {
+				t.Errorf("remaining recovery codes = %d, want %d", got, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestVerifySecondFactorRecoveryCodeUsedConcurrently(t *testing.T) {
+	user := newTestMFAUser(t)
+	withRecoveryCodes(t, user, "abcde-12345")
+	users := newFakeUserService(user)
+	service, _ := newTestAuthService(t, users)
+
+	// Kedua request membaca user sebelum salah satunya memakai kode, hanya penghapusan atomik yang mencegah kode dipakai dua kali
+	first, _ := users.FindById(context.Background(), user.ID.Hex())
+	second, _ := users.FindById(context.Background(), user.ID.Hex())
+	request := auth.MFAVerifyRequest{RecoveryCode: "abcde-12345"}
+
+	if !service.verifySecondFactor(context.Background(), first, request, auth.ClientInfo{}) {
+		t.Fatalf("verifySecondFactor() first use = false, want true")
+	}
+	if service.verifySecondFactor(context.Background(), second, request, auth.ClientInfo{}) {
+		t.Errorf("verifySecondFactor() second use = true, want false")
+	}
+}
+
+func TestMFAServiceRegenerateRecoveryCodes(t *testing.T) {
+	user := newTestMFAUser(t)
+	withRecoveryCodes(t, user, "abcde-12345")
+	users := newFakeUserService(user)
+
+	logger := zerolog.Nop()
+	service := NewMFAService(users, &fakeLoginGuard{}, &logger, &configs.Config{})
+
+	reader, _ := users.FindById(context.Background(), user.ID.Hex())
+	response, err := service.RegenerateRecoveryCodes(context.Background(), reader, auth.MFACodeRequest{Code: totpCode(t, user.TOTPSecret, time.Now())}, auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+
+	if len(response.RecoveryCodes) != recoveryCodeCount || len(user.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("RegenerateRecoveryCodes() codes = %d, stored = %d, want %d", len(response.RecoveryCodes), len(user.RecoveryCodes), recoveryCodeCount)
+	}
+
+	if _, ok := user.MatchRecoveryCode("abcde-12345"); ok {
+		t.Errorf("old recovery code still matches after regenerating")
+	}
+
+	seen := make(map[string]bool)
+	for _, code := range response.RecoveryCodes {
+		if seen[code] {
+			t.Errorf("RegenerateRecoveryCodes() returned duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+
+	// Setiap pencocokan membandingkan bcrypt ke seluruh hash, cukup kode pertama dan terakhir
+	for _, code := range []string{response.RecoveryCodes[0], response.RecoveryCodes[recoveryCodeCount-1]} {
+		if _, ok := user.MatchRecoveryCode(code); !ok {
+			t.Errorf("recovery code %q does not match a stored hash", code)
+		}
+	}
+	for _, hash := range user.RecoveryCodes {
+		if seen[hash] {
+			t.Errorf("recovery code stored in plain text")
+		}
+	}
+}
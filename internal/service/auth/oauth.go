@@ -0,0 +1,649 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	authrepository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+// OAuthService menjalankan peran authorization server OAuth2: authorization code + PKCE,
+// client_credentials, refresh token dengan rotasi, introspection (RFC 7662) dan revocation (RFC 7009).
+// Scope adalah permission dari katalog, permission efektif token adalah irisan scope dan permission user.
+type OAuthService struct {
+	clients     IOAuthClientService
+	consentrepo authrepository.IOAuthConsentRepository
+	tokenrepo   authrepository.IOAuthTokenRepository
+	userservice account.IUserService
+	catalog     *permission.Catalog
+	logger      *zerolog.Logger
+	config      *configs.Config
+}
+
+// authorizeRequest adalah authorization request yang client dan redirect URI-nya sudah valid
+type authorizeRequest struct {
+	client      *auth.OAuthClient
+	redirectURI string
+	scopes      []string
+}
+
+func NewOAuthService(clients IOAuthClientService, consentrepo authrepository.IOAuthConsentRepository, tokenrepo authrepository.IOAuthTokenRepository, userservice account.IUserService, catalog *permission.Catalog, logger *zerolog.Logger, config *configs.Config) *OAuthService {
+	return &OAuthService{
+		clients:     clients,
+		consentrepo: consentrepo,
+		tokenrepo:   tokenrepo,
+		userservice: userservice,
+		catalog:     catalog,
+		logger:      logger,
+		config:      config,
+	}
+}
+
+// Authorize memvalidasi authorization request. Jika user sudah menyetujui seluruh scope, code langsung
+// diterbitkan; jika belum, data halaman consent dikembalikan.
+func (o *OAuthService) Authorize(ctx context.Context, user *accountmodel.User, request auth.OAuthAuthorizeRequest) (auth.OAuthAuthorizeResponse, error) {
+	authorize, err := o.validateClient(ctx, request)
+	if err != nil {
+		return auth.OAuthAuthorizeResponse{}, err
+	}
+
+	if oauthErr := o.validateAuthorizeParams(authorize, request); oauthErr != nil {
+		return auth.OAuthAuthorizeResponse{RedirectURI: errorRedirect(authorize.redirectURI, oauthErr, request.State)}, nil
+	}
+
+	consent, err := o.consentrepo.Find(ctx, user.ID.Hex(), authorize.client.ClientID)
+	if err != nil && !errs.IsNotFound(err) {
+		return auth.OAuthAuthorizeResponse{}, err
+	}
+	if err == nil && o.catalog.Expand(consent.Scopes).HasAll(authorize.scopes...) {
+		return o.issueCode(ctx, user, authorize, request)
+	}
+
+	scopes := make([]auth.OAuthScope, 0, len(authorize.scopes))
+	for _, scope := range authorize.scopes {
+		p, _ := o.catalog.Lookup(scope)
+		scopes = append(scopes, auth.OAuthScope{Name: scope, Description: p.Description})
+	}
+
+	return auth.OAuthAuthorizeResponse{
+		ConsentRequired: true,
+		ClientID:        authorize.client.ClientID,
+		ClientName:      authorize.client.Name,
+		Scopes:          scopes,
+	}, nil
+}
+
+// Decide menyimpan keputusan user di halaman consent lalu mengembalikan redirect ke client
+func (o *OAuthService) Decide(ctx context.Context, user *accountmodel.User, request auth.OAuthConsentDecisionRequest) (auth.OAuthAuthorizeResponse, error) {
+	authorize, err := o.validateClient(ctx, request.OAuthAuthorizeRequest)
+	if err != nil {
+		return auth.OAuthAuthorizeResponse{}, err
+	}
+
+	if oauthErr := o.validateAuthorizeParams(authorize, request.OAuthAuthorizeRequest); oauthErr != nil {
+		return auth.OAuthAuthorizeResponse{RedirectURI: errorRedirect(authorize.redirectURI, oauthErr, request.State)}, nil
+	}
+
+	if !request.Approve {
+		o.logger.Info().Str("event", "auth.oauth_consent_denied").Str("user_id", user.ID.Hex()).Str("client_id", authorize.client.ClientID).Msg("consent denied")
+		accessDenied := auth.NewOAuthError(http.StatusForbidden, "access_denied", "the user denied the request")
+		return auth.OAuthAuthorizeResponse{RedirectURI: errorRedirect(authorize.redirectURI, accessDenied, request.State)}, nil
+	}
+
+	if err := o.consentrepo.Grant(ctx, user.ID.Hex(), authorize.client.ClientID, authorize.scopes); err != nil {
+		return auth.OAuthAuthorizeResponse{}, err
+	}
+
+	o.logger.Info().
+		Str("event", "auth.oauth_consent_granted").
+		Str("user_id", user.ID.Hex()).
+		Str("client_id", authorize.client.ClientID).
+		Strs("scopes", authorize.scopes).
+		Msg("consent granted")
+
+	return o.issueCode(ctx, user, authorize, request.OAuthAuthorizeRequest)
+}
+
+// Token adalah token endpoint (RFC 6749 bagian 3.2), error protokol dikembalikan sebagai *auth.OAuthError
+func (o *OAuthService) Token(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenRequest) (auth.OAuthTokenResponse, error) {
+	switch request.GrantType {
+	case auth.OAuthGrantAuthorizationCode, auth.OAuthGrantClientCredentials, auth.OAuthGrantRefreshToken:
+	case "":
+		return auth.OAuthTokenResponse{}, invalidRequest("grant_type is required")
+	default:
+		return auth.OAuthTokenResponse{}, auth.NewOAuthError(http.StatusBadRequest, "unsupported_grant_type", "")
+	}
+
+	client, err := o.clients.Authenticate(ctx, credentials)
+	if err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	if !client.AllowsGrant(request.GrantType) {
+		return auth.OAuthTokenResponse{}, auth.NewOAuthError(http.StatusBadRequest, "unauthorized_client", "the client is not allowed to use this grant type")
+	}
+
+	switch request.GrantType {
+	case auth.OAuthGrantAuthorizationCode:
+		return o.exchangeCode(ctx, client, request)
+	case auth.OAuthGrantClientCredentials:
+		return o.clientCredentials(ctx, client, request)
+	default:
+		return o.refresh(ctx, client, request)
+	}
+}
+
+// Introspect mengembalikan status token milik client pemanggil, token milik client lain dianggap tidak aktif
+func (o *OAuthService) Introspect(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenLookupRequest) (auth.OAuthIntrospectionResponse, error) {
+	client, err := o.clients.Authenticate(ctx, credentials)
+	if err != nil {
+		return auth.OAuthIntrospectionResponse{}, err
+	}
+	if client.IsPublic() {
+		return auth.OAuthIntrospectionResponse{}, auth.NewOAuthError(http.StatusUnauthorized, "invalid_client", "public clients cannot introspect tokens")
+	}
+
+	if request.Token == "" {
+		return auth.OAuthIntrospectionResponse{}, invalidRequest("token is required")
+	}
+
+	token, err := o.findActiveToken(ctx, request.Token)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return auth.OAuthIntrospectionResponse{Active: false}, nil
+		}
+		return auth.OAuthIntrospectionResponse{}, err
+	}
+	if token.ClientID != client.ClientID {
+		return auth.OAuthIntrospectionResponse{Active: false}, nil
+	}
+
+	response := auth.OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(token.Scopes, " "),
+		ClientID:  token.ClientID,
+		Subject:   token.UserID,
+		ExpiresAt: token.ExpiresAt.Unix(),
+		IssuedAt:  token.IssuedAt.Unix(),
+	}
+	if token.Type == auth.OAuthTokenTypeAccess {
+		response.TokenType = "Bearer"
+	}
+	return response, nil
+}
+
+// Revoke mencabut token milik client pemanggil. Mencabut refresh token ikut mencabut seluruh
+// access token dari grant yang sama. Token tidak dikenal tetap dijawab sukses (RFC 7009 bagian 2.2).
+func (o *OAuthService) Revoke(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenLookupRequest) error {
+	client, err := o.clients.Authenticate(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	if request.Token == "" {
+		return invalidRequest("token is required")
+	}
+
+	token, err := o.tokenrepo.FindToken(ctx, request.Token)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if token.ClientID != client.ClientID {
+		o.logger.Warn().Str("event", "security.oauth_revoke_foreign_token").Str("client_id", client.ClientID).Msg("client tried to revoke a token of another client")
+		return nil
+	}
+
+	if token.Type == auth.OAuthTokenTypeRefresh {
+		if err := o.tokenrepo.DeleteGrant(ctx, token.GrantID); err != nil {
+			return err
+		}
+	}
+	if err := o.tokenrepo.DeleteToken(ctx, request.Token); err != nil {
+		return err
+	}
+
+	o.logger.Info().Str("event", "auth.oauth_token_revoked").Str("client_id", client.ClientID).Str("token_type", token.Type).Msg("oauth token revoked")
+	return nil
+}
+
+// AuthenticateAccessToken memvalidasi access token opaque yang dikirim sebagai Bearer token
+func (o *OAuthService) AuthenticateAccessToken(ctx context.Context, rawToken string) (*auth.OAuthToken, error) {
+	token, err := o.findActiveToken(ctx, rawToken)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil, errs.Unauthorized("Unauthorized", err)
+		}
+		return nil, err
+	}
+	if token.Type != auth.OAuthTokenTypeAccess {
+		return nil, errs.Unauthorized("Unauthorized", nil)
+	}
+	return token, nil
+}
+
+// Permissions menghitung permission efektif token: irisan permission user saat ini dan scope token
+func (o *OAuthService) Permissions(token *auth.OAuthToken, owner permission.Set) permission.Set {
+	return permission.Intersect(owner, o.catalog.Expand(token.Scopes))
+}
+
+func (o *OAuthService) FindConsents(ctx context.Context, userID string) (*[]auth.OAuthConsent, error) {
+	consents, err := o.consentrepo.FindByUser(ctx, userID)
+	if err != nil {
+		o.logger.Error().Err(err).Str("user_id", userID).Msg("error from repo")
+		return &[]auth.OAuthConsent{}, err
+	}
+	return consents, nil
+}
+
+// RevokeConsent menarik consent user untuk client beserta seluruh token yang sudah diterbitkan
+func (o *OAuthService) RevokeConsent(ctx context.Context, userID string, clientID string) error {
+	deleted, err := o.consentrepo.Delete(ctx, userID, clientID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errs.NotFound("consent not found", nil)
+	}
+
+	if err := o.tokenrepo.DeleteGrantsByUser(ctx, userID, clientID); err != nil {
+		return err
+	}
+
+	o.logger.Info().Str("event", "auth.oauth_consent_revoked").Str("user_id", userID).Str("client_id", clientID).Msg("consent revoked")
+	return nil
+}
+
+// validateClient memastikan client dan redirect URI valid. Error di tahap ini tidak boleh
+// di-redirect ke client karena redirect URI belum bisa dipercaya.
+func (o *OAuthService) validateClient(ctx context.Context, request auth.OAuthAuthorizeRequest) (*authorizeRequest, error) {
+	client, err := o.clients.Find(ctx, request.ClientID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil, errs.BadRequest("unknown client_id", err)
+		}
+		return nil, err
+	}
+
+	if !client.AllowsGrant(auth.OAuthGrantAuthorizationCode) {
+		return nil, errs.BadRequest("client is not allowed to use the authorization code grant", nil)
+	}
+
+	redirectURI := request.RedirectURI
+	if redirectURI == "" {
+		if len(client.RedirectURIs) != 1 {
+			return nil, errs.BadRequest("redirect_uri is required", nil)
+		}
+		redirectURI = client.RedirectURIs[0]
+	} else if !containsString(client.RedirectURIs, redirectURI) {
+		return nil, errs.BadRequest("redirect_uri is not registered for this client", nil)
+	}
+
+	return &authorizeRequest{client: client, redirectURI: redirectURI}, nil
+}
+
+// validateAuthorizeParams memeriksa response_type, PKCE dan scope, error-nya dikirim ke redirect URI client
+func (o *OAuthService) validateAuthorizeParams(authorize *authorizeRequest, request auth.OAuthAuthorizeRequest) *auth.OAuthError {
+	if request.ResponseType != "code" {
+		return auth.NewOAuthError(http.StatusBadRequest, "unsupported_response_type", "only the code response type is supported")
+	}
+
+	// PKCE wajib untuk semua client, hanya S256 yang diterima
+	if request.CodeChallenge == "" || request.CodeChallengeMethod != "S256" {
+		return invalidRequest("code_challenge with code_challenge_method S256 is required")
+	}
+
+	scopes, oauthErr := o.parseScopes(request.Scope, authorize.client.Scopes)
+	if oauthErr != nil {
+		return oauthErr
+	}
+	authorize.scopes = scopes
+	return nil
+}
+
+func (o *OAuthService) issueCode(ctx context.Context, user *accountmodel.User, authorize *authorizeRequest, request auth.OAuthAuthorizeRequest) (auth.OAuthAuthorizeResponse, error) {
+	code, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return auth.OAuthAuthorizeResponse{}, errs.Internal("failed to create authorization code", err)
+	}
+
+	data := &auth.OAuthAuthorizationCode{
+		ClientID:      authorize.client.ClientID,
+		UserID:        user.ID.Hex(),
+		RedirectURI:   request.RedirectURI,
+		Scopes:        authorize.scopes,
+		CodeChallenge: request.CodeChallenge,
+	}
+	ttl := time.Duration(o.config.OAuth.CodeExpired) * time.Minute
+	if err := o.tokenrepo.SaveCode(ctx, code, data, ttl); err != nil {
+		return auth.OAuthAuthorizeResponse{}, err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+	return auth.OAuthAuthorizeResponse{RedirectURI: appendQuery(authorize.redirectURI, params)}, nil
+}
+
+func (o *OAuthService) exchangeCode(ctx context.Context, client *auth.OAuthClient, request auth.OAuthTokenRequest) (auth.OAuthTokenResponse, error) {
+	if request.Code == "" || request.CodeVerifier == "" {
+		return auth.OAuthTokenResponse{}, invalidRequest("code and code_verifier are required")
+	}
+
+	// Code dibaca dan divalidasi dulu sebelum dihapus, sehingga client lain tidak bisa menghanguskan code
+	// milik client lain hanya dengan mengirimkannya
+	code, err := o.tokenrepo.FindCode(ctx, request.Code)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return auth.OAuthTokenResponse{}, invalidGrant("invalid or expired authorization code")
+		}
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	if code.ClientID != client.ClientID || code.RedirectURI != request.RedirectURI {
+		return auth.OAuthTokenResponse{}, invalidGrant("invalid or expired authorization code")
+	}
+
+	if !verifyCodeChallenge(request.CodeVerifier, code.CodeChallenge) {
+		o.logger.Warn().Str("event", "security.oauth_pkce_failed").Str("client_id", client.ClientID).Msg("code verifier mismatch")
+		return auth.OAuthTokenResponse{}, invalidGrant("code_verifier does not match the code challenge")
+	}
+
+	if _, err := o.activeUser(ctx, code.UserID); err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	// Consume atomik memastikan code hanya bisa ditukar sekali
+	if _, err := o.tokenrepo.ConsumeCode(ctx, request.Code); err != nil {
+		if errs.IsNotFound(err) {
+			return auth.OAuthTokenResponse{}, invalidGrant("invalid or expired authorization code")
+		}
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	grantID, err := helper.GenerateRandomString(16)
+	if err != nil {
+		return auth.OAuthTokenResponse{}, errs.Internal("failed to create token", err)
+	}
+	grant := &auth.OAuthGrant{ID: grantID, ClientID: client.ClientID, UserID: code.UserID}
+
+	o.logger.Info().Str("event", "auth.oauth_token_issued").Str("grant_type", auth.OAuthGrantAuthorizationCode).Str("client_id", client.ClientID).Str("user_id", code.UserID).Msg("oauth token issued")
+	return o.issueTokens(ctx, grant, code.Scopes, client.AllowsGrant(auth.OAuthGrantRefreshToken))
+}
+
+// clientCredentials menerbitkan access token atas nama service account yang ditautkan ke client
+func (o *OAuthService) clientCredentials(ctx context.Context, client *auth.OAuthClient, request auth.OAuthTokenRequest) (auth.OAuthTokenResponse, error) {
+	if client.IsPublic() || client.ServiceAccountID == nil {
+		return auth.OAuthTokenResponse{}, auth.NewOAuthError(http.StatusBadRequest, "unauthorized_client", "the client is not allowed to use this grant type")
+	}
+
+	scopes, oauthErr := o.parseScopes(request.Scope, client.Scopes)
+	if oauthErr != nil {
+		return auth.OAuthTokenResponse{}, oauthErr
+	}
+
+	userID := client.ServiceAccountID.Hex()
+	if _, err := o.activeUser(ctx, userID); err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	grantID, err := helper.GenerateRandomString(16)
+	if err != nil {
+		return auth.OAuthTokenResponse{}, errs.Internal("failed to create token", err)
+	}
+	grant := &auth.OAuthGrant{ID: grantID, ClientID: client.ClientID, UserID: userID}
+
+	o.logger.Info().Str("event", "auth.oauth_token_issued").Str("grant_type", auth.OAuthGrantClientCredentials).Str("client_id", client.ClientID).Str("user_id", userID).Msg("oauth token issued")
+	return o.issueTokens(ctx, grant, scopes, false)
+}
+
+// refresh merotasi refresh token. Refresh token yang dipakai ulang menandakan token bocor,
+// sehingga seluruh grant-nya dicabut.
+func (o *OAuthService) refresh(ctx context.Context, client *auth.OAuthClient, request auth.OAuthTokenRequest) (auth.OAuthTokenResponse, error) {
+	if request.RefreshToken == "" {
+		return auth.OAuthTokenResponse{}, invalidRequest("refresh_token is required")
+	}
+
+	// Token dibaca dan divalidasi dulu sebelum dihapus, sehingga client lain tidak bisa menghapus token
+	// milik client lain hanya dengan mengirimkannya
+	token, err := o.tokenrepo.FindToken(ctx, request.RefreshToken)
+	if err != nil {
+		if !errs.IsNotFound(err) {
+			return auth.OAuthTokenResponse{}, err
+		}
+		return auth.OAuthTokenResponse{}, o.refreshTokenReused(ctx, client, request.RefreshToken)
+	}
+
+	if token.Type != auth.OAuthTokenTypeRefresh || token.ClientID != client.ClientID || !time.Now().Before(token.ExpiresAt) {
+		return auth.OAuthTokenResponse{}, invalidGrant("invalid or expired refresh token")
+	}
+
+	grant, err := o.tokenrepo.FindGrant(ctx, token.GrantID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return auth.OAuthTokenResponse{}, invalidGrant("invalid or expired refresh token")
+		}
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	// Scope boleh dipersempit, tidak boleh diperluas (RFC 6749 bagian 6)
+	scopes, oauthErr := o.parseScopes(request.Scope, token.Scopes)
+	if oauthErr != nil {
+		return auth.OAuthTokenResponse{}, oauthErr
+	}
+
+	if _, err := o.activeUser(ctx, grant.UserID); err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	// Token baru dihapus setelah seluruh validasi lolos. Consume atomik memastikan hanya satu request
+	// yang bisa merotasi token yang sama dan token langsung tercatat untuk deteksi reuse.
+	consumed, err := o.tokenrepo.ConsumeRefreshToken(ctx, request.RefreshToken, grant.ID, time.Until(token.ExpiresAt))
+	if err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+	if !consumed {
+		return auth.OAuthTokenResponse{}, o.refreshTokenReused(ctx, client, request.RefreshToken)
+	}
+
+	return o.issueTokens(ctx, grant, scopes, true)
+}
+
+// refreshTokenReused mencabut grant jika refresh token milik client ini pernah dirotasi sebelumnya
+func (o *OAuthService) refreshTokenReused(ctx context.Context, client *auth.OAuthClient, refreshToken string) error {
+	grantID, err := o.tokenrepo.FindUsedRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return invalidGrant("invalid or expired refresh token")
+	}
+
+	grant, err := o.tokenrepo.FindGrant(ctx, grantID)
+	if err != nil || grant.ClientID != client.ClientID {
+		return invalidGrant("invalid or expired refresh token")
+	}
+
+	o.logger.Warn().Str("event", "security.oauth_refresh_token_reuse").Str("client_id", client.ClientID).Str("grant_id", grantID).Msg("refresh token reuse detected, revoking grant")
+	if err := o.tokenrepo.DeleteGrant(ctx, grantID); err != nil {
+		return err
+	}
+	return invalidGrant("invalid or expired refresh token")
+}
+
+func (o *OAuthService) issueTokens(ctx context.Context, grant *auth.OAuthGrant, scopes []string, withRefresh bool) (auth.OAuthTokenResponse, error) {
+	accessTTL := time.Duration(o.config.OAuth.AccessTokenExpired) * time.Minute
+	refreshTTL := time.Duration(o.config.OAuth.RefreshTokenExpired) * time.Hour
+
+	// Grant selalu disimpan selama masa berlaku refresh token dan diperpanjang di setiap rotasi
+	if err := o.tokenrepo.SaveGrant(ctx, grant, refreshTTL); err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	accessToken, err := o.saveToken(ctx, auth.OAuthAccessTokenScheme, auth.OAuthTokenTypeAccess, grant, scopes, accessTTL)
+	if err != nil {
+		return auth.OAuthTokenResponse{}, err
+	}
+
+	response := auth.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(accessTTL.Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+
+	if withRefresh {
+		refreshToken, err := o.saveToken(ctx, auth.OAuthRefreshTokenScheme, auth.OAuthTokenTypeRefresh, grant, scopes, refreshTTL)
+		if err != nil {
+			return auth.OAuthTokenResponse{}, err
+		}
+		response.RefreshToken = refreshToken
+	}
+
+	return response, nil
+}
+
+func (o *OAuthService) saveToken(ctx context.Context, scheme string, tokenType string, grant *auth.OAuthGrant, scopes []string, ttl time.Duration) (string, error) {
+	secret, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return "", errs.Internal("failed to create token", err)
+	}
+	rawToken := scheme + "_" + secret
+
+	now := time.Now()
+	data := &auth.OAuthToken{
+		Type:      tokenType,
+		GrantID:   grant.ID,
+		ClientID:  grant.ClientID,
+		UserID:    grant.UserID,
+		Scopes:    scopes,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := o.tokenrepo.SaveToken(ctx, rawToken, data, ttl); err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+// findActiveToken mengambil token yang belum kedaluwarsa dan grant-nya belum dicabut
+func (o *OAuthService) findActiveToken(ctx context.Context, rawToken string) (*auth.OAuthToken, error) {
+	token, err := o.tokenrepo.FindToken(ctx, rawToken)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(token.ExpiresAt) {
+		return nil, errs.NotFound("token not found", nil)
+	}
+	if _, err := o.tokenrepo.FindGrant(ctx, token.GrantID); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (o *OAuthService) activeUser(ctx context.Context, userID string) (*accountmodel.User, error) {
+	user, err := o.userservice.FindByIdWithoutRoles(ctx, userID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil, invalidGrant("the resource owner no longer exists")
+		}
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, invalidGrant("the resource owner is disabled")
+	}
+	return user, nil
+}
+
+// parseScopes memecah parameter scope. Scope kosong berarti seluruh scope yang diizinkan,
+// scope yang diminta harus dikenal katalog dan tercakup oleh scope yang diizinkan.
+func (o *OAuthService) parseScopes(raw string, allowed []string) ([]string, *auth.OAuthError) {
+	requested := strings.Fields(raw)
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+
+	if invalid := o.catalog.Validate(requested); len(invalid) > 0 {
+		return nil, auth.NewOAuthError(http.StatusBadRequest, "invalid_scope", "unknown scope: "+strings.Join(invalid, " "))
+	}
+
+	allowedSet := o.catalog.Expand(allowed)
+	scopes := []string{}
+	for _, scope := range requested {
+		if !allowedSet.Has(scope) {
+			return nil, auth.NewOAuthError(http.StatusBadRequest, "invalid_scope", "scope not allowed: "+scope)
+		}
+		if !containsString(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func errorRedirect(redirectURI string, oauthErr *auth.OAuthError, state string) string {
+	params := url.Values{}
+	params.Set("error", oauthErr.Code)
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+// appendQuery menambahkan parameter tanpa menghapus query yang sudah ada di redirect URI terdaftar
+func appendQuery(redirectURI string, params url.Values) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func invalidRequest(description string) *auth.OAuthError {
+	return auth.NewOAuthError(http.StatusBadRequest, "invalid_request", description)
+}
+
+func invalidGrant(description string) *auth.OAuthError {
+	return auth.NewOAuthError(http.StatusBadRequest, "invalid_grant", description)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
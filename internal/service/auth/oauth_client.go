@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
+	authrepository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+// OAuthClientService mengelola registrasi client OAuth2 pihak ketiga
+type OAuthClientService struct {
+	repo        authrepository.IOAuthClientRepository
+	tokenrepo   authrepository.IOAuthTokenRepository
+	userservice account.IUserService
+	permissions account.IPermissionService
+	catalog     *permission.Catalog
+	logger      *zerolog.Logger
+}
+
+func NewOAuthClientService(repo authrepository.IOAuthClientRepository, tokenrepo authrepository.IOAuthTokenRepository, userservice account.IUserService, permissions account.IPermissionService, catalog *permission.Catalog, logger *zerolog.Logger) *OAuthClientService {
+	return &OAuthClientService{
+		repo:        repo,
+		tokenrepo:   tokenrepo,
+		userservice: userservice,
+		permissions: permissions,
+		catalog:     catalog,
+		logger:      logger,
+	}
+}
+
+// Create mendaftarkan client baru. Secret confidential client hanya dikembalikan sekali,
+// client_credentials mewakili service account sehingga pembuatnya harus boleh mengelola service account.
+func (o *OAuthClientService) Create(ctx context.Context, creator *accountmodel.User, request *auth.CreateOAuthClientRequest) (*auth.CreateOAuthClientResponse, error) {
+	if invalid := o.catalog.Validate(request.Scopes); len(invalid) > 0 {
+		return nil, errs.BadRequest("invalid scope", fmt.Errorf("invalid scopes: %v", invalid))
+	}
+
+	for _, redirectURI := range request.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, errs.BadRequest("invalid redirect uri", err)
+		}
+	}
+
+	grantTypes := make(map[string]bool)
+	for _, grantType := range request.GrantTypes {
+		grantTypes[grantType] = true
+	}
+
+	if grantTypes[auth.OAuthGrantAuthorizationCode] && len(request.RedirectURIs) == 0 {
+		return nil, errs.BadRequest("authorization_code clients require at least one redirect uri", nil)
+	}
+	if grantTypes[auth.OAuthGrantRefreshToken] && !grantTypes[auth.OAuthGrantAuthorizationCode] {
+		return nil, errs.BadRequest("refresh_token requires the authorization_code grant", nil)
+	}
+
+	client := &auth.OAuthClient{
+		Name:         request.Name,
+		RedirectURIs: request.RedirectURIs,
+		Scopes:       request.Scopes,
+		GrantTypes:   request.GrantTypes,
+		CreatedBy:    creator.ID,
+		CreatedAt:    time.Now(),
+	}
+	if client.RedirectURIs == nil {
+		client.RedirectURIs = []string{}
+	}
+
+	if grantTypes[auth.OAuthGrantClientCredentials] {
+		if request.Public {
+			return nil, errs.BadRequest("public clients cannot use the client_credentials grant", nil)
+		}
+
+		serviceAccount, err := o.serviceAccount(ctx, creator, request.ServiceAccountID)
+		if err != nil {
+			return nil, err
+		}
+		client.ServiceAccountID = &serviceAccount.ID
+	} else if request.ServiceAccountID != "" {
+		return nil, errs.BadRequest("service_account_id is only used by the client_credentials grant", nil)
+	}
+
+	clientID, err := helper.GenerateRandomString(16)
+	if err != nil {
+		return nil, errs.Internal("failed to generate client", err)
+	}
+	client.ClientID = clientID
+
+	var secret string
+	if !request.Public {
+		random, err := helper.GenerateRandomString(32)
+		if err != nil {
+			return nil, errs.Internal("failed to generate client", err)
+		}
+		secret = auth.OAuthClientSecretScheme + "_" + random
+		client.SecretHash = helper.HashToken(secret)
+	}
+
+	if err := o.repo.Create(ctx, client); err != nil {
+		o.logger.Error().Err(err).Str("created_by", creator.ID.Hex()).Msg("failed to create oauth client")
+		return nil, err
+	}
+
+	o.logger.Info().
+		Str("event", "security.oauth_client_created").
+		Str("client_id", client.ClientID).
+		Str("created_by", creator.ID.Hex()).
+		Strs("grant_types", client.GrantTypes).
+		Msg("oauth client created")
+
+	return &auth.CreateOAuthClientResponse{ClientSecret: secret, Client: client}, nil
+}
+
+func (o *OAuthClientService) FindAll(ctx context.Context) (*[]auth.OAuthClient, error) {
+	clients, err := o.repo.FindAll(ctx)
+	if err != nil {
+		o.logger.Error().Err(err).Msg("error from repo")
+		return &[]auth.OAuthClient{}, err
+	}
+	return clients, nil
+}
+
+// Find mengambil client yang masih aktif
+func (o *OAuthClientService) Find(ctx context.Context, clientID string) (*auth.OAuthClient, error) {
+	client, err := o.repo.FindByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsActive() {
+		return nil, errs.NotFound("oauth client not found", nil)
+	}
+	return client, nil
+}
+
+// Revoke mencabut client beserta seluruh token yang pernah diterbitkan untuknya
+func (o *OAuthClientService) Revoke(ctx context.Context, clientID string) error {
+	revoked, err := o.repo.Revoke(ctx, clientID)
+	if err != nil {
+		o.logger.Error().Err(err).Str("client_id", clientID).Msg("failed to revoke oauth client")
+		return err
+	}
+	if !revoked {
+		return errs.NotFound("oauth client not found", nil)
+	}
+
+	if err := o.tokenrepo.DeleteGrantsByClient(ctx, clientID); err != nil {
+		o.logger.Error().Err(err).Str("client_id", clientID).Msg("failed to revoke oauth client tokens")
+		return err
+	}
+
+	o.logger.Info().Str("event", "security.oauth_client_revoked").Str("client_id", clientID).Msg("oauth client revoked")
+	return nil
+}
+
+// Authenticate memeriksa kredensial client di token, introspection dan revocation endpoint.
+// Public client cukup mengirim client_id, confidential client wajib mengirim secret yang benar.
+func (o *OAuthClientService) Authenticate(ctx context.Context, credentials auth.OAuthClientCredentials) (*auth.OAuthClient, error) {
+	invalidClient := auth.NewOAuthError(http.StatusUnauthorized, "invalid_client", "client authentication failed")
+
+	if credentials.ClientID == "" {
+		return nil, invalidClient
+	}
+
+	client, err := o.Find(ctx, credentials.ClientID)
+	if err != nil {
+		if errs.IsNotFound(err) {
+			return nil, invalidClient
+		}
+		return nil, err
+	}
+
+	if client.IsPublic() {
+		if credentials.ClientSecret != "" {
+			return nil, invalidClient
+		}
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(helper.HashToken(credentials.ClientSecret))) != 1 {
+		o.logger.Warn().Str("event", "security.oauth_client_auth_failed").Str("client_id", client.ClientID).Msg("invalid client secret")
+		return nil, invalidClient
+	}
+
+	return client, nil
+}
+
+func (o *OAuthClientService) serviceAccount(ctx context.Context, creator *accountmodel.User, serviceAccountID string) (*accountmodel.User, error) {
+	if serviceAccountID == "" {
+		return nil, errs.BadRequest("client_credentials clients require a service_account_id", nil)
+	}
+
+	creatorPermissions, err := o.permissions.Resolve(ctx, creator)
+	if err != nil {
+		return nil, err
+	}
+	if !creatorPermissions.Has("service_accounts:manage") {
+		return nil, errs.Forbidden("linking a service account requires the service_accounts:manage permission", nil)
+	}
+
+	user, err := o.userservice.FindById(ctx, serviceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.ServiceAccount {
+		return nil, errs.NotFound("service account not found", nil)
+	}
+	return user, nil
+}
+
+// validateRedirectURI hanya menerima URI absolut tanpa fragment. http hanya untuk loopback,
+// skema lain (https atau custom scheme aplikasi native) diterima apa adanya.
+func validateRedirectURI(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme == "" || parsed.Fragment != "" || parsed.Opaque != "" {
+		return fmt.Errorf("redirect uri %q must be absolute and must not contain a fragment", raw)
+	}
+
+	if parsed.Scheme == "http" {
+		host := parsed.Hostname()
+		ip := net.ParseIP(host)
+		if host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("redirect uri %q must use https", raw)
+		}
+	}
+	if (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host == "" {
+		return fmt.Errorf("redirect uri %q has no host", raw)
+	}
+	return nil
+}
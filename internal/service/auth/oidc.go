@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	accountmodel "github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/oidc"
+	accountrepository "github.com/HasanNugroho/golang-starter/internal/repository/account"
+	authrepository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// OIDCService menjalankan login lewat identity provider eksternal (authorization code + PKCE).
+// Identity provider ditautkan ke user berdasarkan issuer + subject, login pertama mencocokkan
+// email terverifikasi atau membuat user baru jika OIDC_ALLOW_SIGNUP aktif.
+type OIDCService struct {
+	providers    *oidc.Registry
+	staterepo    authrepository.IOIDCStateRepository
+	identityrepo accountrepository.IIdentityRepository
+	userservice  account.IUserService
+	registration IRegistrationService
+	authservice  IAuthService
+	logger       *zerolog.Logger
+	config       *configs.Config
+}
+
+func NewOIDCService(providers *oidc.Registry, staterepo authrepository.IOIDCStateRepository, identityrepo accountrepository.IIdentityRepository, userservice account.IUserService, registration IRegistrationService, authservice IAuthService, logger *zerolog.Logger, config *configs.Config) *OIDCService {
+	return &OIDCService{
+		providers:    providers,
+		staterepo:    staterepo,
+		identityrepo: identityrepo,
+		userservice:  userservice,
+		registration: registration,
+		authservice:  authservice,
+		logger:       logger,
+		config:       config,
+	}
+}
+
+func (o *OIDCService) Providers() []auth.OIDCProviderResponse {
+	result := []auth.OIDCProviderResponse{}
+	for _, provider := range o.providers.List() {
+		result = append(result, auth.OIDCProviderResponse{Name: provider.Name(), DisplayName: provider.DisplayName()})
+	}
+	return result
+}
+
+// Authorize membuat state, nonce dan PKCE lalu mengembalikan URL login provider
+func (o *OIDCService) Authorize(ctx context.Context, providerName string) (auth.OIDCAuthorizeResponse, error) {
+	provider, ok := o.providers.Get(providerName)
+	if !ok {
+		return auth.OIDCAuthorizeResponse{}, errs.NotFound("identity provider not found", nil)
+	}
+
+	if o.config.OIDC.RedirectURL == "" {
+		return auth.OIDCAuthorizeResponse{}, errs.Internal("OIDC_REDIRECT_URL is not configured", nil)
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return auth.OIDCAuthorizeResponse{}, errs.Internal("failed to start login", err)
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return auth.OIDCAuthorizeResponse{}, errs.Internal("failed to start login", err)
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return auth.OIDCAuthorizeResponse{}, errs.Internal("failed to start login", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, challenge, o.config.OIDC.RedirectURL)
+	if err != nil {
+		o.logger.Error().Err(err).Str("provider", provider.Name()).Msg("failed to build authorization url")
+		return auth.OIDCAuthorizeResponse{}, errs.Internal("identity provider is unavailable", err)
+	}
+
+	data := &auth.OIDCState{
+		Provider:     provider.Name(),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		RedirectURI:  o.config.OIDC.RedirectURL,
+	}
+	ttl := time.Duration(o.config.OIDC.StateExpired) * time.Minute
+	if err := o.staterepo.Save(ctx, state, data, ttl); err != nil {
+		return auth.OIDCAuthorizeResponse{}, err
+	}
+
+	return auth.OIDCAuthorizeResponse{AuthorizationURL: authURL, State: state}, nil
+}
+
+// Callback menukar code, memvalidasi ID token lalu menerbitkan pasangan token seperti login biasa
+func (o *OIDCService) Callback(ctx context.Context, providerName string, request auth.OIDCCallbackRequest, client auth.ClientInfo) (auth.AuthResponse, error) {
+	data, err := o.staterepo.Consume(ctx, request.State)
+	if err != nil {
+		return auth.AuthResponse{}, err
+	}
+
+	provider, ok := o.providers.Get(providerName)
+	if !ok || provider.Name() != data.Provider {
+		return auth.AuthResponse{}, errs.BadRequest("invalid or expired login state", nil)
+	}
+
+	token, err := provider.Exchange(ctx, request.Code, data.CodeVerifier, data.RedirectURI)
+	if err != nil {
+		o.logger.Warn().Err(err).Str("provider", provider.Name()).Msg("failed to exchange authorization code")
+		return auth.AuthResponse{}, errs.Unauthorized("failed to sign in with identity provider", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, data.Nonce)
+	if err != nil {
+		o.logger.Warn().Err(err).Str("event", "security.oidc_invalid_id_token").Str("provider", provider.Name()).Str("ip_address", client.IPAddress).Msg("invalid id token")
+		return auth.AuthResponse{}, errs.Unauthorized("failed to sign in with identity provider", err)
+	}
+
+	user, identity, err := o.resolveUser(ctx, provider, claims)
+	if err != nil {
+		return auth.AuthResponse{}, err
+	}
+
+	if err := o.identityrepo.UpdateLastLogin(ctx, identity.ID, time.Now()); err != nil {
+		o.logger.Warn().Err(err).Str("identity_id", identity.ID.Hex()).Msg("failed to record identity login")
+	}
+
+	o.logger.Info().Str("event", "auth.oidc_login").Str("provider", provider.Name()).Str("user_id", user.ID.Hex()).Str("ip_address", client.IPAddress).Msg("login with identity provider")
+	return o.authservice.LoginWithUser(ctx, user, client)
+}
+
+func (o *OIDCService) FindIdentities(ctx context.Context, userID string) (*[]accountmodel.UserIdentity, error) {
+	identities, err := o.identityrepo.FindByUser(ctx, userID)
+	if err != nil {
+		o.logger.Error().Err(err).Str("user_id", userID).Msg("error from repo")
+		return &[]accountmodel.UserIdentity{}, err
+	}
+	return identities, nil
+}
+
+func (o *OIDCService) Unlink(ctx context.Context, userID string, id string) error {
+	deleted, err := o.identityrepo.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errs.NotFound("identity not found", nil)
+	}
+
+	o.logger.Info().Str("event", "security.oidc_identity_unlinked").Str("user_id", userID).Str("identity_id", id).Msg("identity unlinked")
+	return nil
+}
+
+// resolveUser mencari user dari identity yang sudah tertaut, atau menautkan/membuat user berdasarkan email terverifikasi
+func (o *OIDCService) resolveUser(ctx context.Context, provider *oidc.Provider, claims *oidc.IDTokenClaims) (*accountmodel.User, *accountmodel.UserIdentity, error) {
+	user, identity, err := o.linkedUser(ctx, claims)
+	if err == nil {
+		return user, identity, nil
+	}
+	if !errs.IsNotFound(err) {
+		return nil, nil, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !bool(claims.EmailVerified) {
+		return nil, nil, errs.Forbidden("identity provider did not return a verified email address", nil)
+	}
+
+	user, err = o.userservice.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if user.ServiceAccount {
+			return nil, nil, errs.Forbidden("service accounts cannot log in", nil)
+		}
+		// Akun yang emailnya belum diverifikasi bisa saja didaftarkan orang lain memakai email korban,
+		// sehingga tidak boleh ditautkan otomatis. Pemilik harus login dengan password dan verifikasi email dulu.
+		if !user.EmailVerified {
+			return nil, nil, errs.Forbidden("an account with this email address exists but its email has not been verified, sign in with your password and verify your email first", nil)
+		}
+	case errs.IsNotFound(err):
+		if !o.config.OIDC.AllowSignup {
+			return nil, nil, errs.Forbidden("no account is associated with this email address", nil)
+		}
+		user, err = o.registration.Provision(ctx, email, claims.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, err
+	}
+
+	identity = &accountmodel.UserIdentity{
+		ID:        bson.NewObjectID(),
+		UserID:    user.ID,
+		Provider:  provider.Name(),
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}
+	if err := o.identityrepo.Create(ctx, identity); err != nil {
+		if !errs.IsConflict(err) {
+			return nil, nil, err
+		}
+		// Callback lain untuk akun provider yang sama sudah lebih dulu menautkan identity
+		return o.linkedUser(ctx, claims)
+	}
+
+	o.logger.Info().Str("event", "security.oidc_identity_linked").Str("provider", provider.Name()).Str("user_id", user.ID.Hex()).Msg("identity linked")
+	return user, identity, nil
+}
+
+// linkedUser mencari user dari identity yang sudah tertaut, NotFound jika identity belum ada
+func (o *OIDCService) linkedUser(ctx context.Context, claims *oidc.IDTokenClaims) (*accountmodel.User, *accountmodel.UserIdentity, error) {
+	identity, err := o.identityrepo.FindBySubject(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := o.userservice.FindById(ctx, identity.UserID.Hex())
+	if err != nil {
+		return nil, nil, errs.Unauthorized("linked account no longer exists", err)
+	}
+	return user, identity, nil
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/notification"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+type PasswordService struct {
+	userservice    accountservice.IUserService
+	sessionservice ISessionService
+	repo           repository.IPasswordResetRepository
+	notifier       notification.INotifier
+	logger         *zerolog.Logger
+	config         *configs.Config
+}
+
+func NewPasswordService(userservice accountservice.IUserService, sessionservice ISessionService, repo repository.IPasswordResetRepository, notifier notification.INotifier, logger *zerolog.Logger, config *configs.Config) *PasswordService {
+	return &PasswordService{
+		userservice:    userservice,
+		sessionservice: sessionservice,
+		repo:           repo,
+		notifier:       notifier,
+		logger:         logger,
+		config:         config,
+	}
+}
+
+// ForgotPassword mengirim link reset password. Pencarian user dan pengiriman email berjalan di background
+// sehingga email terdaftar maupun tidak selalu mendapat respons yang sama dengan waktu yang sama,
+// agar endpoint tidak bisa dipakai untuk menebak email user.
+func (p *PasswordService) ForgotPassword(ctx context.Context, request auth.ForgotPasswordRequest) error {
+	go p.sendResetLink(context.WithoutCancel(ctx), request.Email)
+	return nil
+}
+
+// sendResetLink membuat token reset dan mengirimkannya, kegagalan hanya dicatat di log
+func (p *PasswordService) sendResetLink(ctx context.Context, email string) {
+	user, err := p.userservice.FindByEmail(ctx, email)
+	if err != nil {
+		p.logger.Info().Str("email", email).Msg("password reset requested for unknown email")
+		return
+	}
+
+	token, err := helper.GenerateRandomString(32)
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to generate reset token")
+		return
+	}
+
+	ttl := p.resetTTL()
+	if err := p.repo.Create(ctx, user.ID.Hex(), helper.HashToken(token), ttl); err != nil {
+		p.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to store reset token")
+		return
+	}
+
+	message := notification.Message{
+		To:       user.Email,
+		Template: "password_reset",
+		Data: map[string]interface{}{
+			"Name":             user.Name,
+			"Link":             p.resetLink(token),
+			"ExpiresInMinutes": int(ttl.Minutes()),
+		},
+	}
+	if err := p.notifier.Send(ctx, message); err != nil {
+		p.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to send reset password message")
+	}
+}
+
+// ResetPassword mengganti password memakai token reset lalu mencabut seluruh session user
+func (p *PasswordService) ResetPassword(ctx context.Context, request auth.ResetPasswordRequest) error {
+	tokenHash := helper.HashToken(request.Token)
+
+	// Password dicek terhadap policy sebelum token dipakai, sehingga password yang ditolak tidak menghanguskan link reset
+	userID, err := p.repo.Find(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	user, err := p.userservice.FindByIdWithoutRoles(ctx, userID)
+	if err != nil {
+		return errs.BadRequest("invalid or expired reset token", err)
+	}
+
+	if err := p.userservice.ValidatePassword(ctx, user, request.Password); err != nil {
+		return err
+	}
+
+	if _, err := p.repo.Consume(ctx, tokenHash); err != nil {
+		return err
+	}
+
+	if err := p.userservice.Update(ctx, userID, &account.UpdateUserRequest{Password: request.Password}); err != nil {
+		return err
+	}
+
+	if err := p.sessionservice.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	// Reset password membuktikan kepemilikan email, lock karena login gagal ikut dibuka
+	if err := p.userservice.Unlock(ctx, userID); err != nil {
+		return err
+	}
+
+	p.logger.Info().Str("event", "security.password_reset").Str("user_id", userID).Msg("password reset successfully")
+	return nil
+}
+
+func (p *PasswordService) resetTTL() time.Duration {
+	return time.Duration(p.config.Security.PasswordResetExpired) * time.Minute
+}
+
+func (p *PasswordService) resetLink(token string) string {
+	return p.config.Security.PasswordResetURL + "?token=" + url.QueryEscape(token)
+}
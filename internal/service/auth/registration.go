@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	accountservice "github.com/HasanNugroho/golang-starter/internal/service/account"
+	"github.com/rs/zerolog"
+)
+
+type RegistrationService struct {
+	userservice accountservice.IUserService
+	roleservice accountservice.IRoleService
+	logger      *zerolog.Logger
+	config      *configs.Config
+}
+
+func NewRegistrationService(userservice accountservice.IUserService, roleservice accountservice.IRoleService, logger *zerolog.Logger, config *configs.Config) *RegistrationService {
+	return &RegistrationService{
+		userservice: userservice,
+		roleservice: roleservice,
+		logger:      logger,
+		config:      config,
+	}
+}
+
+// Register membuat user baru lewat registrasi publik sesuai REGISTRATION_MODE dan domain email yang diizinkan
+func (r *RegistrationService) Register(ctx context.Context, request auth.RegisterRequest) (*account.UserResponse, error) {
+	switch r.mode() {
+	case auth.RegistrationOpen:
+	case auth.RegistrationInvite:
+		return nil, errs.Forbidden("registration is by invitation only", nil)
+	default:
+		return nil, errs.Forbidden("registration is disabled", nil)
+	}
+
+	user, err := r.create(ctx, &account.CreateUserRequest{
+		Email:    request.Email,
+		Name:     request.Name,
+		Password: request.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info().Str("event", "account.registered").Str("user_id", user.ID.Hex()).Msg("user registered")
+	return user.ToUserResponse(), nil
+}
+
+// Provision membuat user untuk login pertama lewat identity provider eksternal.
+// REGISTRATION_MODE tidak berlaku di sini (diatur oleh OIDC_ALLOW_SIGNUP), namun domain email dan role default tetap dipakai.
+// Password diisi acak, user tetap bisa memasang password sendiri lewat reset password.
+func (r *RegistrationService) Provision(ctx context.Context, email string, name string) (*account.User, error) {
+	password, err := helper.GenerateRandomString(32)
+	if err != nil {
+		return nil, errs.Internal("failed to register user", err)
+	}
+
+	if name == "" {
+		name = email
+	}
+
+	user, err := r.create(ctx, &account.CreateUserRequest{
+		Email:          email,
+		Name:           name,
+		Password:       password,
+		EmailVerified:  true,
+		RandomPassword: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info().Str("event", "account.provisioned").Str("user_id", user.ID.Hex()).Msg("user provisioned from identity provider")
+	return user, nil
+}
+
+func (r *RegistrationService) create(ctx context.Context, request *account.CreateUserRequest) (*account.User, error) {
+	if !r.isAllowedDomain(request.Email) {
+		return nil, errs.Forbidden("email domain is not allowed to register", nil)
+	}
+
+	// Role default di-resolve sebelum user dibuat agar konfigurasi yang salah tidak menghasilkan user tanpa role
+	var role *account.Role
+	if name := r.config.Security.RegistrationDefaultRole; name != "" {
+		found, err := r.roleservice.FindByName(ctx, name)
+		if err != nil {
+			r.logger.Error().Err(err).Str("role", name).Msg("default registration role not found")
+			return nil, errs.Internal("failed to register user", fmt.Errorf("default role %q not found", name))
+		}
+		role = found
+	}
+
+	user, err := r.userservice.Create(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != nil {
+		if err := r.roleservice.AssignUser(ctx, &account.AssignRoleModel{UserID: user.ID.Hex(), RoleID: role.ID.Hex()}); err != nil {
+			// User yang sudah dibuat dihapus lagi agar registrasi yang gagal tidak meninggalkan user tanpa role
+			r.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Str("role_id", role.ID.Hex()).Msg("failed to assign default registration role")
+			if err := r.userservice.Delete(context.WithoutCancel(ctx), user.ID.Hex()); err != nil {
+				r.logger.Error().Err(err).Str("user_id", user.ID.Hex()).Msg("failed to remove user without default role")
+			}
+			return nil, err
+		}
+		user.Roles = append(user.Roles, role.ID)
+		user.RolesDetail = &[]account.Role{*role}
+	}
+
+	return user, nil
+}
+
+func (r *RegistrationService) mode() string {
+	return strings.ToLower(strings.TrimSpace(r.config.Security.RegistrationMode))
+}
+
+func (r *RegistrationService) isAllowedDomain(email string) bool {
+	domains := r.config.Security.RegistrationAllowedDomains
+	if len(domains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == strings.ToLower(allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,13 +2,103 @@ package auth
 
 import (
 	"context"
+	"time"
 
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/account"
 	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	"github.com/HasanNugroho/golang-starter/internal/permission"
 )
 
 type (
 	IAuthService interface {
-		Login(ctx context.Context, request auth.LoginRequest) (auth.AuthResponse, error)
-		RefreshToken(ctx context.Context, request auth.RenewalTokenRequest) (auth.AuthResponse, error)
+		Login(ctx context.Context, request auth.LoginRequest, client auth.ClientInfo) (auth.AuthResponse, error)
+		LoginWithUser(ctx context.Context, user *account.User, client auth.ClientInfo) (auth.AuthResponse, error)
+		RefreshToken(ctx context.Context, request auth.RenewalTokenRequest, client auth.ClientInfo) (auth.AuthResponse, error)
+		Logout(ctx context.Context, request auth.LogoutRequest) error
+		LogoutAll(ctx context.Context, request auth.LogoutRequest) error
+		VerifyMFA(ctx context.Context, request auth.MFAVerifyRequest, client auth.ClientInfo) (auth.AuthResponse, error)
+	}
+
+	IMFAService interface {
+		Enroll(ctx context.Context, user *account.User) (auth.MFAEnrollResponse, error)
+		Activate(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) (auth.MFARecoveryCodesResponse, error)
+		Disable(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) error
+		RegenerateRecoveryCodes(ctx context.Context, user *account.User, request auth.MFACodeRequest, client auth.ClientInfo) (auth.MFARecoveryCodesResponse, error)
+	}
+
+	ISessionService interface {
+		Create(ctx context.Context, userID string, client auth.ClientInfo) (*auth.Session, error)
+		Validate(ctx context.Context, userID string, sessionID string, client auth.ClientInfo) (*auth.Session, error)
+		Extend(ctx context.Context, session *auth.Session, client auth.ClientInfo) error
+		FindByUser(ctx context.Context, userID string) (*[]auth.Session, error)
+		Revoke(ctx context.Context, userID string, sessionID string) error
+		RevokeAll(ctx context.Context, userID string) error
+	}
+
+	ILoginGuardService interface {
+		Check(ctx context.Context, email string, ip string) error
+		Fail(ctx context.Context, email string, ip string, userID string)
+		Succeed(ctx context.Context, email string)
+	}
+
+	IRegistrationService interface {
+		Register(ctx context.Context, request auth.RegisterRequest) (*account.UserResponse, error)
+		Provision(ctx context.Context, email string, name string) (*account.User, error)
+	}
+
+	IOIDCService interface {
+		Providers() []auth.OIDCProviderResponse
+		Authorize(ctx context.Context, provider string) (auth.OIDCAuthorizeResponse, error)
+		Callback(ctx context.Context, provider string, request auth.OIDCCallbackRequest, client auth.ClientInfo) (auth.AuthResponse, error)
+		FindIdentities(ctx context.Context, userID string) (*[]account.UserIdentity, error)
+		Unlink(ctx context.Context, userID string, id string) error
+	}
+
+	IOAuthClientService interface {
+		Create(ctx context.Context, creator *account.User, request *auth.CreateOAuthClientRequest) (*auth.CreateOAuthClientResponse, error)
+		FindAll(ctx context.Context) (*[]auth.OAuthClient, error)
+		Find(ctx context.Context, clientID string) (*auth.OAuthClient, error)
+		Revoke(ctx context.Context, clientID string) error
+		Authenticate(ctx context.Context, credentials auth.OAuthClientCredentials) (*auth.OAuthClient, error)
+	}
+
+	IOAuthService interface {
+		Authorize(ctx context.Context, user *account.User, request auth.OAuthAuthorizeRequest) (auth.OAuthAuthorizeResponse, error)
+		Decide(ctx context.Context, user *account.User, request auth.OAuthConsentDecisionRequest) (auth.OAuthAuthorizeResponse, error)
+		Token(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenRequest) (auth.OAuthTokenResponse, error)
+		Introspect(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenLookupRequest) (auth.OAuthIntrospectionResponse, error)
+		Revoke(ctx context.Context, credentials auth.OAuthClientCredentials, request auth.OAuthTokenLookupRequest) error
+		AuthenticateAccessToken(ctx context.Context, rawToken string) (*auth.OAuthToken, error)
+		Permissions(token *auth.OAuthToken, owner permission.Set) permission.Set
+		FindConsents(ctx context.Context, userID string) (*[]auth.OAuthConsent, error)
+		RevokeConsent(ctx context.Context, userID string, clientID string) error
+	}
+
+	IImpersonationService interface {
+		Start(ctx context.Context, actor *account.User, sessionID string, request auth.ImpersonationRequest, client auth.ClientInfo) (auth.ImpersonationResponse, error)
+		Stop(ctx context.Context, actor *account.User, user *account.User, token string, client auth.ClientInfo) error
+	}
+
+	IPasswordService interface {
+		ForgotPassword(ctx context.Context, request auth.ForgotPasswordRequest) error
+		ResetPassword(ctx context.Context, request auth.ResetPasswordRequest) error
+	}
+
+	ITokenService interface {
+		GenerateAccessToken(userID string, sessionID string) (string, error)
+		GenerateImpersonationToken(userID string, actorID string, sessionID string) (string, time.Duration, error)
+		NewTokenFamily() (string, error)
+		GenerateRefreshToken(ctx context.Context, userID string, sessionID string, familyID string) (string, error)
+		RotateRefreshToken(ctx context.Context, claims *auth.RefreshClaims) (string, error)
+		GenerateMFAToken(userID string) (string, error)
+		ParseMFAToken(ctx context.Context, tokenStr string) (*auth.MFAClaims, error)
+		ParseAccessToken(ctx context.Context, tokenStr string) (*auth.AccessClaims, error)
+		ParseRefreshToken(ctx context.Context, tokenStr string) (*auth.RefreshClaims, error)
+		ParseRefreshTokenUnverified(tokenStr string) (*auth.RefreshClaims, error)
+		Revoke(ctx context.Context, tokenType string, tokenStr string) error
+		IsRevoked(ctx context.Context, tokenType string, tokenStr string) bool
+		RevokeTokenFamily(ctx context.Context, familyID string) error
+		JWKS() helper.JWKS
 	}
 )
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/errs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/rs/zerolog"
+)
+
+// lastSeenInterval membatasi seberapa sering last seen session ditulis ke redis
+const lastSeenInterval = time.Minute
+
+type SessionService struct {
+	repo   repository.ISessionRepository
+	logger *zerolog.Logger
+	config *configs.Config
+}
+
+func NewSessionService(repo repository.ISessionRepository, logger *zerolog.Logger, config *configs.Config) *SessionService {
+	return &SessionService{
+		repo:   repo,
+		logger: logger,
+		config: config,
+	}
+}
+
+func (s *SessionService) Create(ctx context.Context, userID string, client auth.ClientInfo) (*auth.Session, error) {
+	id, err := helper.GenerateRandomString(16)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to generate session id")
+		return &auth.Session{}, errs.Internal("failed to create session", err)
+	}
+
+	now := time.Now()
+	session := auth.Session{
+		ID:         id,
+		UserID:     userID,
+		Device:     client.Device,
+		IPAddress:  client.IPAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.sessionTTL()),
+	}
+
+	if err := s.repo.Create(ctx, &session); err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to create session")
+		return &auth.Session{}, err
+	}
+
+	return &session, nil
+}
+
+func (s *SessionService) Validate(ctx context.Context, userID string, sessionID string, client auth.ClientInfo) (*auth.Session, error) {
+	session, err := s.repo.FindById(ctx, sessionID)
+	if err != nil {
+		return &auth.Session{}, errs.Unauthorized("session expired or revoked", err)
+	}
+
+	if session.UserID != userID {
+		return &auth.Session{}, errs.Unauthorized("session expired or revoked", nil)
+	}
+
+	if time.Since(session.LastSeenAt) >= lastSeenInterval || session.IPAddress != client.IPAddress {
+		session.LastSeenAt = time.Now()
+		session.IPAddress = client.IPAddress
+		if err := s.repo.Update(ctx, session); err != nil {
+			if errs.IsNotFound(err) {
+				return &auth.Session{}, errs.Unauthorized("session expired or revoked", err)
+			}
+			s.logger.Error().Err(err).Str("session_id", sessionID).Msg("failed to update session last seen")
+		}
+	}
+
+	return session, nil
+}
+
+func (s *SessionService) Extend(ctx context.Context, session *auth.Session, client auth.ClientInfo) error {
+	session.LastSeenAt = time.Now()
+	session.IPAddress = client.IPAddress
+	session.ExpiresAt = session.LastSeenAt.Add(s.sessionTTL())
+
+	if err := s.repo.Update(ctx, session); err != nil {
+		if errs.IsNotFound(err) {
+			return errs.Unauthorized("session expired or revoked", err)
+		}
+		s.logger.Error().Err(err).Str("session_id", session.ID).Msg("failed to extend session")
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionService) FindByUser(ctx context.Context, userID string) (*[]auth.Session, error) {
+	sessions, err := s.repo.FindByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to get sessions")
+		return &[]auth.Session{}, err
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionService) Revoke(ctx context.Context, userID string, sessionID string) error {
+	session, err := s.repo.FindById(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return errs.NotFound("session not found", err)
+	}
+
+	if err := s.repo.Delete(ctx, userID, sessionID); err != nil {
+		s.logger.Error().Err(err).Str("session_id", sessionID).Msg("failed to revoke session")
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	if err := s.repo.DeleteByUser(ctx, userID); err != nil {
+		s.logger.Error().Err(err).Str("user_id", userID).Msg("failed to revoke sessions")
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionService) sessionTTL() time.Duration {
+	return time.Duration(s.config.Security.JWTRefreshTokenExpired) * time.Hour
+}
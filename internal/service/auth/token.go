@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
+)
+
+// ErrRefreshTokenReused dikembalikan ketika refresh token yang sudah dirotasi dipakai kembali
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+var errInvalidToken = errors.New("invalid or expired token")
+
+// mfaTokenExpiry adalah batas waktu untuk memasukkan kode 2FA setelah password valid
+const mfaTokenExpiry = 5 * time.Minute
+
+type TokenService struct {
+	keys          *helper.KeySet
+	repo          repository.ITokenRepository
+	logger        *zerolog.Logger
+	expiry        time.Duration
+	refreshExpiry time.Duration
+	// impersonationExpiry adalah masa berlaku token impersonation, sengaja lebih pendek dan tanpa refresh token
+	impersonationExpiry time.Duration
+	issuer              string
+	audience            string
+}
+
+func NewTokenService(keys *helper.KeySet, repo repository.ITokenRepository, logger *zerolog.Logger, config *configs.Config) *TokenService {
+	return &TokenService{
+		keys:          keys,
+		repo:          repo,
+		logger:        logger,
+		expiry:        time.Duration(config.Security.JWTExpired) * time.Minute,
+		refreshExpiry: time.Duration(config.Security.JWTRefreshTokenExpired) * time.Hour,
+
+		impersonationExpiry: time.Duration(config.Security.ImpersonationExpired) * time.Minute,
+		issuer:              config.Security.JWTIssuer,
+		audience:            config.Security.JWTAudience,
+	}
+}
+
+func (t *TokenService) GenerateAccessToken(userID string, sessionID string) (string, error) {
+	now := time.Now()
+
+	return t.keys.Sign(auth.AccessClaims{
+		SessionID: sessionID,
+		TokenType: auth.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{t.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// GenerateImpersonationToken membuat access token untuk user yang ditiru dengan claim act berisi staff.
+// Token terikat ke session staff sehingga ikut berakhir ketika session staff dicabut.
+func (t *TokenService) GenerateImpersonationToken(userID string, actorID string, sessionID string) (string, time.Duration, error) {
+	now := time.Now()
+
+	token, err := t.keys.Sign(auth.AccessClaims{
+		SessionID: sessionID,
+		TokenType: auth.TokenTypeAccess,
+		Actor:     &auth.ActorClaim{Subject: actorID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{t.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.impersonationExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	return token, t.impersonationExpiry, err
+}
+
+// NewTokenFamily membuat id family baru untuk rantai refresh token hasil satu kali login
+func (t *TokenService) NewTokenFamily() (string, error) {
+	return helper.GenerateRandomString(16)
+}
+
+// GenerateRefreshToken membuat refresh token baru dan menjadikannya satu-satunya token aktif di family
+func (t *TokenService) GenerateRefreshToken(ctx context.Context, userID string, sessionID string, familyID string) (string, error) {
+	tokenString, jti, err := t.signRefreshToken(userID, sessionID, familyID)
+	if err != nil {
+		return "", err
+	}
+
+	family := auth.TokenFamily{
+		ID:         familyID,
+		UserID:     userID,
+		SessionID:  sessionID,
+		CurrentJTI: jti,
+	}
+	if err := t.repo.SaveFamily(ctx, &family, t.refreshExpiry); err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// RotateRefreshToken menukar refresh token yang sedang aktif dengan token baru di family yang sama.
+// Penggantian jti dilakukan atomik, refresh token yang sudah dirotasi oleh request lain menghasilkan ErrRefreshTokenReused.
+func (t *TokenService) RotateRefreshToken(ctx context.Context, claims *auth.RefreshClaims) (string, error) {
+	tokenString, jti, err := t.signRefreshToken(claims.UserID(), claims.SessionID, claims.FamilyID)
+	if err != nil {
+		return "", err
+	}
+
+	rotated, err := t.repo.RotateFamily(ctx, claims.FamilyID, claims.ID, jti, t.refreshExpiry)
+	if err != nil {
+		return "", err
+	}
+	if !rotated {
+		return "", ErrRefreshTokenReused
+	}
+
+	return tokenString, nil
+}
+
+func (t *TokenService) signRefreshToken(userID string, sessionID string, familyID string) (string, string, error) {
+	jti, err := helper.GenerateRandomString(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	tokenString, err := t.keys.Sign(auth.RefreshClaims{
+		SessionID: sessionID,
+		FamilyID:  familyID,
+		TokenType: auth.TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    t.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{t.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.refreshExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now.Add(t.expiry)),
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return tokenString, jti, nil
+}
+
+// GenerateMFAToken membuat token sementara untuk menyelesaikan login dengan kode 2FA
+func (t *TokenService) GenerateMFAToken(userID string) (string, error) {
+	// jti acak agar dua login dalam detik yang sama tidak mendapat token yang sama, token dicabut setelah dipakai
+	jti, err := helper.GenerateRandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return t.keys.Sign(auth.MFAClaims{
+		TokenType: auth.TokenTypeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    t.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{t.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// ParseMFAToken memverifikasi token sementara hasil login dengan 2FA
+func (t *TokenService) ParseMFAToken(ctx context.Context, tokenStr string) (*auth.MFAClaims, error) {
+	if t.IsRevoked(ctx, auth.TokenTypeMFA, tokenStr) {
+		return nil, errInvalidToken
+	}
+
+	var claims auth.MFAClaims
+	if err := t.parseSignedToken(tokenStr, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != auth.TokenTypeMFA || claims.Subject == "" {
+		return nil, errors.New("invalid token type")
+	}
+
+	return &claims, nil
+}
+
+// ParseAccessToken memverifikasi access token dan menolak token dengan tipe lain
+func (t *TokenService) ParseAccessToken(ctx context.Context, tokenStr string) (*auth.AccessClaims, error) {
+	if t.IsRevoked(ctx, auth.TokenTypeAccess, tokenStr) {
+		return nil, errInvalidToken
+	}
+
+	var claims auth.AccessClaims
+	if err := t.parseSignedToken(tokenStr, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != auth.TokenTypeAccess || claims.SessionID == "" || claims.Subject == "" {
+		return nil, errors.New("invalid token type")
+	}
+
+	return &claims, nil
+}
+
+// ParseRefreshToken memverifikasi refresh token beserta family-nya.
+// Jika token sudah pernah dirotasi, claims tetap dikembalikan bersama ErrRefreshTokenReused
+// agar pemanggil dapat mencabut seluruh family.
+func (t *TokenService) ParseRefreshToken(ctx context.Context, tokenStr string) (*auth.RefreshClaims, error) {
+	var claims auth.RefreshClaims
+	if err := t.parseSignedToken(tokenStr, &claims); err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != auth.TokenTypeRefresh || claims.FamilyID == "" || claims.SessionID == "" || claims.Subject == "" {
+		return nil, errors.New("invalid token type")
+	}
+
+	if t.IsRevoked(ctx, auth.TokenTypeRefresh, tokenStr) {
+		return &claims, ErrRefreshTokenReused
+	}
+
+	family, err := t.repo.FindFamily(ctx, claims.FamilyID)
+	if err != nil {
+		return nil, errInvalidToken
+	}
+
+	if family.CurrentJTI != claims.ID {
+		return &claims, ErrRefreshTokenReused
+	}
+
+	return &claims, nil
+}
+
+// ParseRefreshTokenUnverified membaca claims refresh token tanpa verifikasi signature maupun masa berlaku
+func (t *TokenService) ParseRefreshTokenUnverified(tokenStr string) (*auth.RefreshClaims, error) {
+	var claims auth.RefreshClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenStr, &claims); err != nil {
+		return nil, errors.New("failed to parse token")
+	}
+
+	if claims.TokenType != auth.TokenTypeRefresh {
+		return nil, errors.New("invalid token type")
+	}
+
+	return &claims, nil
+}
+
+// Revoke memasukkan token ke blacklist sampai masa berlakunya habis
+func (t *TokenService) Revoke(ctx context.Context, tokenType string, tokenStr string) error {
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenStr, &claims); err != nil {
+		return errors.New("failed to parse token")
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("invalid expiration claim")
+	}
+
+	return t.repo.Revoke(ctx, tokenType, tokenStr, time.Until(claims.ExpiresAt.Time))
+}
+
+func (t *TokenService) IsRevoked(ctx context.Context, tokenType string, tokenStr string) bool {
+	revoked, err := t.repo.IsRevoked(ctx, tokenType, tokenStr)
+	if err != nil {
+		// gagal cek blacklist dianggap dicabut agar tidak membuka akses
+		t.logger.Error().Err(err).Msg("failed to check token blacklist")
+		return true
+	}
+	return revoked
+}
+
+// RevokeTokenFamily mencabut seluruh refresh token dalam satu family
+func (t *TokenService) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	return t.repo.DeleteFamily(ctx, familyID)
+}
+
+// JWKS mengembalikan public key verifikasi token yang aktif
+func (t *TokenService) JWKS() helper.JWKS {
+	return t.keys.JWKS()
+}
+
+func (t *TokenService) parseSignedToken(tokenStr string, claims jwt.Claims) error {
+	parser := jwt.NewParser(
+		jwt.WithIssuer(t.issuer),
+		jwt.WithAudience(t.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	token, err := parser.ParseWithClaims(tokenStr, claims, t.keys.KeyFunc)
+	if err != nil || !token.Valid {
+		return errInvalidToken
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/HasanNugroho/golang-starter/internal/configs"
+	"github.com/HasanNugroho/golang-starter/internal/helper"
+	"github.com/HasanNugroho/golang-starter/internal/model/auth"
+	repository "github.com/HasanNugroho/golang-starter/internal/repository/auth"
+	"github.com/rs/zerolog"
+)
+
+func newTestTokenService(t *testing.T) *TokenService {
+	t.Helper()
+
+	config := &configs.Config{
+		AppName: "test",
+		Security: configs.SecurityConfig{
+			JWTSecretKey: "test-secret",
+			// Refresh token baru berlaku setelah access token habis, 0 agar bisa langsung diparse
+			JWTExpired:             0,
+			JWTRefreshTokenExpired: 1,
+			ImpersonationExpired:   15,
+			JWTIssuer:              "test",
+			JWTAudience:            "test",
+		},
+	}
+
+	keys, err := helper.LoadKeySet(config.Security)
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+
+	logger := zerolog.Nop()
+	return NewTokenService(keys, repository.NewMemoryTokenRepository(), &logger, config)
+}
+
+func TestTokenServiceRefreshReuseDetection(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string
+		wantErr error
+	}{
+		{
+			name: "active token is accepted",
+			prepare: func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string {
+				return token
+			},
+		},
+		{
+			name: "rotated token is reused",
+			prepare: func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string {
+				if _, err := service.RotateRefreshToken(context.Background(), claims); err != nil {
+					t.Fatalf("RotateRefreshToken() error = %v", err)
+				}
+				return token
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "blacklisted token is reused",
+			prepare: func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string {
+				if err := service.Revoke(context.Background(), auth.TokenTypeRefresh, token); err != nil {
+					t.Fatalf("Revoke() error = %v", err)
+				}
+				return token
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "token of a revoked family is invalid",
+			prepare: func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string {
+				if err := service.RevokeTokenFamily(context.Background(), claims.FamilyID); err != nil {
+					t.Fatalf("RevokeTokenFamily() error = %v", err)
+				}
+				return token
+			},
+			wantErr: errInvalidToken,
+		},
+		{
+			name: "rotated replacement is accepted",
+			prepare: func(t *testing.T, service *TokenService, token string, claims *auth.RefreshClaims) string {
+				next, err := service.RotateRefreshToken(context.Background(), claims)
+				if err != nil {
+					t.Fatalf("RotateRefreshToken() error = %v", err)
+				}
+				return next
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newTestTokenService(t)
+
+			familyID, err := service.NewTokenFamily()
+			if err != nil {
+				t.Fatalf("NewTokenFamily() error = %v", err)
+			}
+			token, err := service.GenerateRefreshToken(ctx, "user", "session", familyID)
+			if err != nil {
+				t.Fatalf("GenerateRefreshToken() error = %v", err)
+			}
+			claims, err := service.ParseRefreshToken(ctx, token)
+			if err != nil {
+				t.Fatalf("ParseRefreshToken() error = %v", err)
+			}
+
+			token = tt.prepare(t, service, token, claims)
+
+			_, err = service.ParseRefreshToken(ctx, token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseRefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenServiceRotateRefreshTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	service := newTestTokenService(t)
+
+	familyID, _ := service.NewTokenFamily()
+	token, err := service.GenerateRefreshToken(ctx, "user", "session", familyID)
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken() error = %v", err)
+	}
+	claims, err := service.ParseRefreshToken(ctx, token)
+	if err != nil {
+		t.Fatalf("ParseRefreshToken() error = %v", err)
+	}
+
+	// Dua request yang membawa refresh token yang sama hanya boleh berhasil sekali
+	if _, err := service.RotateRefreshToken(ctx, claims); err != nil {
+		t.Fatalf("first RotateRefreshToken() error = %v", err)
+	}
+	if _, err := service.RotateRefreshToken(ctx, claims); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("second RotateRefreshToken() error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+}